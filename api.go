@@ -0,0 +1,340 @@
+// Copyright 2025 variHQ OÜ
+// SPDX-License-Identifier: BSD-3-Clause
+
+package main
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/wakeful/trick/internal/arn"
+	"github.com/wakeful/trick/internal/broadcast"
+	"github.com/wakeful/trick/internal/ui"
+)
+
+// States of the running chain reported by /api/status.
+const (
+	stateStarting = "starting"
+	stateRunning  = "running"
+	statePaused   = "paused"
+	stateBroken   = "broken"
+)
+
+// expiryWarningThreshold is how long before the written credentials expire the UI shows the expiry as a warning.
+const expiryWarningThreshold = 5 * time.Minute
+
+// defaultHistoryLimit is the page size of /api/history when the limit parameter is missing.
+const defaultHistoryLimit = 50
+
+// liveStatus is the state of the running chain served by /api/status.
+type liveStatus struct {
+	Chain      string               `json:"chain"`
+	State      string               `json:"state"`
+	Role       string               `json:"role,omitempty"`
+	Hop        int                  `json:"hop"`
+	Identity   string               `json:"identity,omitempty"`
+	Account    string               `json:"account,omitempty"`
+	Since      *time.Time           `json:"since,omitempty"`
+	Expiration *time.Time           `json:"expiration,omitempty"`
+	LastWrite  *time.Time           `json:"last_write,omitempty"`
+	NextJump   *time.Time           `json:"next_jump,omitempty"`
+	LastError  *statusError         `json:"last_error,omitempty"`
+	Paused     bool                 `json:"paused"`
+	Pinned     string               `json:"pinned,omitempty"`
+	Skipped    map[string]time.Time `json:"skipped,omitempty"`
+	// Unhealthy is the role whose AssumeRole call failed last, until a hop succeeds again
+	Unhealthy string `json:"unhealthy,omitempty"`
+	// Now is the server time of the response, so clients can count down without trusting their own clock
+	Now *time.Time `json:"now,omitempty"`
+	// ExpiryWarningSeconds is how long before Expiration the credentials are shown as expiring soon
+	ExpiryWarningSeconds int64 `json:"expiry_warning_seconds,omitempty"`
+}
+
+// statusError is the last failure of the running chain.
+type statusError struct {
+	Time    time.Time `json:"time"`
+	Class   string    `json:"class,omitempty"`
+	Message string    `json:"message"`
+}
+
+// chainInfo describes a ring for /api/chains.
+type chainInfo struct {
+	Name           string        `json:"name"`
+	RefreshSeconds int64         `json:"refresh_seconds"`
+	Roles          []chainMember `json:"roles"`
+}
+
+// chainMember is a role of a ring together with its position and flags.
+type chainMember struct {
+	Hop int `json:"hop"`
+	// Node is the ID of the diagram node of Hop
+	Node    string `json:"node"`
+	ARN     string `json:"arn"`
+	Name    string `json:"name"`
+	Account string `json:"account"`
+	Usable  bool   `json:"usable"`
+	Entry   bool   `json:"entry"`
+}
+
+// diagramResponse is the live diagram of a ring served by /api/diagram.
+type diagramResponse struct {
+	Chain      string `json:"chain"`
+	State      string `json:"state"`
+	Role       string `json:"role,omitempty"`
+	Hop        int    `json:"hop"`
+	Node       string `json:"node,omitempty"`
+	Definition string `json:"definition"`
+}
+
+// historyPage is a page of /api/history; After fetches the next one.
+type historyPage struct {
+	Events []broadcast.Message `json:"events"`
+	After  uint64              `json:"after"`
+	More   bool                `json:"more"`
+}
+
+// setStatus applies update to the status served by /api/status.
+func (a *App) setStatus(update func(status *liveStatus)) {
+	a.statusMu.Lock()
+	defer a.statusMu.Unlock()
+
+	update(&a.status)
+}
+
+// snapshot returns a copy of the status served by /api/status.
+func (a *App) snapshot() liveStatus {
+	a.statusMu.RLock()
+	defer a.statusMu.RUnlock()
+
+	return a.status
+}
+
+// chains describes the rings of the app as last loaded.
+func (a *App) chains() []chainInfo {
+	a.statusMu.RLock()
+	defer a.statusMu.RUnlock()
+
+	entries := a.entries
+	if len(entries) == 0 && len(a.members) > 0 {
+		entries = a.members[:1]
+	}
+
+	members := make([]chainMember, 0, len(a.members))
+
+	for hop, role := range a.members {
+		_, usable := a.usableRoles[role]
+		parsed, _ := arn.Parse(role)
+
+		members = append(members, chainMember{
+			Hop:     hop,
+			Node:    broadcast.NodeID(hop),
+			ARN:     role,
+			Name:    arn.Name(role),
+			Account: parsed.Account,
+			Usable:  usable || len(a.usableRoles) == 0,
+			Entry:   slices.Contains(entries, role),
+		})
+	}
+
+	return []chainInfo{{
+		Name:           "main",
+		RefreshSeconds: int64(a.refresh.Seconds()),
+		Roles:          members,
+	}}
+}
+
+// diagram draws the ring as last loaded with its disabled, unhealthy and pinned hops.
+func (a *App) diagram() diagramResponse {
+	chain := a.chains()[0]
+	status := a.snapshot()
+
+	usable := make(map[string]struct{})
+	roles := make([]string, 0, len(chain.Roles))
+
+	for _, member := range chain.Roles {
+		roles = append(roles, member.ARN)
+
+		if member.Usable {
+			usable[member.ARN] = struct{}{}
+		}
+	}
+
+	styles := make(map[string]string)
+
+	if status.Pinned != "" {
+		styles[status.Pinned] = ui.HopPinned
+	}
+
+	now := time.Now()
+
+	for role, until := range status.Skipped {
+		if until.After(now) {
+			styles[role] = ui.HopDisabled
+		}
+	}
+
+	if status.Unhealthy != "" {
+		styles[status.Unhealthy] = ui.HopUnhealthy
+	}
+
+	node := ""
+	if status.Role != "" {
+		node = broadcast.NodeID(status.Hop)
+	}
+
+	return diagramResponse{
+		Chain:      chain.Name,
+		State:      status.State,
+		Role:       status.Role,
+		Hop:        status.Hop,
+		Node:       node,
+		Definition: ui.LiveDiagram(roles, usable, chain.RefreshSeconds/60, styles), //nolint:mnd
+	}
+}
+
+// chainUpdated tells subscribers that the diagram of the chain changed.
+func (a *App) chainUpdated() {
+	a.broadcaster.Publish(broadcast.Message{ //nolint:exhaustruct
+		Event: broadcast.EventChainUpdated,
+		Chain: "main",
+		Role:  a.current,
+		Hop:   max(slices.Index(a.members, a.current), 0),
+	})
+}
+
+// setUnhealthy records role as the hop whose AssumeRole call failed last, or clears it with an empty role.
+func (a *App) setUnhealthy(role string) {
+	changed := false
+
+	a.setStatus(func(status *liveStatus) {
+		changed = status.Unhealthy != role
+		status.Unhealthy = role
+	})
+
+	if changed {
+		a.chainUpdated()
+	}
+}
+
+func apiChains(app *App) http.HandlerFunc {
+	return func(writer http.ResponseWriter, _ *http.Request) {
+		writeJSON(writer, map[string]any{"chains": app.chains()})
+	}
+}
+
+func apiDiagram(app *App) http.HandlerFunc {
+	return func(writer http.ResponseWriter, _ *http.Request) {
+		writeJSON(writer, app.diagram())
+	}
+}
+
+func apiStatus(app *App) http.HandlerFunc {
+	return func(writer http.ResponseWriter, _ *http.Request) {
+		status := app.snapshot()
+		now := time.Now().UTC()
+
+		status.Now = &now
+		status.ExpiryWarningSeconds = int64(expiryWarningThreshold.Seconds())
+
+		writeJSON(writer, status)
+	}
+}
+
+// apiHistory pages through the kept events oldest first. after is the ID of the last event already seen and limit the
+// page size; chain and event filter like they do for /events.
+func apiHistory(broadcaster *broadcast.Broadcaster) http.HandlerFunc {
+	return func(writer http.ResponseWriter, request *http.Request) {
+		query := request.URL.Query()
+
+		after, err := strconv.ParseUint(query.Get("after"), 10, 64)
+		if err != nil && query.Get("after") != "" {
+			http.Error(writer, "after must be an event ID", http.StatusBadRequest)
+
+			return
+		}
+
+		limit := defaultHistoryLimit
+
+		if value := query.Get("limit"); value != "" {
+			limit, err = strconv.Atoi(value)
+			if err != nil || limit < 1 {
+				http.Error(writer, "limit must be a positive number", http.StatusBadRequest)
+
+				return
+			}
+		}
+
+		filter := broadcast.Options{ //nolint:exhaustruct
+			Chains: splitList(strings.Join(query["chain"], ",")),
+			Events: splitList(strings.Join(query["event"], ",")),
+		}
+
+		page := historyPage{Events: make([]broadcast.Message, 0), After: after, More: false}
+
+		for _, msg := range broadcaster.History(after) {
+			if !filter.Match(msg) {
+				continue
+			}
+
+			if len(page.Events) == limit {
+				page.More = true
+
+				break
+			}
+
+			page.Events = append(page.Events, msg)
+			page.After = msg.ID
+		}
+
+		writeJSON(writer, page)
+	}
+}
+
+func writeJSON(writer http.ResponseWriter, value any) {
+	writer.Header().Set("Content-Type", "application/json")
+	writer.Header().Set("Cache-Control", "no-cache")
+
+	err := json.NewEncoder(writer).Encode(value)
+	if err != nil {
+		slog.Debug("failed to write API response", slog.String("error", err.Error()))
+	}
+}
+
+// credentialsResponse carries the credentials last written to the output profile; only the control socket serves it.
+type credentialsResponse struct {
+	Profile         string     `json:"profile"`
+	Region          string     `json:"region"`
+	AccessKeyID     string     `json:"access_key_id"`
+	SecretAccessKey string     `json:"secret_access_key"`
+	SessionToken    string     `json:"session_token"`
+	Expiration      *time.Time `json:"expiration,omitempty"`
+}
+
+func apiCredentials(app *App) http.HandlerFunc {
+	return func(writer http.ResponseWriter, _ *http.Request) {
+		app.statusMu.RLock()
+		credentials := app.credentials
+		app.statusMu.RUnlock()
+
+		if credentials == nil {
+			http.Error(writer, "no credentials written yet", http.StatusServiceUnavailable)
+
+			return
+		}
+
+		writeJSON(writer, credentialsResponse{
+			Profile:         defaultProfileName,
+			Region:          app.region,
+			AccessKeyID:     aws.ToString(credentials.AccessKeyId),
+			SecretAccessKey: aws.ToString(credentials.SecretAccessKey),
+			SessionToken:    aws.ToString(credentials.SessionToken),
+			Expiration:      credentials.Expiration,
+		})
+	}
+}
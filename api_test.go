@@ -0,0 +1,296 @@
+// Copyright 2025 variHQ OÜ
+// SPDX-License-Identifier: BSD-3-Clause
+
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/wakeful/trick/internal/broadcast"
+)
+
+func TestApp_chains(t *testing.T) {
+	t.Parallel()
+
+	members := []string{
+		"arn:aws:iam::123456789012:role/trick-role-a",
+		"arn:aws:iam::123456789012:role/trick-role-b",
+		"arn:aws:iam::210987654321:role/trick-role-c",
+	}
+
+	tests := []struct {
+		name       string
+		usable     map[string]struct{}
+		entries    []string
+		wantUsable []bool
+		wantEntry  []bool
+	}{
+		{
+			name:       "every role is usable and the first one is the entry point",
+			usable:     map[string]struct{}{},
+			entries:    nil,
+			wantUsable: []bool{true, true, true},
+			wantEntry:  []bool{true, false, false},
+		},
+		{
+			name:       "usable roles and entry points from the config",
+			usable:     map[string]struct{}{members[1]: {}},
+			entries:    []string{members[1], members[2]},
+			wantUsable: []bool{false, true, false},
+			wantEntry:  []bool{false, true, true},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			app := &App{ //nolint:exhaustruct
+				members:     members,
+				entries:     tt.entries,
+				usableRoles: tt.usable,
+				refresh:     12 * time.Minute,
+			}
+
+			chains := app.chains()
+			if len(chains) != 1 || chains[0].Name != "main" || chains[0].RefreshSeconds != 720 {
+				t.Fatalf("chains() = %+v, want the main chain refreshing every 720 seconds", chains)
+			}
+
+			usable := make([]bool, 0)
+			entry := make([]bool, 0)
+
+			for hop, member := range chains[0].Roles {
+				if member.Hop != hop || member.ARN != members[hop] {
+					t.Errorf("member %d = %+v, want %s", hop, member, members[hop])
+				}
+
+				usable = append(usable, member.Usable)
+				entry = append(entry, member.Entry)
+			}
+
+			if !reflect.DeepEqual(usable, tt.wantUsable) {
+				t.Errorf("usable = %v, want %v", usable, tt.wantUsable)
+			}
+
+			if !reflect.DeepEqual(entry, tt.wantEntry) {
+				t.Errorf("entry = %v, want %v", entry, tt.wantEntry)
+			}
+
+			if chains[0].Roles[2].Name != "trick-role-c" || chains[0].Roles[2].Account != "210987654321" {
+				t.Errorf("member 2 = %+v, want name trick-role-c in account 210987654321", chains[0].Roles[2])
+			}
+		})
+	}
+}
+
+func TestAPIStatus(t *testing.T) {
+	t.Parallel()
+
+	expiration := time.Date(2025, 6, 1, 12, 15, 0, 0, time.UTC)
+
+	app := &App{ //nolint:exhaustruct
+		status: liveStatus{Chain: "main", State: stateStarting}, //nolint:exhaustruct
+	}
+	app.setStatus(func(status *liveStatus) {
+		status.State = stateBroken
+		status.Role = "arn:aws:iam::123456789012:role/trick-role-b"
+		status.Hop = 1
+		status.Expiration = &expiration
+		status.LastError = &statusError{Time: expiration, Class: string(ClassThrottled), Message: "slow down"}
+	})
+
+	recorder := httptest.NewRecorder()
+	apiStatus(app)(recorder, httptest.NewRequestWithContext(t.Context(), http.MethodGet, "/api/status", nil))
+
+	var got liveStatus
+
+	err := json.NewDecoder(recorder.Body).Decode(&got)
+	if err != nil {
+		t.Fatalf("failed to decode status: %v", err)
+	}
+
+	if got.State != stateBroken || got.Hop != 1 || !got.Expiration.Equal(expiration) ||
+		got.LastError == nil || got.LastError.Class != "throttled" || got.Now == nil ||
+		got.ExpiryWarningSeconds != int64(expiryWarningThreshold.Seconds()) {
+		t.Errorf("status = %+v", got)
+	}
+
+	if recorder.Header().Get("Content-Type") != "application/json" {
+		t.Errorf("Content-Type = %q", recorder.Header().Get("Content-Type"))
+	}
+}
+
+func TestAPIDiagram(t *testing.T) {
+	t.Parallel()
+
+	const (
+		roleA = "arn:aws:iam::123456789012:role/trick-role-a"
+		roleB = "arn:aws:iam::123456789012:role/trick-role-b"
+		roleC = "arn:aws:iam::123456789012:role/trick-role-c"
+	)
+
+	tests := []struct {
+		name        string
+		update      func(status *liveStatus)
+		wantClasses []string
+		notClasses  []string
+	}{
+		{
+			name:       "plain ring",
+			update:     func(*liveStatus) {},
+			notClasses: []string{"class r0", "class r1", "class r2"},
+		},
+		{
+			name: "pinned and disabled hops",
+			update: func(status *liveStatus) {
+				status.Pinned = roleA
+				status.Skipped = map[string]time.Time{roleC: time.Now().Add(time.Hour)}
+			},
+			wantClasses: []string{"class r0 pinned", "class r2 disabled"},
+			notClasses:  []string{"class r1"},
+		},
+		{
+			name: "expired skip and unhealthy hop",
+			update: func(status *liveStatus) {
+				status.Skipped = map[string]time.Time{roleB: time.Now().Add(-time.Minute)}
+				status.Unhealthy = roleC
+			},
+			wantClasses: []string{"class r2 unhealthy"},
+			notClasses:  []string{"class r1"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			app := &App{ //nolint:exhaustruct
+				members: []string{roleA, roleB, roleC},
+				refresh: 12 * time.Minute,
+				status:  liveStatus{Chain: "main", State: stateRunning, Role: roleB, Hop: 1}, //nolint:exhaustruct
+			}
+			app.setStatus(tt.update)
+
+			recorder := httptest.NewRecorder()
+			apiDiagram(app)(recorder, httptest.NewRequestWithContext(t.Context(), http.MethodGet, "/api/diagram", nil))
+
+			var got diagramResponse
+
+			err := json.NewDecoder(recorder.Body).Decode(&got)
+			if err != nil {
+				t.Fatalf("failed to decode diagram: %v", err)
+			}
+
+			if got.Role != roleB || got.Hop != 1 || got.State != stateRunning ||
+				!strings.Contains(got.Definition, "r0 --> r1: wait 12min and jump") {
+				t.Errorf("diagram = %+v", got)
+			}
+
+			for _, want := range tt.wantClasses {
+				if !strings.Contains(got.Definition, want+"\n") {
+					t.Errorf("definition misses %q:\n%s", want, got.Definition)
+				}
+			}
+
+			for _, unwanted := range tt.notClasses {
+				if strings.Contains(got.Definition, unwanted) {
+					t.Errorf("definition contains %q:\n%s", unwanted, got.Definition)
+				}
+			}
+		})
+	}
+}
+
+func TestAPIHistory(t *testing.T) {
+	t.Parallel()
+
+	b := broadcast.NewBroadcaster()
+
+	for _, event := range []string{
+		broadcast.EventAssumeStarted,
+		broadcast.EventJump,
+		broadcast.EventCredentialsWritten,
+		broadcast.EventAssumeStarted,
+		broadcast.EventJump,
+	} {
+		b.Publish(broadcast.Message{Event: event, Chain: "main"}) //nolint:exhaustruct
+	}
+
+	tests := []struct {
+		name       string
+		target     string
+		wantStatus int
+		wantIDs    []uint64
+		wantAfter  uint64
+		wantMore   bool
+	}{
+		{name: "everything", target: "/api/history", wantStatus: http.StatusOK, wantIDs: []uint64{1, 2, 3, 4, 5}, wantAfter: 5},
+		{
+			name:       "first page",
+			target:     "/api/history?limit=2",
+			wantStatus: http.StatusOK,
+			wantIDs:    []uint64{1, 2},
+			wantAfter:  2,
+			wantMore:   true,
+		},
+		{
+			name:       "next page",
+			target:     "/api/history?limit=2&after=2",
+			wantStatus: http.StatusOK,
+			wantIDs:    []uint64{3, 4},
+			wantAfter:  4,
+			wantMore:   true,
+		},
+		{name: "last page", target: "/api/history?limit=2&after=4", wantStatus: http.StatusOK, wantIDs: []uint64{5}, wantAfter: 5},
+		{
+			name:       "filtered",
+			target:     "/api/history?event=jump",
+			wantStatus: http.StatusOK,
+			wantIDs:    []uint64{2, 5},
+			wantAfter:  5,
+		},
+		{name: "invalid limit", target: "/api/history?limit=0", wantStatus: http.StatusBadRequest},
+		{name: "invalid after", target: "/api/history?after=last", wantStatus: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			recorder := httptest.NewRecorder()
+			apiHistory(b)(recorder, httptest.NewRequestWithContext(t.Context(), http.MethodGet, tt.target, nil))
+
+			if recorder.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", recorder.Code, tt.wantStatus)
+			}
+
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+
+			var page historyPage
+
+			err := json.NewDecoder(recorder.Body).Decode(&page)
+			if err != nil {
+				t.Fatalf("failed to decode page: %v", err)
+			}
+
+			ids := make([]uint64, 0)
+			for _, event := range page.Events {
+				ids = append(ids, event.ID)
+			}
+
+			if !reflect.DeepEqual(ids, tt.wantIDs) || page.After != tt.wantAfter || page.More != tt.wantMore {
+				t.Errorf("page = ids %v after %d more %v, want %v after %d more %v",
+					ids, page.After, page.More, tt.wantIDs, tt.wantAfter, tt.wantMore)
+			}
+		})
+	}
+}
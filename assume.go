@@ -7,12 +7,15 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
+	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/config"
 	"github.com/aws/aws-sdk-go-v2/credentials"
 	"github.com/aws/aws-sdk-go-v2/service/sts"
 	"github.com/aws/aws-sdk-go-v2/service/sts/types"
+	"github.com/wakeful/trick/internal/arn"
+	"github.com/wakeful/trick/internal/audit"
 	"github.com/wakeful/trick/internal/broadcast"
 )
 
@@ -21,7 +24,7 @@ func (a *App) assumeRole(ctx context.Context, role string) (*types.Credentials,
 
 	assumeRole, err := a.client.AssumeRole(ctx, &sts.AssumeRoleInput{ //nolint:exhaustruct
 		RoleArn:         aws.String(role),
-		RoleSessionName: aws.String("trick"),
+		RoleSessionName: aws.String(sessionName),
 		DurationSeconds: aws.Int32(int32(a.sessionDuration.Seconds())),
 	})
 	if err != nil {
@@ -46,40 +49,130 @@ func (a *App) assumeRole(ctx context.Context, role string) (*types.Credentials,
 	return assumeRole.Credentials, nil
 }
 
+// assumeNextInterestingRole hops along the ring until it holds a role the chain may stop at. Disabled hops are
+// assumed like transit roles, so every hop is still made with the credentials of the role before it.
 func (a *App) assumeNextInterestingRole(ctx context.Context) (*types.Credentials, error) {
+	if !a.hasStop() {
+		return nil, withClass(ClassConfig, fmt.Errorf("%w: every usable role is disabled", ErrNoUsableRoleLeft))
+	}
+
 	var outputCred *types.Credentials
 
 	for {
+		hop := a.position
 		role := a.nextRole()
 
 		slog.Info("trying to assume role", slog.String("role", role))
 
+		usable := a.canStop(role)
+
+		errBudget := a.guardrails.reserveAssume()
+		if errBudget != nil {
+			a.rewindRole()
+
+			return nil, a.tripGuardrail(errBudget)
+		}
+
+		a.broadcaster.Publish(broadcast.Message{ //nolint:exhaustruct
+			Event:        broadcast.EventAssumeStarted,
+			Chain:        "main",
+			Role:         role,
+			Hop:          hop,
+			PreviousRole: a.current,
+		})
+
+		started := time.Now()
+
 		cred, err := a.assumeRole(ctx, role)
 		if err != nil {
-			return nil, fmt.Errorf("unable to assume role, %w", err)
+			class := assumeErrorClass(err)
+
+			a.audit.Record(audit.Entry{ //nolint:exhaustruct
+				Event: audit.EventAssumeFailed,
+				Chain: "main",
+				Role:  role,
+				Class: string(class),
+				Error: err.Error(),
+			})
+
+			a.broadcaster.Publish(broadcast.Message{ //nolint:exhaustruct
+				Event:        broadcast.EventAssumeFailed,
+				Chain:        "main",
+				Role:         role,
+				Hop:          hop,
+				PreviousRole: a.current,
+				Class:        string(class),
+				Error:        err.Error(),
+				DurationMS:   time.Since(started).Milliseconds(),
+			})
+
+			a.setUnhealthy(role)
+			a.rewindRole()
+
+			return nil, withClass(class, fmt.Errorf("unable to assume role, %w", err))
 		}
 
-		a.broadcaster.Publish(broadcast.Message{
-			Chain: "main",
-			Role:  role,
+		a.audit.Record(audit.Entry{ //nolint:exhaustruct
+			Event:  audit.EventAssume,
+			Chain:  "main",
+			Role:   role,
+			Usable: usable,
+		})
+
+		a.broadcaster.Publish(broadcast.Message{ //nolint:exhaustruct
+			Event:        broadcast.EventJump,
+			Chain:        "main",
+			Role:         role,
+			Hop:          hop,
+			PreviousRole: a.current,
+			Expiration:   cred.Expiration,
+			DurationMS:   time.Since(started).Milliseconds(),
 		})
 
 		outputCred = cred
+		a.current = role
 
-		if len(a.usableRoles) == 0 {
-			slog.Debug("all roles have meaningful permissions")
+		a.setUnhealthy("")
 
-			break
-		}
+		a.setStatus(func(status *liveStatus) {
+			now := time.Now().UTC()
+			identity, _ := arn.AssumedRole(role, sessionName)
+			parsed, _ := arn.Parse(role)
+
+			status.Role = role
+			status.Hop = hop
+			status.Identity = identity
+			status.Account = parsed.Account
+			status.Since = &now
+		})
 
-		if _, ok := a.usableRoles[role]; ok {
+		if usable {
 			slog.Debug("found role with meaningful permissions", slog.String("role", role))
 
 			break
 		}
 
 		slog.Debug("role is lacking meaningful permissions", slog.String("role", role))
+
+		a.broadcaster.Publish(broadcast.Message{ //nolint:exhaustruct
+			Event: broadcast.EventSkippedTransit,
+			Chain: "main",
+			Role:  role,
+			Hop:   hop,
+		})
 	}
 
 	return outputCred, nil
 }
+
+// hasStop reports whether the ring holds a role the chain may stop at, so a tick cannot hop around it forever.
+func (a *App) hasStop() bool {
+	found := false
+
+	a.roles.Do(func(value any) {
+		role, _ := value.(string)
+		found = found || a.canStop(role)
+	})
+
+	return found
+}
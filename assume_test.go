@@ -186,3 +186,122 @@ func TestApp_assumeNextInterestingRole(t *testing.T) {
 		})
 	}
 }
+
+func TestApp_assumeNextInterestingRoleEvents(t *testing.T) {
+	t.Parallel()
+
+	credentials := &types.Credentials{
+		AccessKeyId:     aws.String("access-key-id"),
+		SecretAccessKey: aws.String("secret-access-key"),
+		SessionToken:    aws.String("session-token"),
+	}
+
+	const (
+		roleA = "arn:aws:iam::0987654321:role/role-a"
+		roleB = "arn:aws:iam::0987654321:role/role-b"
+	)
+
+	tests := []struct {
+		name          string
+		client        MockSTSClient
+		usableRoles   map[string]struct{}
+		skipped       map[string]time.Time
+		wantErr       error
+		wantEvents    []string
+		wantPosition  int
+		wantUnhealthy string
+	}{
+		{
+			name: "a successful hop starts and jumps",
+			client: MockSTSClient{ //nolint:exhaustruct
+				mockAssumeRoleOutput: map[string]sts.AssumeRoleOutput{
+					"arn:aws:iam::0987654321:role/role-a": {Credentials: credentials},
+				},
+			},
+			wantEvents:   []string{broadcast.EventAssumeStarted, broadcast.EventJump},
+			wantPosition: 1,
+		},
+		{
+			name: "a failed hop is reported and retried from the same role",
+			client: MockSTSClient{ //nolint:exhaustruct
+				mockAssumeRoleOutput: make(map[string]sts.AssumeRoleOutput),
+				mockAssumeRoleError:  errors.New("error"),
+			},
+			wantEvents:    []string{broadcast.EventAssumeStarted, broadcast.EventAssumeFailed},
+			wantPosition:  0,
+			wantUnhealthy: "arn:aws:iam::0987654321:role/role-a",
+		},
+		{
+			name: "a skipped hop is still assumed",
+			client: MockSTSClient{ //nolint:exhaustruct
+				mockAssumeRoleOutput: make(map[string]sts.AssumeRoleOutput),
+				mockAssumeRoleError:  errors.New("error"),
+			},
+			usableRoles:   map[string]struct{}{roleB: {}},
+			skipped:       map[string]time.Time{roleA: time.Now().Add(time.Hour)},
+			wantEvents:    []string{broadcast.EventAssumeStarted, broadcast.EventAssumeFailed},
+			wantPosition:  0,
+			wantUnhealthy: roleA,
+		},
+		{
+			name: "no hop when every usable role is skipped",
+			client: MockSTSClient{ //nolint:exhaustruct
+				mockAssumeRoleOutput: map[string]sts.AssumeRoleOutput{roleA: {Credentials: credentials}},
+			},
+			usableRoles:  map[string]struct{}{roleA: {}},
+			skipped:      map[string]time.Time{roleA: time.Now().Add(time.Hour)},
+			wantErr:      ErrNoUsableRoleLeft,
+			wantPosition: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			roles, _ := setRolePool([]string{roleA, roleB})
+
+			usableRoles := tt.usableRoles
+			if usableRoles == nil {
+				usableRoles = make(map[string]struct{})
+			}
+
+			a := &App{ //nolint:exhaustruct
+				client:          tt.client,
+				region:          "eu-west-1",
+				roles:           roles,
+				usableRoles:     usableRoles,
+				skipped:         tt.skipped,
+				sessionDuration: 42 * time.Second,
+				broadcaster:     broadcast.NewBroadcaster(),
+			}
+
+			events, unsubscribe := a.broadcaster.Subscribe()
+			defer unsubscribe()
+
+			_, err := a.assumeNextInterestingRole(t.Context())
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Errorf("assumeNextInterestingRole() error = %v, want %v", err, tt.wantErr)
+			}
+
+			for _, want := range tt.wantEvents {
+				select {
+				case got := <-events:
+					if got.Event != want || got.Hop != 0 || got.Role != "arn:aws:iam::0987654321:role/role-a" {
+						t.Errorf("got event %+v, want %q for role-a at hop 0", got, want)
+					}
+				case <-time.After(100 * time.Millisecond):
+					t.Fatalf("did not receive %q", want)
+				}
+			}
+
+			if a.position != tt.wantPosition {
+				t.Errorf("position = %d, want %d", a.position, tt.wantPosition)
+			}
+
+			if unhealthy := a.snapshot().Unhealthy; unhealthy != tt.wantUnhealthy {
+				t.Errorf("unhealthy = %q, want %q", unhealthy, tt.wantUnhealthy)
+			}
+		})
+	}
+}
@@ -0,0 +1,125 @@
+// Copyright 2025 variHQ OÜ
+// SPDX-License-Identifier: BSD-3-Clause
+
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+
+	"github.com/wakeful/trick/internal/awsconfig"
+	"github.com/wakeful/trick/internal/parser"
+)
+
+// importAWSConfigCommand follows role_arn and source_profile links in the shared config file into a trick profile.
+//
+//nolint:cyclop,funlen
+func importAWSConfigCommand(_ context.Context, args []string, stdout io.Writer) error {
+	flags := flag.NewFlagSet("import aws-config", flag.ContinueOnError)
+	path := flags.String("file", "", "shared config file (default $AWS_CONFIG_FILE or ~/.aws/config)")
+	from := flags.String("from", "", "CLI profile the chain ends at (default: the only chain in the file)")
+	profileName := flags.String("profile", "aws-config", "name of the generated profile")
+	ttl := flags.Int64("ttl", 12, "minutes between jumps") //nolint:mnd
+	output := flags.String("output", "", "write the profile to this file instead of stdout")
+
+	ok, err := parseFlags(flags, args)
+	if !ok {
+		return err
+	}
+
+	if *path == "" {
+		*path, err = awsconfig.ConfigFile()
+		if err != nil {
+			return fmt.Errorf("failed to locate shared config: %w", err)
+		}
+	}
+
+	file, err := os.Open(*path) //nolint:gosec
+	if err != nil {
+		return fmt.Errorf("failed to open shared config: %w", err)
+	}
+
+	defer func() { _ = file.Close() }()
+
+	profiles, err := awsconfig.ReadProfiles(file)
+	if err != nil {
+		return fmt.Errorf("failed to read shared config: %w", err)
+	}
+
+	tail := *from
+	if tail == "" {
+		tails := awsconfig.Tails(profiles)
+		if len(tails) != 1 {
+			return fmt.Errorf("%w with -from: %s", awsconfig.ErrAmbiguousChain, strings.Join(tails, ", "))
+		}
+
+		tail = tails[0]
+	}
+
+	trace, err := awsconfig.Follow(profiles, tail)
+	if err != nil {
+		return fmt.Errorf("failed to follow %q: %w", tail, err)
+	}
+
+	switch {
+	case trace.Ring && len(trace.Branch) > 0:
+		slog.Warn(
+			"source_profile links lead into a ring, only the ring is imported",
+			slog.String("profile", tail),
+			slog.String("skipped", strings.Join(trace.Branch, ", ")),
+		)
+	case trace.Ring:
+		slog.Info("source_profile links form a ring", slog.String("profile", tail))
+	default:
+		slog.Warn(
+			"source_profile links do not form a ring, the last role must also be trusted by the first",
+			slog.String("profile", tail),
+			slog.String("entry", trace.Source),
+		)
+	}
+
+	trace.Chain.TTL = *ttl
+
+	config := &parser.Config{
+		SelectProfile: *profileName,
+		Profiles: []*parser.Profile{{
+			Name:       *profileName,
+			Region:     trace.Region,
+			Chain:      trace.Chain,
+			Guardrails: nil,
+		}},
+	}
+
+	return writeProfile(config, *output, stdout)
+}
+
+// exportAWSConfigCommand writes one shared config profile per hop of the chain.
+func exportAWSConfigCommand(_ context.Context, args []string, stdout io.Writer) error {
+	flags := flag.NewFlagSet("export aws-config", flag.ContinueOnError)
+	config := flags.String("config", "", "path to config file")
+	profileName := flags.String("profile", "", "profile to export (default select_profile)")
+	prefix := flags.String("prefix", "trick-", "prefix of the generated CLI profile names")
+	sourceProfile := flags.String("source-profile", "default", "CLI profile holding the credentials that enter the chain")
+
+	ok, err := parseFlags(flags, args)
+	if !ok {
+		return err
+	}
+
+	profile, err := loadProfile(*config, *profileName)
+	if err != nil {
+		return err
+	}
+
+	_, err = io.WriteString(stdout, awsconfig.Export(profile, *prefix, *sourceProfile, sessionName))
+	if err != nil {
+		return fmt.Errorf("failed to write profiles: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,94 @@
+// Copyright 2025 variHQ OÜ
+// SPDX-License-Identifier: BSD-3-Clause
+
+package main
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/wakeful/trick/internal/awsconfig"
+)
+
+func TestExportImportAWSConfig(t *testing.T) {
+	t.Parallel()
+
+	config := writeConfig(t, ringConfig)
+
+	var exported bytes.Buffer
+
+	err := exportCommand(t.Context(), []string{"aws-config", "-config", config, "-prefix", "lab-"}, &exported)
+	if err != nil {
+		t.Fatalf("export error = %v", err)
+	}
+
+	for _, want := range []string{
+		"[profile lab-trick-role-a]\nrole_arn = arn:aws:iam::123456789012:role/trick-role-a\nsource_profile = default\n",
+		"[profile lab-trick-role-a-wrap]\nrole_arn = arn:aws:iam::123456789012:role/trick-role-a\n" +
+			"source_profile = lab-trick-role-c\n",
+	} {
+		if !strings.Contains(exported.String(), want) {
+			t.Errorf("export output missing %q:\n%s", want, exported.String())
+		}
+	}
+
+	path := filepath.Join(t.TempDir(), "config")
+
+	err = os.WriteFile(path, exported.Bytes(), 0o600)
+	if err != nil {
+		t.Fatalf("failed to write shared config: %v", err)
+	}
+
+	var imported bytes.Buffer
+
+	err = importCommand(t.Context(), []string{"aws-config", "-file", path, "-profile", "lab"}, &imported)
+	if err != nil {
+		t.Fatalf("import error = %v", err)
+	}
+
+	want := `select_profile = profile.lab
+
+profile "lab" {
+  region = "eu-west-1"
+
+  chain {
+    ttl = 12
+
+    use {
+      arn   = "arn:aws:iam::123456789012:role/trick-role-a"
+      entry = true
+    }
+
+    use {
+      arn = "arn:aws:iam::123456789012:role/trick-role-b"
+    }
+
+    use {
+      arn = "arn:aws:iam::123456789012:role/trick-role-c"
+    }
+  }
+}
+`
+	if imported.String() != want {
+		t.Errorf("import output =\n%s\nwant\n%s", imported.String(), want)
+	}
+
+	err = os.WriteFile(path, []byte(exported.String()+"\n[profile other]\nrole_arn = arn:aws:iam::1:role/x\n"), 0o600)
+	if err != nil {
+		t.Fatalf("failed to write shared config: %v", err)
+	}
+
+	err = importCommand(t.Context(), []string{"aws-config", "-file", path}, &imported)
+	if !errors.Is(err, awsconfig.ErrAmbiguousChain) {
+		t.Errorf("import error = %v, want %v", err, awsconfig.ErrAmbiguousChain)
+	}
+
+	err = exportCommand(t.Context(), []string{"terraform"}, &exported)
+	if !errors.Is(err, ErrUnknownDestination) {
+		t.Errorf("export error = %v, want %v", err, ErrUnknownDestination)
+	}
+}
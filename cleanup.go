@@ -0,0 +1,295 @@
+// Copyright 2025 variHQ OÜ
+// SPDX-License-Identifier: BSD-3-Clause
+
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"log/slog"
+	"os"
+	"slices"
+
+	"github.com/wakeful/trick/internal/awsconfig"
+	"github.com/wakeful/trick/internal/state"
+)
+
+// ErrArtifactsRemain is returned when trick profiles are still present after cleanup.
+var ErrArtifactsRemain = errors.New("trick credentials remain after cleanup")
+
+// loadManifest opens the state manifest at path, or at the default location when path is empty.
+func loadManifest(path string) (*state.Manifest, error) {
+	if path == "" {
+		defaultPath, err := state.DefaultPath()
+		if err != nil {
+			return nil, fmt.Errorf("unable to locate state: %w", err)
+		}
+
+		path = defaultPath
+	}
+
+	manifest, err := state.Load(path)
+	if err != nil {
+		return nil, fmt.Errorf("unable to load state: %w", err)
+	}
+
+	return manifest, nil
+}
+
+// outputProfileArtifacts returns the sections profileName occupies in the shared credentials and config files.
+func outputProfileArtifacts(profileName string) ([]state.Artifact, error) {
+	credentialsFile, err := awsconfig.CredentialsFile()
+	if err != nil {
+		return nil, fmt.Errorf("unable to locate credentials file: %w", err)
+	}
+
+	configFile, err := awsconfig.ConfigFile()
+	if err != nil {
+		return nil, fmt.Errorf("unable to locate config file: %w", err)
+	}
+
+	return []state.Artifact{
+		{Kind: state.KindProfile, Path: credentialsFile, Section: profileName},
+		{Kind: state.KindProfile, Path: configFile, Section: awsconfig.ConfigSection(profileName)},
+	}, nil
+}
+
+// configArtifacts returns what a run with the resolved settings writes besides the output profile: its audit log,
+// control socket and UI socket.
+func configArtifacts(resolved *settings) ([]state.Artifact, error) {
+	artifacts := make([]state.Artifact, 0)
+
+	if resolved.audit.value != "" {
+		artifacts = append(artifacts, state.Artifact{ //nolint:exhaustruct
+			Kind: state.KindAuditLog,
+			Path: absPath(resolved.audit.value),
+		})
+	}
+
+	controlSocket, err := socketPath(resolved.socket.value)
+	if err != nil {
+		return nil, err
+	}
+
+	artifacts = append(artifacts, state.Artifact{Kind: state.KindSocket, Path: absPath(controlSocket)}) //nolint:exhaustruct
+
+	if resolved.uiSocket.value != "" {
+		artifacts = append(artifacts, state.Artifact{ //nolint:exhaustruct
+			Kind: state.KindSocket,
+			Path: absPath(resolved.uiSocket.value),
+		})
+	}
+
+	return artifacts, nil
+}
+
+// recordArtifacts adds artifacts to the manifest, logging instead of failing so a broken state never stops a run.
+func recordArtifacts(manifest *state.Manifest, artifacts ...state.Artifact) {
+	for _, artifact := range artifacts {
+		err := manifest.Add(artifact)
+		if err != nil {
+			slog.Warn("unable to record artifact",
+				slog.String("path", artifact.Path),
+				slog.String("error", err.Error()),
+			)
+		}
+	}
+}
+
+func describeArtifact(artifact state.Artifact) string {
+	if artifact.Kind == state.KindProfile {
+		return fmt.Sprintf("profile [%s] in %s", artifact.Section, artifact.Path)
+	}
+
+	return fmt.Sprintf("%s %s", artifact.Kind, artifact.Path)
+}
+
+// removeArtifact deletes artifact, treating anything already gone as removed. A socket artifact that names anything
+// but a socket is left alone.
+func removeArtifact(artifact state.Artifact) error {
+	if artifact.Kind == state.KindSocket {
+		info, err := os.Lstat(artifact.Path)
+		if err == nil && info.Mode()&fs.ModeSocket == 0 {
+			return fmt.Errorf("unable to remove %s: %w", describeArtifact(artifact), ErrNotSocket)
+		}
+	}
+
+	if artifact.Kind == state.KindProfile {
+		_, err := awsconfig.RemoveSection(artifact.Path, artifact.Section)
+		if err != nil {
+			return fmt.Errorf("unable to remove %s: %w", describeArtifact(artifact), err)
+		}
+
+		return nil
+	}
+
+	err := os.Remove(artifact.Path)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("unable to remove %s: %w", describeArtifact(artifact), err)
+	}
+
+	return nil
+}
+
+// artifactExists reports whether artifact is still present on disk.
+func artifactExists(artifact state.Artifact) (bool, error) {
+	if artifact.Kind == state.KindProfile {
+		found, err := awsconfig.HasSection(artifact.Path, artifact.Section)
+		if err != nil {
+			return false, fmt.Errorf("unable to inspect %s: %w", describeArtifact(artifact), err)
+		}
+
+		return found, nil
+	}
+
+	_, err := os.Lstat(artifact.Path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return false, nil
+	}
+
+	if err != nil {
+		return false, fmt.Errorf("unable to inspect %s: %w", describeArtifact(artifact), err)
+	}
+
+	return true, nil
+}
+
+// remainingProfiles lists every profile artifact that is still present on disk.
+func remainingProfiles(artifacts []state.Artifact) ([]state.Artifact, error) {
+	remaining := make([]state.Artifact, 0)
+
+	for _, artifact := range artifacts {
+		if artifact.Kind != state.KindProfile {
+			continue
+		}
+
+		found, err := artifactExists(artifact)
+		if err != nil {
+			return nil, err
+		}
+
+		if found {
+			remaining = append(remaining, artifact)
+		}
+	}
+
+	return remaining, nil
+}
+
+// cleanupCommand removes the output profile, every artifact recorded in the state manifest and the files and sockets
+// the run settings name. Audit logs are kept, so the engagement report can still be generated, unless -remove-audit
+// is given.
+//
+//nolint:cyclop,funlen
+func cleanupCommand(_ context.Context, args []string, stdout io.Writer) error {
+	flags := flag.NewFlagSet("cleanup", flag.ContinueOnError)
+	dryRun := flags.Bool("dry-run", false, "list what would be removed without removing anything")
+	removeAudit := flags.Bool("remove-audit", false, "also remove audit logs, which trick report reads")
+	declareRunFlags(flags)
+	flags.Usage = func() {
+		_, _ = fmt.Fprintln(flags.Output(), "Usage: trick cleanup [-dry-run] [-remove-audit] [trick flags]")
+		flags.PrintDefaults()
+	}
+
+	ok, err := parseFlags(flags, args)
+	if !ok {
+		return err
+	}
+
+	resolved, err := resolveSettings(flags, os.Getenv)
+	if err != nil {
+		return err
+	}
+
+	manifest, err := loadManifest(resolved.state.value)
+	if err != nil {
+		return err
+	}
+
+	profiles, err := outputProfileArtifacts(defaultProfileName)
+	if err != nil {
+		return err
+	}
+
+	configured, err := configArtifacts(resolved)
+	if err != nil {
+		return err
+	}
+
+	artifacts := slices.Clone(manifest.Artifacts)
+	for _, artifact := range slices.Concat(profiles, configured) {
+		if !slices.Contains(artifacts, artifact) {
+			artifacts = append(artifacts, artifact)
+		}
+	}
+
+	var errs []error
+
+	for _, artifact := range artifacts {
+		present, err := artifactExists(artifact)
+		if err != nil {
+			errs = append(errs, err)
+
+			continue
+		}
+
+		switch {
+		case !present && *dryRun:
+			continue
+		case !present:
+			slog.Debug("artifact already gone", slog.String("artifact", describeArtifact(artifact)))
+		case !*removeAudit && artifact.Kind == state.KindAuditLog:
+			_, _ = fmt.Fprintf(stdout, "kept %s\n", describeArtifact(artifact))
+
+			continue
+		case *dryRun:
+			_, _ = fmt.Fprintf(stdout, "would remove %s\n", describeArtifact(artifact))
+
+			continue
+		default:
+			err = removeArtifact(artifact)
+			if err != nil {
+				errs = append(errs, err)
+
+				continue
+			}
+
+			_, _ = fmt.Fprintf(stdout, "removed %s\n", describeArtifact(artifact))
+		}
+
+		err = manifest.Remove(artifact)
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	if *dryRun {
+		return errors.Join(errs...)
+	}
+
+	if len(errs) == 0 && len(manifest.Artifacts) == 0 {
+		err = removeArtifact(state.Artifact{Kind: state.KindFile, Path: manifest.Path(), Section: ""})
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	remaining, err := remainingProfiles(artifacts)
+	if err != nil {
+		errs = append(errs, err)
+	}
+
+	for _, artifact := range remaining {
+		_, _ = fmt.Fprintf(stdout, "still present %s\n", describeArtifact(artifact))
+	}
+
+	if len(remaining) > 0 {
+		errs = append(errs, fmt.Errorf("%w: %d profile(s)", ErrArtifactsRemain, len(remaining)))
+	}
+
+	return errors.Join(errs...)
+}
@@ -0,0 +1,118 @@
+// Copyright 2025 variHQ OÜ
+// SPDX-License-Identifier: BSD-3-Clause
+
+package main
+
+import (
+	"bytes"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/wakeful/trick/internal/state"
+)
+
+//nolint:paralleltest // t.Setenv points the AWS shared files at a temporary directory.
+func TestCleanupCommand(t *testing.T) {
+	dir := shortTempDir(t)
+	credentialsFile := filepath.Join(dir, "credentials")
+	configFile := filepath.Join(dir, "config")
+	auditLog := filepath.Join(dir, "audit.jsonl")
+	statePath := filepath.Join(dir, "state", "state.json")
+	uiSocket := filepath.Join(dir, "ui.sock")
+	trickConfig := writeConfig(t, strings.Replace(ringConfig, "  chain {",
+		"  server {\n    socket = \""+uiSocket+"\"\n  }\n\n  chain {", 1))
+
+	t.Setenv("AWS_SHARED_CREDENTIALS_FILE", credentialsFile)
+	t.Setenv("AWS_CONFIG_FILE", configFile)
+
+	files := map[string]string{
+		credentialsFile: "[default]\naws_access_key_id = KEEP\n\n[trick-jump-credentials]\naws_access_key_id = ASIA\n",
+		configFile:      "[default]\nregion = eu-west-1\n\n[profile trick-jump-credentials]\nregion = eu-west-1\n",
+		auditLog:        "{}\n",
+	}
+
+	for path, content := range files {
+		err := os.WriteFile(path, []byte(content), 0o600)
+		if err != nil {
+			t.Fatalf("failed to write %s: %v", path, err)
+		}
+	}
+
+	var listenConfig net.ListenConfig
+
+	listener, err := listenConfig.Listen(t.Context(), "unix", uiSocket)
+	if err != nil {
+		t.Fatalf("Listen() error = %v", err)
+	}
+
+	unixListener, _ := listener.(*net.UnixListener)
+	unixListener.SetUnlinkOnClose(false)
+
+	_ = listener.Close()
+
+	manifest, err := state.Load(statePath)
+	if err != nil {
+		t.Fatalf("state.Load() error = %v", err)
+	}
+
+	err = manifest.Add(state.Artifact{Kind: state.KindAuditLog, Path: auditLog})
+	if err != nil {
+		t.Fatalf("manifest.Add() error = %v", err)
+	}
+
+	var stdout bytes.Buffer
+
+	args := []string{"-state", statePath, "-config", trickConfig, "-socket", filepath.Join(dir, "trick.sock")}
+
+	err = cleanupCommand(t.Context(), append([]string{"-dry-run"}, args...), &stdout)
+	if err != nil {
+		t.Fatalf("cleanup -dry-run error = %v", err)
+	}
+
+	if strings.Count(stdout.String(), "would remove") != 3 || !strings.Contains(stdout.String(), "kept audit-log") {
+		t.Errorf("cleanup -dry-run output = %q, want two profile sections and the UI socket", stdout.String())
+	}
+
+	if _, err := os.Lstat(uiSocket); err != nil {
+		t.Errorf("cleanup -dry-run removed the UI socket: %v", err)
+	}
+
+	stdout.Reset()
+
+	err = cleanupCommand(t.Context(), args, &stdout)
+	if err != nil {
+		t.Fatalf("cleanup error = %v, output %q", err, stdout.String())
+	}
+
+	if _, err := os.Lstat(uiSocket); !os.IsNotExist(err) {
+		t.Errorf("cleanup left the UI socket of the config file behind")
+	}
+
+	if _, err := os.Stat(auditLog); err != nil {
+		t.Errorf("cleanup removed the audit log without -remove-audit: %v", err)
+	}
+
+	err = cleanupCommand(t.Context(), append([]string{"-remove-audit"}, args...), &stdout)
+	if err != nil {
+		t.Fatalf("cleanup -remove-audit error = %v, output %q", err, stdout.String())
+	}
+
+	for _, path := range []string{auditLog, statePath} {
+		if _, err := os.Stat(path); !os.IsNotExist(err) {
+			t.Errorf("cleanup left %s behind", path)
+		}
+	}
+
+	credentials, _ := os.ReadFile(credentialsFile)
+	if string(credentials) != "[default]\naws_access_key_id = KEEP\n\n" {
+		t.Errorf("cleanup credentials = %q", credentials)
+	}
+
+	config, _ := os.ReadFile(configFile)
+	if strings.Contains(string(config), "trick-jump-credentials") {
+		t.Errorf("cleanup config = %q", config)
+	}
+}
@@ -0,0 +1,193 @@
+// Copyright 2025 variHQ OÜ
+// SPDX-License-Identifier: BSD-3-Clause
+
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"text/tabwriter"
+
+	"github.com/wakeful/trick/internal/parser"
+)
+
+var (
+	// ErrMissingConfig indicates that a command needs the -config path.
+	ErrMissingConfig = errors.New("path to config file is required")
+	// ErrMissingChain indicates that the selected profile has no chain block.
+	ErrMissingChain = errors.New("profile has no chain")
+	// ErrUsage indicates that a command was called with flags or arguments it does not accept.
+	ErrUsage = errors.New("invalid arguments")
+	// ErrUnknownCommand indicates that the first argument names no subcommand.
+	ErrUnknownCommand = errors.New("unknown command")
+)
+
+// command is a subcommand selected by the first positional argument, e.g. `trick report`.
+type command struct {
+	summary string
+	run     func(ctx context.Context, args []string, stdout io.Writer) error
+}
+
+func subcommands() map[string]command {
+	return map[string]command{
+		"cleanup": {
+			summary: "remove every profile and file trick created",
+			run:     cleanupCommand,
+		},
+		"completion": {
+			summary: "print a bash, zsh or fish completion script",
+			run:     completionCommand,
+		},
+		"config": {
+			summary: "show the config file profile, or with -resolved the effective run settings",
+			run:     configCommand,
+		},
+		"credentials": {
+			summary: "show, export or wipe the output profile",
+			run:     credentialsCommand,
+		},
+		"ctl": {
+			summary: "query, reload or steer a running trick over its control socket",
+			run:     ctlCommand,
+		},
+		"detections": {
+			summary: "generate detection rules for the chain's footprint",
+			run:     detectionsCommand,
+		},
+		"diagram": {
+			summary: "print the ring as a Mermaid state diagram",
+			run:     diagramCommand,
+		},
+		"export": {
+			summary: "write the chain as AWS CLI profiles, one per hop",
+			run:     exportCommand,
+		},
+		"fmt": {
+			summary: "rewrite config files in canonical form, or turn -role/-use flags into one",
+			run:     fmtCommand,
+		},
+		"gen": {
+			summary: "generate Terraform, CloudFormation or IAM JSON for the chain",
+			run:     genCommand,
+		},
+		"help": {
+			summary: "list the commands",
+			run:     helpCommand,
+		},
+		"hunt": {
+			summary: "find role-juggling rings in local CloudTrail exports",
+			run:     huntCommand,
+		},
+		"import": {
+			summary: "build a trick profile from terraform output -json or AWS CLI profiles",
+			run:     importCommand,
+		},
+		"lint-policies": {
+			summary: "check offline that IAM policies allow every hop of the ring",
+			run:     lintPoliciesCommand,
+		},
+		"report": {
+			summary: "render an engagement report from the audit log",
+			run:     reportCommand,
+		},
+		"run": {
+			summary: "rotate through the ring and keep the output profile fresh (the default)",
+			run:     runCommand,
+		},
+		"status": {
+			summary: "show the current role and next hop of a run from its audit log",
+			run:     statusCommand,
+		},
+		"validate": {
+			summary: "check that config files describe a runnable ring",
+			run:     validateCommand,
+		},
+		"version": {
+			summary: "show version",
+			run:     versionCommand,
+		},
+	}
+}
+
+// dispatch runs the subcommand named by args[0] and reports whether one was found.
+func dispatch(ctx context.Context, args []string, stdout io.Writer) (bool, error) {
+	if len(args) == 0 {
+		return false, nil
+	}
+
+	cmd, ok := subcommands()[args[0]]
+	if !ok {
+		return false, nil
+	}
+
+	return true, cmd.run(ctx, args[1:], stdout)
+}
+
+// helpCommand lists every subcommand with its summary.
+func helpCommand(_ context.Context, _ []string, stdout io.Writer) error {
+	table := tabwriter.NewWriter(stdout, 0, 0, 2, ' ', 0) //nolint:mnd
+
+	_, _ = fmt.Fprintln(table, "Usage: trick <command> [flags]")
+	_, _ = fmt.Fprintln(table, "       trick [flags]              same as trick run")
+	_, _ = fmt.Fprintln(table, "\nCommands:")
+
+	commands := subcommands()
+	for _, name := range slices.Sorted(maps.Keys(commands)) {
+		_, _ = fmt.Fprintf(table, "  %s\t%s\n", name, commands[name].summary)
+	}
+
+	_, _ = fmt.Fprintln(table, "\nRun `trick <command> -h` for the flags of a command.")
+
+	err := table.Flush()
+	if err != nil {
+		return fmt.Errorf("failed to write help: %w", err)
+	}
+
+	return nil
+}
+
+// loadProfile parses the config file at path and returns the profile named name, or the selected one when name is empty.
+func loadProfile(path, name string) (*parser.Profile, error) {
+	if path == "" {
+		return nil, ErrMissingConfig
+	}
+
+	cfgFile, err := parser.ParseFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse config file: %w", err)
+	}
+
+	if name != "" {
+		cfgFile.SelectProfile = name
+	}
+
+	profile, err := cfgFile.Selected()
+	if err != nil {
+		return nil, fmt.Errorf("failed to select profile: %w", err)
+	}
+
+	if profile.Chain == nil {
+		return nil, fmt.Errorf("%w: %q", ErrMissingChain, profile.Name)
+	}
+
+	return profile, nil
+}
+
+// parseFlags parses args into flags, treating -h as success so subcommands can return early.
+func parseFlags(flags *flag.FlagSet, args []string) (bool, error) {
+	err := flags.Parse(args)
+	if errors.Is(err, flag.ErrHelp) {
+		return false, nil
+	}
+
+	if err != nil {
+		return false, fmt.Errorf("%w for %s: %w", ErrUsage, flags.Name(), err)
+	}
+
+	return true, nil
+}
@@ -0,0 +1,85 @@
+// Copyright 2025 variHQ OÜ
+// SPDX-License-Identifier: BSD-3-Clause
+
+package main
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestExecute(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		args     []string
+		want     string
+		wantErr  error
+		wantCode int
+	}{
+		{
+			name: "help lists every command",
+			args: []string{"help"},
+			want: "  validate       check that config files describe a runnable ring\n",
+		},
+		{
+			name: "version",
+			args: []string{"version"},
+			want: "trick dev https://github.com/wakeful/trick\n",
+		},
+		{
+			name: "version flag is an alias for run",
+			args: []string{"-version"},
+			want: "trick dev https://github.com/wakeful/trick\n",
+		},
+		{
+			name:     "unknown command",
+			args:     []string{"rotate"},
+			wantErr:  ErrUnknownCommand,
+			wantCode: exitUsage,
+		},
+		{
+			name:     "unknown flag",
+			args:     []string{"run", "-no-such-flag"},
+			wantErr:  ErrUsage,
+			wantCode: exitUsage,
+		},
+		{
+			name:     "unknown generator",
+			args:     []string{"gen", "pulumi"},
+			wantErr:  ErrUnknownTarget,
+			wantCode: exitUsage,
+		},
+		{
+			name:     "failure",
+			args:     []string{"validate", "does-not-exist.hcl"},
+			want:     "does-not-exist.hcl: failed to read config file",
+			wantErr:  ErrInvalidConfig,
+			wantCode: exitConfig,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var out bytes.Buffer
+
+			err := execute(t.Context(), tt.args, &out)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("execute() error = %v, want %v", err, tt.wantErr)
+			}
+
+			if err != nil && exitCode(err) != tt.wantCode {
+				t.Errorf("exitCode() = %d, want %d", exitCode(err), tt.wantCode)
+			}
+
+			if !strings.Contains(out.String(), tt.want) {
+				t.Errorf("execute() output = %q, want it to contain %q", out.String(), tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,212 @@
+// Copyright 2025 variHQ OÜ
+// SPDX-License-Identifier: BSD-3-Clause
+
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"strings"
+	"text/template"
+)
+
+// ErrUnknownShell indicates that trick completion was asked for a shell it has no script for.
+var ErrUnknownShell = errors.New("unknown shell")
+
+const bashCompletion = `# bash completion for trick, generated by ` + "`trick completion bash`" + `
+_trick() {
+    local cur="${COMP_WORDS[COMP_CWORD]}"
+    local cmd="${COMP_WORDS[1]}"
+    local run_flags="{{ range .RunFlags }} -{{ .Name }}{{ end }}"
+
+    if [[ ${COMP_CWORD} -eq 1 && ${cur} != -* ]]; then
+        COMPREPLY=($(compgen -W "{{ join .CommandNames " " }}" -- "${cur}"))
+        return
+    fi
+
+    if [[ ${COMP_CWORD} -eq 2 ]]; then
+        case "${cmd}" in
+{{- range .Nested }}
+            {{ .Command }})
+                COMPREPLY=($(compgen -W "{{ join .Words " " }}" -- "${cur}"))
+                return
+                ;;
+{{- end }}
+        esac
+    fi
+
+    if [[ ${cur} == -* ]]; then
+        case "${cmd}" in
+            -*|{{ join .RunCommands "|" }})
+                COMPREPLY=($(compgen -W "${run_flags}" -- "${cur}"))
+                return
+                ;;
+        esac
+    fi
+
+    COMPREPLY=($(compgen -f -- "${cur}"))
+}
+
+complete -o filenames -F _trick trick
+`
+
+const zshCompletion = `#compdef trick
+# zsh completion for trick, generated by ` + "`trick completion zsh`" + `
+_trick() {
+  local -a commands run_flags
+  commands=(
+{{- range .Commands }}
+    '{{ .Name }}:{{ zshQuote .Summary }}'
+{{- end }}
+  )
+  run_flags=({{ range .RunFlags }} -{{ .Name }}{{ end }})
+
+  if (( CURRENT == 2 )) && [[ ${words[2]} != -* ]]; then
+    _describe 'command' commands
+    return
+  fi
+
+  if (( CURRENT == 3 )); then
+    case ${words[2]} in
+{{- range .Nested }}
+      {{ .Command }})
+        compadd -- {{ join .Words " " }}
+        return
+        ;;
+{{- end }}
+    esac
+  fi
+
+  if [[ ${words[CURRENT]} == -* ]]; then
+    case ${words[2]} in
+      -*|{{ join .RunCommands "|" }})
+        compadd -- ${run_flags}
+        return
+        ;;
+    esac
+  fi
+
+  _files
+}
+
+compdef _trick trick
+`
+
+const fishCompletion = `# fish completion for trick, generated by ` + "`trick completion fish`" + `
+{{- range .Commands }}
+complete -c trick -n __fish_use_subcommand -f -a {{ .Name }} -d '{{ fishQuote .Summary }}'
+{{- end }}
+{{- range .Nested }}
+complete -c trick -n '__fish_seen_subcommand_from {{ .Command }}' -f -a '{{ join .Words " " }}'
+{{- end }}
+{{- range .RunFlags }}
+complete -c trick -n '__fish_use_subcommand; or __fish_seen_subcommand_from {{ join $.RunCommands " " }}' -o {{ .Name }}
+{{- if .Value }} -r{{ end }} -d '{{ fishQuote .Usage }}'
+{{- end }}
+`
+
+// completionWord is a subcommand offered by the completion scripts.
+type completionWord struct {
+	Name    string
+	Summary string
+}
+
+// completionFlag is a flag of trick run together with whether it takes a value.
+type completionFlag struct {
+	Name  string
+	Usage string
+	Value bool
+}
+
+// completionNested lists the words accepted right after a subcommand, e.g. the targets of trick gen.
+type completionNested struct {
+	Command string
+	Words   []string
+}
+
+type completionData struct {
+	Commands     []completionWord
+	CommandNames []string
+	Nested       []completionNested
+	RunCommands  []string
+	RunFlags     []completionFlag
+}
+
+func completionScripts() map[string]string {
+	return map[string]string{
+		"bash": bashCompletion,
+		"fish": fishCompletion,
+		"zsh":  zshCompletion,
+	}
+}
+
+func newCompletionData() completionData {
+	commands := subcommands()
+	data := completionData{
+		Commands:     make([]completionWord, 0, len(commands)),
+		CommandNames: slices.Sorted(maps.Keys(commands)),
+		Nested: []completionNested{
+			{Command: "completion", Words: slices.Sorted(maps.Keys(completionScripts()))},
+			{Command: "config", Words: []string{"show"}},
+			{Command: "ctl", Words: ctlVerbs()},
+			{Command: "export", Words: slices.Sorted(maps.Keys(exporters()))},
+			{Command: "fmt", Words: []string{"from-flags"}},
+			{Command: "gen", Words: slices.Sorted(maps.Keys(generators()))},
+			{Command: "import", Words: slices.Sorted(maps.Keys(importers()))},
+		},
+		RunCommands: []string{"config", "diagram", "run"},
+		RunFlags:    make([]completionFlag, 0),
+	}
+
+	for _, name := range data.CommandNames {
+		data.Commands = append(data.Commands, completionWord{Name: name, Summary: commands[name].summary})
+	}
+
+	flags := flag.NewFlagSet("run", flag.ContinueOnError)
+	declareRunFlags(flags)
+	flags.VisitAll(func(f *flag.Flag) {
+		boolFlag, ok := f.Value.(interface{ IsBoolFlag() bool })
+		data.RunFlags = append(data.RunFlags, completionFlag{
+			Name:  f.Name,
+			Usage: f.Usage,
+			Value: !ok || !boolFlag.IsBoolFlag(),
+		})
+	})
+
+	return data
+}
+
+// completionCommand prints the completion script for the shell named by args[0].
+func completionCommand(_ context.Context, args []string, stdout io.Writer) error {
+	shells := strings.Join(slices.Sorted(maps.Keys(completionScripts())), ", ")
+
+	if len(args) != 1 {
+		return fmt.Errorf("%w: expected one of %s", ErrUnknownShell, shells)
+	}
+
+	script, found := completionScripts()[args[0]]
+	if !found {
+		return fmt.Errorf("%w: %q, expected one of %s", ErrUnknownShell, args[0], shells)
+	}
+
+	tmpl, err := template.New(args[0]).Funcs(template.FuncMap{
+		"join":      strings.Join,
+		"zshQuote":  strings.NewReplacer("'", `'\''`, ":", `\:`).Replace,
+		"fishQuote": strings.NewReplacer(`\`, `\\`, "'", `\'`).Replace,
+	}).Parse(script)
+	if err != nil {
+		return fmt.Errorf("failed to parse completion template: %w", err)
+	}
+
+	err = tmpl.Execute(stdout, newCompletionData())
+	if err != nil {
+		return fmt.Errorf("failed to write completion script: %w", err)
+	}
+
+	return nil
+}
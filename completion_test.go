@@ -0,0 +1,86 @@
+// Copyright 2025 variHQ OÜ
+// SPDX-License-Identifier: BSD-3-Clause
+
+package main
+
+import (
+	"bytes"
+	"errors"
+	"os/exec"
+	"strings"
+	"testing"
+)
+
+func TestCompletionCommand(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		shell string
+		want  []string
+	}{
+		{
+			shell: "bash",
+			want: []string{
+				"complete -o filenames -F _trick trick\n",
+				`compgen -W "cloudformation iam-json terraform"`,
+				" -config ",
+			},
+		},
+		{
+			shell: "zsh",
+			want: []string{
+				"#compdef trick\n",
+				"    'detections:generate detection rules for the chain'\\''s footprint'\n",
+			},
+		},
+		{
+			shell: "fish",
+			want: []string{
+				"complete -c trick -n __fish_use_subcommand -f -a validate -d 'check that config files describe a runnable ring'\n",
+				"-o refresh -r -d 'refresh IAM every n minutes'\n",
+				"-o ui -d 'starts role visualization on -listen'\n",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.shell, func(t *testing.T) {
+			t.Parallel()
+
+			var out bytes.Buffer
+
+			err := completionCommand(t.Context(), []string{tt.shell}, &out)
+			if err != nil {
+				t.Fatalf("completion error = %v", err)
+			}
+
+			for _, want := range tt.want {
+				if !strings.Contains(out.String(), want) {
+					t.Errorf("completion output missing %q", want)
+				}
+			}
+
+			shell, err := exec.LookPath(tt.shell)
+			if err != nil {
+				return
+			}
+
+			check := exec.CommandContext(t.Context(), shell, "-n")
+			if tt.shell == "fish" {
+				check = exec.CommandContext(t.Context(), shell, "--no-execute")
+			}
+
+			check.Stdin = &out
+
+			output, err := check.CombinedOutput()
+			if err != nil {
+				t.Errorf("%s rejected the script: %v\n%s", tt.shell, err, output)
+			}
+		})
+	}
+
+	err := completionCommand(t.Context(), []string{"powershell"}, &bytes.Buffer{})
+	if !errors.Is(err, ErrUnknownShell) {
+		t.Errorf("completion error = %v, want %v", err, ErrUnknownShell)
+	}
+}
@@ -0,0 +1,75 @@
+// Copyright 2025 variHQ OÜ
+// SPDX-License-Identifier: BSD-3-Clause
+
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/wakeful/trick/internal/parser"
+)
+
+// ErrUnknownConfigAction indicates that trick config was called without a supported action.
+var ErrUnknownConfigAction = errors.New("unknown config action, expected show")
+
+// configCommand inspects the configuration of a plain trick run, e.g. `trick config show --resolved`.
+// Secrets of the server block are masked either way.
+func configCommand(_ context.Context, args []string, stdout io.Writer) error {
+	if len(args) == 0 || args[0] != "show" {
+		return ErrUnknownConfigAction
+	}
+
+	flags := flag.NewFlagSet("config show", flag.ContinueOnError)
+	resolvedOnly := flags.Bool("resolved", false, "print the effective settings and where each one came from")
+	declareRunFlags(flags)
+	flags.Usage = func() {
+		_, _ = fmt.Fprintln(flags.Output(), "Usage: trick config show [-resolved] [trick flags]")
+		flags.PrintDefaults()
+	}
+
+	ok, err := parseFlags(flags, args[1:])
+	if !ok {
+		return err
+	}
+
+	resolved, err := resolveSettings(flags, os.Getenv)
+	if err != nil {
+		return err
+	}
+
+	if *resolvedOnly {
+		return resolved.write(stdout)
+	}
+
+	profile, err := loadProfile(resolved.config.value, resolved.profile.value)
+	if err != nil {
+		return err
+	}
+
+	if profile.Server != nil {
+		server := *profile.Server
+		server.Token = mask(server.Token)
+		server.BasicAuth = maskBasicAuth(server.BasicAuth)
+		profile.Server = &server
+	}
+
+	config := &parser.Config{SelectProfile: profile.Name, Profiles: []*parser.Profile{profile}}
+
+	return writeProfile(config, "", stdout)
+}
+
+// maskBasicAuth hides the password of user:password credentials, keeping the user so the output still parses.
+func maskBasicAuth(value string) string {
+	user, password, found := strings.Cut(value, ":")
+	if !found {
+		return mask(value)
+	}
+
+	return user + ":" + mask(password)
+}
@@ -0,0 +1,99 @@
+// Copyright 2025 variHQ OÜ
+// SPDX-License-Identifier: BSD-3-Clause
+
+package main
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestConfigShow(t *testing.T) {
+	t.Parallel()
+
+	config := writeConfig(t, ringConfig)
+
+	var out bytes.Buffer
+
+	err := configCommand(t.Context(), []string{"show", "--resolved", "-config", config, "-refresh", "5"}, &out)
+	if err != nil {
+		t.Fatalf("config show --resolved error = %v", err)
+	}
+
+	rows := make(map[string]string)
+
+	for line := range strings.Lines(out.String()) {
+		fields := strings.Fields(line)
+		rows[fields[0]] = strings.Join(fields[1:], " ")
+	}
+
+	for setting, want := range map[string]string{
+		"SETTING": "VALUE SOURCE",
+		"profile": "ring config file",
+		"refresh": "5 flag -refresh",
+		"region":  "eu-west-1 default",
+		"audit":   "- default",
+	} {
+		if rows[setting] != want {
+			t.Errorf("config show --resolved %s = %q, want %q", setting, rows[setting], want)
+		}
+	}
+
+	out.Reset()
+
+	err = configCommand(t.Context(), []string{"show", "-config", config}, &out)
+	if err != nil {
+		t.Fatalf("config show error = %v", err)
+	}
+
+	if !strings.HasPrefix(out.String(), "select_profile = profile.ring\n") {
+		t.Errorf("config show output =\n%s", out.String())
+	}
+
+	err = configCommand(t.Context(), []string{"edit"}, &out)
+	if !errors.Is(err, ErrUnknownConfigAction) {
+		t.Errorf("config error = %v, want %v", err, ErrUnknownConfigAction)
+	}
+}
+
+func TestConfigShowMasksSecrets(t *testing.T) {
+	t.Parallel()
+
+	config := writeConfig(t, strings.Replace(ringConfig, "  chain {", `  server {
+    token      = "s3cret-token"
+    basic_auth = "red:s3cret-password"
+  }
+
+  chain {`, 1))
+
+	for _, args := range [][]string{
+		{"show", "-config", config},
+		{"show", "-resolved", "-config", config},
+	} {
+		var out bytes.Buffer
+
+		err := configCommand(t.Context(), args, &out)
+		if err != nil {
+			t.Fatalf("config %v error = %v", args, err)
+		}
+
+		if strings.Contains(out.String(), "s3cret") {
+			t.Errorf("config %v leaks a secret:\n%s", args, out.String())
+		}
+	}
+
+	var out bytes.Buffer
+
+	err := configCommand(t.Context(), []string{"show", "-config", config}, &out)
+	if err != nil {
+		t.Fatalf("config show error = %v", err)
+	}
+
+	for _, want := range []string{`token      = "********"`, `basic_auth = "red:********"`} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("config show output lacks %s:\n%s", want, out.String())
+		}
+	}
+}
@@ -0,0 +1,381 @@
+// Copyright 2025 variHQ OÜ
+// SPDX-License-Identifier: BSD-3-Clause
+
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"maps"
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/wakeful/trick/internal/audit"
+	"github.com/wakeful/trick/internal/broadcast"
+)
+
+var (
+	// ErrUnknownControlAction indicates a control request for an action trick does not know.
+	ErrUnknownControlAction = errors.New(
+		"unknown control action, expected jump, pause, resume, pin, unpin, skip, unskip or reload",
+	)
+	// ErrRoleNotInRing indicates a control request naming a role that is not a member of the ring.
+	ErrRoleNotInRing = errors.New("role is not in the ring")
+	// ErrRoleNotUsable indicates a pin request for a role without meaningful permissions.
+	ErrRoleNotUsable = errors.New("role is not usable")
+	// ErrRoleUnavailable indicates a request that conflicts with a pinned or disabled role.
+	ErrRoleUnavailable = errors.New("role is pinned or disabled")
+	// ErrNoUsableRoleLeft indicates a skip request or reload that would disable every usable role.
+	ErrNoUsableRoleLeft = errors.New("no usable role would be left")
+	// ErrNotRunning indicates a control request the run loop did not answer.
+	ErrNotRunning = errors.New("run loop is not accepting control requests")
+	// ErrReloadUnavailable indicates a reload request to a run that was not started from settings it can re-read.
+	ErrReloadUnavailable = errors.New("reload is not available")
+)
+
+// Control actions accepted by the control API and trick ctl.
+const (
+	actionJump   = "jump"
+	actionPause  = "pause"
+	actionResume = "resume"
+	actionPin    = "pin"
+	actionUnpin  = "unpin"
+	actionSkip   = "skip"
+	actionUnskip = "unskip"
+	actionReload = "reload"
+)
+
+// defaultSkipDuration is how long skip disables a hop when the request gives no duration.
+const defaultSkipDuration = time.Hour
+
+// controlRequest asks the run loop to change course; the loop answers on reply.
+type controlRequest struct {
+	action string
+	role   string
+	// duration is how long skip disables the role
+	duration time.Duration
+	reply    chan controlReply
+}
+
+type controlReply struct {
+	status liveStatus
+	err    error
+}
+
+// controlActions lists the actions of the control API in the order trick ctl documents them.
+func controlActions() []string {
+	return []string{
+		actionJump, actionPause, actionResume, actionPin, actionUnpin, actionSkip, actionUnskip, actionReload,
+	}
+}
+
+// sendControl hands req to the run loop and waits for its answer or for ctx to end.
+func (a *App) sendControl(ctx context.Context, req controlRequest) (liveStatus, error) {
+	req.reply = make(chan controlReply, 1)
+
+	select {
+	case a.control <- req:
+	case <-ctx.Done():
+		return liveStatus{}, fmt.Errorf("%w: %w", ErrNotRunning, ctx.Err()) //nolint:exhaustruct
+	}
+
+	select {
+	case reply := <-req.reply:
+		return reply.status, reply.err
+	case <-ctx.Done():
+		return liveStatus{}, fmt.Errorf("%w: %w", ErrNotRunning, ctx.Err()) //nolint:exhaustruct
+	}
+}
+
+// serveControl applies req on the run goroutine and answers it. A jump runs a tick right away and restarts the
+// ticker; the returned error is that tick's when it must stop the run.
+func (a *App) serveControl(ctx context.Context, req controlRequest, ticker *time.Ticker, retry *time.Timer) error {
+	slog.Info("control request", slog.String("action", req.action), slog.String("role", req.role))
+
+	var err error
+
+	switch req.action {
+	case actionJump:
+		if a.refresh > 0 {
+			ticker.Reset(a.refresh)
+		}
+
+		err = a.step(ctx, retry)
+	case actionReload:
+		err = a.reload(ticker)
+	default:
+		err = a.applyControl(req, retry)
+	}
+
+	req.reply <- controlReply{status: a.snapshot(), err: err}
+
+	if err != nil && req.action == actionJump && !retryable(err) {
+		return err
+	}
+
+	return nil
+}
+
+// applyControl changes the pause, pin or skip state of the ring.
+//
+//nolint:cyclop
+func (a *App) applyControl(req controlRequest, retry *time.Timer) error {
+	if req.action != actionPause && req.action != actionResume && req.action != actionUnpin &&
+		!slices.Contains(a.members, req.role) {
+		return fmt.Errorf("%w: %q", ErrRoleNotInRing, req.role)
+	}
+
+	switch req.action {
+	case actionPause:
+		a.paused = true
+	case actionResume:
+		a.paused = false
+
+		if !a.brokenAt.IsZero() {
+			retry.Reset(0)
+		}
+	case actionPin:
+		_, usable := a.usableRoles[req.role]
+		if !usable && len(a.usableRoles) != 0 {
+			return fmt.Errorf("%w: %q", ErrRoleNotUsable, req.role)
+		}
+
+		if a.skipping(req.role) {
+			return fmt.Errorf("%w: %q is disabled", ErrRoleUnavailable, req.role)
+		}
+
+		a.pinned = req.role
+	case actionUnpin:
+		a.pinned = ""
+	case actionSkip:
+		if req.role == a.pinned {
+			return fmt.Errorf("%w: %q is pinned", ErrRoleUnavailable, req.role)
+		}
+
+		if !slices.ContainsFunc(a.members, func(role string) bool { return role != req.role && a.canStop(role) }) {
+			return fmt.Errorf("%w: cannot disable %q", ErrNoUsableRoleLeft, req.role)
+		}
+
+		duration := req.duration
+		if duration <= 0 {
+			duration = defaultSkipDuration
+		}
+
+		if a.skipped == nil {
+			a.skipped = make(map[string]time.Time)
+		}
+
+		a.skipped[req.role] = time.Now().Add(duration).UTC()
+	case actionUnskip:
+		delete(a.skipped, req.role)
+	default:
+		return fmt.Errorf("%w: %q", ErrUnknownControlAction, req.action)
+	}
+
+	a.setStatus(func(status *liveStatus) {
+		status.Paused = a.paused
+		status.Pinned = a.pinned
+		status.Skipped = maps.Clone(a.skipped)
+
+		if status.State != stateBroken && status.State != stateStarting {
+			status.State = a.state()
+		}
+
+		if a.paused {
+			status.NextJump = nil
+		}
+	})
+
+	a.chainUpdated()
+
+	return nil
+}
+
+// state is the status of a ring whose last tick succeeded.
+func (a *App) state() string {
+	if a.paused {
+		return statePaused
+	}
+
+	return stateRunning
+}
+
+// isUsable reports whether the chain may stop at role: only the pinned role while one is pinned, otherwise any role
+// with meaningful permissions.
+func (a *App) isUsable(role string) bool {
+	if a.pinned != "" {
+		return role == a.pinned
+	}
+
+	_, usable := a.usableRoles[role]
+
+	return usable || len(a.usableRoles) == 0
+}
+
+// canStop reports whether the chain may stop at role and write its credentials: it is usable and not disabled.
+func (a *App) canStop(role string) bool {
+	return a.isUsable(role) && !a.skipping(role)
+}
+
+// skipping reports whether role is disabled, forgetting the skip once it has expired.
+func (a *App) skipping(role string) bool {
+	until, found := a.skipped[role]
+	if !found {
+		return false
+	}
+
+	if time.Now().After(until) {
+		delete(a.skipped, role)
+
+		a.setStatus(func(status *liveStatus) { status.Skipped = maps.Clone(a.skipped) })
+		a.chainUpdated()
+
+		return false
+	}
+
+	return true
+}
+
+// apiControl serves POST /api/control/{action}, answering with the status after the action or with the error that
+// prevented it.
+func apiControl(app *App) http.HandlerFunc {
+	return func(writer http.ResponseWriter, request *http.Request) {
+		req := controlRequest{ //nolint:exhaustruct
+			action: request.PathValue("action"),
+			role:   request.FormValue("role"),
+		}
+
+		if value := request.FormValue("for"); value != "" {
+			duration, err := time.ParseDuration(value)
+			if err != nil {
+				http.Error(writer, "for must be a duration such as 30m", http.StatusBadRequest)
+
+				return
+			}
+
+			req.duration = duration
+		}
+
+		if !slices.Contains(controlActions(), req.action) {
+			http.Error(writer, ErrUnknownControlAction.Error(), http.StatusNotFound)
+
+			return
+		}
+
+		status, err := app.sendControl(request.Context(), req)
+
+		switch {
+		case errors.Is(err, ErrNotRunning):
+			http.Error(writer, err.Error(), http.StatusServiceUnavailable)
+		case errors.Is(err, ErrRoleNotInRing), errors.Is(err, ErrRoleNotUsable),
+			errors.Is(err, ErrRoleUnavailable), errors.Is(err, ErrNoUsableRoleLeft),
+			errors.Is(err, ErrReloadUnavailable):
+			http.Error(writer, err.Error(), http.StatusConflict)
+		case err != nil:
+			http.Error(writer, err.Error(), http.StatusBadGateway)
+		default:
+			writeJSON(writer, status)
+		}
+	}
+}
+
+// reload re-reads the run settings and rebuilds the ring, its usable roles, entry points and refresh interval.
+// The next hop continues after the current role when it is still a member, and from the first role otherwise.
+//
+//nolint:cyclop,funlen
+func (a *App) reload(ticker *time.Ticker) error {
+	if a.loadSettings == nil {
+		return ErrReloadUnavailable
+	}
+
+	resolved, err := a.loadSettings()
+	if err != nil {
+		return withClass(ClassConfig, fmt.Errorf("failed to reload settings: %w", err))
+	}
+
+	roles := resolved.roles.value
+
+	pool, err := setRolePool(roles, a.guardrails.accounts()...)
+	if err != nil {
+		return withClass(ClassConfig, fmt.Errorf("failed to reload role pool: %w", err))
+	}
+
+	usableRoles := make(map[string]struct{})
+
+	for _, role := range resolved.useRoles.value {
+		if !slices.Contains(roles, role) {
+			return withClass(ClassConfig, fmt.Errorf("%w: %s", ErrUsableRoleNotInRoleList, role))
+		}
+
+		usableRoles[role] = struct{}{}
+	}
+
+	if !slices.ContainsFunc(roles, func(role string) bool {
+		_, usable := usableRoles[role]
+
+		return (usable || len(usableRoles) == 0) && !a.skipping(role)
+	}) {
+		return withClass(ClassConfig, fmt.Errorf("%w: every usable role of the reloaded ring is disabled",
+			ErrNoUsableRoleLeft))
+	}
+
+	position := 0
+	if idx := slices.Index(roles, a.current); idx >= 0 {
+		position = (idx + 1) % len(roles)
+	}
+
+	for range position {
+		pool = pool.Next()
+	}
+
+	refresh := time.Duration(max(resolved.refresh.value, 1)) * time.Minute
+
+	a.statusMu.Lock()
+	a.members = roles
+	a.entries = resolved.entries
+	a.usableRoles = usableRoles
+	a.refresh = refresh
+	a.statusMu.Unlock()
+
+	a.roles = pool
+	a.position = position
+
+	ticker.Reset(refresh)
+
+	_, pinnedUsable := usableRoles[a.pinned]
+	if a.pinned != "" && (!slices.Contains(roles, a.pinned) || (!pinnedUsable && len(usableRoles) != 0)) {
+		slog.Warn("pinned role is no longer usable, unpinning", slog.String("role", a.pinned))
+
+		a.pinned = ""
+	}
+
+	maps.DeleteFunc(a.skipped, func(role string, _ time.Time) bool { return !slices.Contains(roles, role) })
+
+	a.setStatus(func(status *liveStatus) {
+		status.Pinned = a.pinned
+		status.Skipped = maps.Clone(a.skipped)
+	})
+
+	slog.Info("config reloaded", slog.Int("roles", len(roles)), slog.Int("usable", len(usableRoles)))
+
+	a.audit.Record(audit.Entry{ //nolint:exhaustruct
+		Event:       audit.EventConfigReloaded,
+		Chain:       "main",
+		Roles:       roles,
+		UsableRoles: resolved.useRoles.value,
+		Refresh:     int64(refresh.Minutes()),
+	})
+
+	a.broadcaster.Publish(broadcast.Message{ //nolint:exhaustruct
+		Event: broadcast.EventConfigReloaded,
+		Chain: "main",
+		Role:  a.current,
+		Hop:   max(slices.Index(roles, a.current), 0),
+	})
+	a.chainUpdated()
+
+	return nil
+}
@@ -0,0 +1,396 @@
+// Copyright 2025 variHQ OÜ
+// SPDX-License-Identifier: BSD-3-Clause
+
+package main
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/wakeful/trick/internal/broadcast"
+)
+
+func newControlApp(usable ...string) *App {
+	usableRoles := make(map[string]struct{})
+	for _, role := range usable {
+		usableRoles[role] = struct{}{}
+	}
+
+	return &App{ //nolint:exhaustruct
+		members: []string{
+			"arn:aws:iam::123456789012:role/trick-role-a",
+			"arn:aws:iam::123456789012:role/trick-role-b",
+			"arn:aws:iam::123456789012:role/trick-role-c",
+		},
+		usableRoles: usableRoles,
+		broadcaster: broadcast.NewBroadcaster(),
+		control:     make(chan controlRequest),
+		status:      liveStatus{Chain: "main", State: stateRunning}, //nolint:exhaustruct
+	}
+}
+
+func TestApp_applyControl(t *testing.T) {
+	t.Parallel()
+
+	const (
+		roleA = "arn:aws:iam::123456789012:role/trick-role-a"
+		roleB = "arn:aws:iam::123456789012:role/trick-role-b"
+		roleC = "arn:aws:iam::123456789012:role/trick-role-c"
+	)
+
+	tests := []struct {
+		name       string
+		usable     []string
+		requests   []controlRequest
+		wantErr    error
+		wantState  string
+		wantPinned string
+		wantSkips  []string
+	}{
+		{
+			name:      "pause",
+			requests:  []controlRequest{{action: actionPause}},
+			wantState: statePaused,
+		},
+		{
+			name:      "pause and resume",
+			requests:  []controlRequest{{action: actionPause}, {action: actionResume}},
+			wantState: stateRunning,
+		},
+		{
+			name:       "pin a usable role",
+			usable:     []string{roleB},
+			requests:   []controlRequest{{action: actionPin, role: roleB}},
+			wantState:  stateRunning,
+			wantPinned: roleB,
+		},
+		{
+			name:     "pin a transit role",
+			usable:   []string{roleB},
+			requests: []controlRequest{{action: actionPin, role: roleA}},
+			wantErr:  ErrRoleNotUsable,
+		},
+		{
+			name:     "pin a role outside the ring",
+			requests: []controlRequest{{action: actionPin, role: "arn:aws:iam::123456789012:role/other"}},
+			wantErr:  ErrRoleNotInRing,
+		},
+		{
+			name:      "skip a hop",
+			requests:  []controlRequest{{action: actionSkip, role: roleA, duration: time.Minute}},
+			wantState: stateRunning,
+			wantSkips: []string{roleA},
+		},
+		{
+			name: "skip and unskip",
+			requests: []controlRequest{
+				{action: actionSkip, role: roleA},
+				{action: actionUnskip, role: roleA},
+			},
+			wantState: stateRunning,
+		},
+		{
+			name:     "skip the pinned role",
+			requests: []controlRequest{{action: actionPin, role: roleC}, {action: actionSkip, role: roleC}},
+			wantErr:  ErrRoleUnavailable,
+		},
+		{
+			name:     "skip the last usable role",
+			usable:   []string{roleB},
+			requests: []controlRequest{{action: actionSkip, role: roleB}},
+			wantErr:  ErrNoUsableRoleLeft,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			app := newControlApp(tt.usable...)
+			retry := time.NewTimer(time.Hour)
+
+			defer retry.Stop()
+
+			var err error
+			for _, req := range tt.requests {
+				err = app.applyControl(req, retry)
+			}
+
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("applyControl() error = %v, want %v", err, tt.wantErr)
+			}
+
+			if tt.wantErr != nil {
+				return
+			}
+
+			status := app.snapshot()
+			if status.State != tt.wantState || status.Pinned != tt.wantPinned || len(status.Skipped) != len(tt.wantSkips) {
+				t.Errorf("status = %+v, want state %q, pinned %q, skipped %v",
+					status, tt.wantState, tt.wantPinned, tt.wantSkips)
+			}
+
+			for _, role := range tt.wantSkips {
+				if !app.skipping(role) {
+					t.Errorf("role %s is not skipped", role)
+				}
+			}
+
+			if updates := app.broadcaster.History(0); len(updates) != len(tt.requests) ||
+				updates[0].Event != broadcast.EventChainUpdated {
+				t.Errorf("published %+v, want a chain-updated event per request", updates)
+			}
+		})
+	}
+}
+
+// serveOneControl answers the next control request of app the way the run loop would.
+func serveOneControl(ctx context.Context, app *App) {
+	ticker := time.NewTicker(time.Hour)
+	retry := time.NewTimer(time.Hour)
+
+	defer ticker.Stop()
+	defer retry.Stop()
+
+	select {
+	case req := <-app.control:
+		_ = app.serveControl(ctx, req, ticker, retry)
+	case <-ctx.Done():
+	}
+}
+
+func TestAPIControl(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		token      string
+		user       string
+		header     string
+		site       string
+		action     string
+		form       url.Values
+		serve      bool
+		wantStatus int
+		wantBody   string
+	}{
+		{name: "disabled", token: "", header: "Bearer ", action: actionPause, wantStatus: http.StatusForbidden},
+		{name: "missing token", token: "s3cret", header: "", action: actionPause, wantStatus: http.StatusUnauthorized},
+		{name: "wrong token", token: "s3cret", header: "Bearer nope", action: actionPause, wantStatus: http.StatusUnauthorized},
+		{name: "unknown action", token: "s3cret", header: "Bearer s3cret", action: "reboot", wantStatus: http.StatusNotFound},
+		{
+			name:       "cross-site basic auth",
+			user:       "red",
+			header:     "Basic cmVkOnRlYW0=",
+			site:       "cross-site",
+			action:     actionPause,
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			name:       "same-origin basic auth",
+			user:       "red",
+			header:     "Basic cmVkOnRlYW0=",
+			site:       "same-origin",
+			action:     actionPause,
+			serve:      true,
+			wantStatus: http.StatusOK,
+			wantBody:   `"state":"paused"`,
+		},
+		{
+			name:       "invalid duration",
+			token:      "s3cret",
+			header:     "Bearer s3cret",
+			action:     actionSkip,
+			form:       url.Values{"role": {"x"}, "for": {"soon"}},
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "pause",
+			token:      "s3cret",
+			header:     "Bearer s3cret",
+			action:     actionPause,
+			serve:      true,
+			wantStatus: http.StatusOK,
+			wantBody:   `"state":"paused"`,
+		},
+		{
+			name:       "conflict",
+			token:      "s3cret",
+			header:     "Bearer s3cret",
+			action:     actionPin,
+			form:       url.Values{"role": {"arn:aws:iam::123456789012:role/other"}},
+			serve:      true,
+			wantStatus: http.StatusConflict,
+			wantBody:   ErrRoleNotInRing.Error(),
+		},
+		{
+			name:       "run loop gone",
+			token:      "s3cret",
+			header:     "Bearer s3cret",
+			action:     actionResume,
+			serve:      false,
+			wantStatus: http.StatusServiceUnavailable,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctx, cancel := context.WithTimeout(t.Context(), 100*time.Millisecond)
+			defer cancel()
+
+			app := newControlApp()
+			if tt.serve {
+				go serveOneControl(ctx, app)
+			}
+
+			request := httptest.NewRequestWithContext(
+				ctx,
+				http.MethodPost,
+				"/api/control/"+tt.action,
+				strings.NewReader(tt.form.Encode()),
+			)
+			request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+			if tt.header != "" {
+				request.Header.Set("Authorization", tt.header)
+			}
+
+			if tt.site != "" {
+				request.Header.Set("Sec-Fetch-Site", tt.site)
+			}
+
+			recorder := httptest.NewRecorder()
+			auth := serverAuth{token: tt.token, user: tt.user, password: "team"}
+			uiHandler(app, "", auth).ServeHTTP(recorder, request)
+
+			if recorder.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d: %s", recorder.Code, tt.wantStatus, recorder.Body.String())
+			}
+
+			if !strings.Contains(recorder.Body.String(), tt.wantBody) {
+				t.Errorf("body = %q, want it to contain %q", recorder.Body.String(), tt.wantBody)
+			}
+		})
+	}
+}
+
+func TestApp_reload(t *testing.T) {
+	t.Parallel()
+
+	const (
+		roleA = "arn:aws:iam::123456789012:role/trick-role-a"
+		roleB = "arn:aws:iam::123456789012:role/trick-role-b"
+		roleC = "arn:aws:iam::123456789012:role/trick-role-c"
+		roleD = "arn:aws:iam::123456789012:role/trick-role-d"
+	)
+
+	loaded := func(roles, useRoles []string) func() (*settings, error) {
+		return func() (*settings, error) {
+			resolved := &settings{} //nolint:exhaustruct
+			resolved.roles.set(roles, sourceConfig)
+			resolved.useRoles.set(useRoles, sourceConfig)
+			resolved.refresh.set(2, sourceConfig)
+
+			return resolved, nil
+		}
+	}
+
+	tests := []struct {
+		name         string
+		loadSettings func() (*settings, error)
+		wantErr      error
+		wantMembers  int
+		wantPosition int
+		wantPinned   string
+		wantSkips    []string
+	}{
+		{name: "unavailable", wantErr: ErrReloadUnavailable, wantMembers: 3, wantPinned: roleA, wantSkips: []string{roleC}},
+		{
+			name:         "current role kept",
+			loadSettings: loaded([]string{roleA, roleB, roleC, roleD}, []string{roleA, roleD}),
+			wantMembers:  4,
+			wantPosition: 2,
+			wantPinned:   roleA,
+			wantSkips:    []string{roleC},
+		},
+		{
+			name:         "pinned and skipped roles removed",
+			loadSettings: loaded([]string{roleB, roleD}, []string{roleD}),
+			wantMembers:  2,
+			wantPosition: 1,
+		},
+		{
+			name:         "pinned role no longer usable",
+			loadSettings: loaded([]string{roleA, roleB, roleC}, []string{roleB}),
+			wantMembers:  3,
+			wantPosition: 2,
+			wantSkips:    []string{roleC},
+		},
+		{
+			name:         "every usable role skipped",
+			loadSettings: loaded([]string{roleB, roleC}, []string{roleC}),
+			wantErr:      ErrNoUsableRoleLeft,
+			wantMembers:  3,
+			wantPinned:   roleA,
+			wantSkips:    []string{roleC},
+		},
+		{
+			name:         "usable role outside the ring",
+			loadSettings: loaded([]string{roleA, roleB}, []string{roleD}),
+			wantErr:      ErrUsableRoleNotInRoleList,
+			wantMembers:  3,
+			wantPinned:   roleA,
+			wantSkips:    []string{roleC},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			app := newControlApp(roleA)
+			app.current = roleB
+			app.pinned = roleA
+			app.skipped = map[string]time.Time{roleC: time.Now().Add(time.Hour)}
+			app.loadSettings = tt.loadSettings
+
+			ticker := time.NewTicker(time.Hour)
+			defer ticker.Stop()
+
+			err := app.reload(ticker)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("reload() error = %v, want %v", err, tt.wantErr)
+			}
+
+			if len(app.members) != tt.wantMembers {
+				t.Errorf("members = %v, want %d roles", app.members, tt.wantMembers)
+			}
+
+			if tt.wantErr == nil && app.position != tt.wantPosition {
+				t.Errorf("position = %d, want %d", app.position, tt.wantPosition)
+			}
+
+			if app.pinned != tt.wantPinned {
+				t.Errorf("pinned = %q, want %q", app.pinned, tt.wantPinned)
+			}
+
+			if len(app.skipped) != len(tt.wantSkips) {
+				t.Errorf("skipped = %v, want %v", app.skipped, tt.wantSkips)
+			}
+
+			for _, role := range tt.wantSkips {
+				if _, found := app.skipped[role]; !found {
+					t.Errorf("skipped = %v, want it to contain %q", app.skipped, role)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,66 @@
+// Copyright 2025 variHQ OÜ
+// SPDX-License-Identifier: BSD-3-Clause
+
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+)
+
+// credentialsCommand reports where the output profile lives, prints the variable that selects it, or deletes it.
+func credentialsCommand(_ context.Context, args []string, stdout io.Writer) error {
+	flags := flag.NewFlagSet("credentials", flag.ContinueOnError)
+	env := flags.Bool("env", false, "print a shell export selecting the output profile")
+	wipe := flags.Bool("wipe", false, "delete the output profile from the shared credentials and config files")
+	flags.Usage = func() {
+		_, _ = fmt.Fprintln(flags.Output(), "Usage: trick credentials [-env | -wipe]")
+		_, _ = fmt.Fprintf(flags.Output(), "\nInspect the %s profile trick keeps fresh.\n\nFlags:\n", defaultProfileName)
+		flags.PrintDefaults()
+	}
+
+	ok, err := parseFlags(flags, args)
+	if !ok {
+		return err
+	}
+
+	if *env && *wipe {
+		return fmt.Errorf("%w: -env and -wipe are mutually exclusive", ErrUsage)
+	}
+
+	if *env {
+		_, err = fmt.Fprintf(stdout, "export AWS_PROFILE=%s\n", defaultProfileName)
+		if err != nil {
+			return fmt.Errorf("failed to write export: %w", err)
+		}
+
+		return nil
+	}
+
+	if *wipe {
+		return NewProfileWriter(nil).wipeAWSProfile()
+	}
+
+	artifacts, err := outputProfileArtifacts(defaultProfileName)
+	if err != nil {
+		return err
+	}
+
+	for _, artifact := range artifacts {
+		present, err := artifactExists(artifact)
+		if err != nil {
+			return err
+		}
+
+		status := "absent"
+		if present {
+			status = "present"
+		}
+
+		_, _ = fmt.Fprintf(stdout, "%s %s\n", status, describeArtifact(artifact))
+	}
+
+	return nil
+}
@@ -0,0 +1,62 @@
+// Copyright 2025 variHQ OÜ
+// SPDX-License-Identifier: BSD-3-Clause
+
+package main
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+//nolint:paralleltest // t.Setenv points the AWS shared files at a temporary directory.
+func TestCredentialsCommand(t *testing.T) {
+	dir := t.TempDir()
+	credentialsFile := filepath.Join(dir, "credentials")
+	configFile := filepath.Join(dir, "config")
+
+	t.Setenv("AWS_SHARED_CREDENTIALS_FILE", credentialsFile)
+	t.Setenv("AWS_CONFIG_FILE", configFile)
+
+	err := os.WriteFile(credentialsFile, []byte("[trick-jump-credentials]\naws_access_key_id = x\n"), 0o600)
+	if err != nil {
+		t.Fatalf("failed to write credentials: %v", err)
+	}
+
+	var out bytes.Buffer
+
+	err = credentialsCommand(t.Context(), nil, &out)
+	if err != nil {
+		t.Fatalf("credentials error = %v", err)
+	}
+
+	want := "present profile [trick-jump-credentials] in " + credentialsFile + "\n" +
+		"absent profile [profile trick-jump-credentials] in " + configFile + "\n"
+	if out.String() != want {
+		t.Errorf("credentials output = %q, want %q", out.String(), want)
+	}
+
+	out.Reset()
+
+	err = credentialsCommand(t.Context(), []string{"-env"}, &out)
+	if err != nil || out.String() != "export AWS_PROFILE=trick-jump-credentials\n" {
+		t.Errorf("credentials -env = %q, %v", out.String(), err)
+	}
+
+	err = credentialsCommand(t.Context(), []string{"-wipe"}, &out)
+	if err != nil {
+		t.Fatalf("credentials -wipe error = %v", err)
+	}
+
+	content, err := os.ReadFile(credentialsFile)
+	if err != nil || len(content) != 0 {
+		t.Errorf("credentials file after -wipe = %q, %v", content, err)
+	}
+
+	err = credentialsCommand(t.Context(), []string{"-env", "-wipe"}, &out)
+	if !errors.Is(err, ErrUsage) {
+		t.Errorf("credentials error = %v, want %v", err, ErrUsage)
+	}
+}
@@ -0,0 +1,213 @@
+// Copyright 2025 variHQ OÜ
+// SPDX-License-Identifier: BSD-3-Clause
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sts/types"
+)
+
+func TestCtlCommand(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		args     []string
+		serve    bool
+		wantErr  error
+		wantBody string
+	}{
+		{name: "pause", args: []string{"-token", "s3cret", "pause"}, serve: true, wantBody: `"state": "paused"`},
+		{
+			name:     "skip",
+			args:     []string{"-token", "s3cret", "-for", "5m", "skip", "arn:aws:iam::123456789012:role/trick-role-a"},
+			serve:    true,
+			wantBody: `"arn:aws:iam::123456789012:role/trick-role-a"`,
+		},
+		{name: "wrong token", args: []string{"-token", "nope", "pause"}, wantErr: ErrControlFailed},
+		{name: "unknown action", args: []string{"reboot"}, wantErr: ErrUnknownControlAction},
+		{name: "missing role", args: []string{"pin"}, wantErr: ErrUsage},
+		{name: "unexpected role", args: []string{"jump", "arn:aws:iam::123456789012:role/trick-role-a"}, wantErr: ErrUsage},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctx, cancel := context.WithTimeout(t.Context(), time.Second)
+			defer cancel()
+
+			app := newControlApp()
+			if tt.serve {
+				go serveOneControl(ctx, app)
+			}
+
+			server := httptest.NewServer(uiHandler(app, "", serverAuth{token: "s3cret"})) //nolint:exhaustruct
+			defer server.Close()
+
+			args := append([]string{"-addr", strings.TrimPrefix(server.URL, "http://")}, tt.args...)
+
+			var stdout bytes.Buffer
+
+			err := ctlCommand(ctx, args, &stdout)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("ctlCommand() error = %v, want %v", err, tt.wantErr)
+			}
+
+			if !strings.Contains(stdout.String(), tt.wantBody) {
+				t.Errorf("output = %q, want it to contain %q", stdout.String(), tt.wantBody)
+			}
+		})
+	}
+}
+
+func TestCtlCommand_fingerprint(t *testing.T) {
+	t.Parallel()
+
+	config := serverConfig{listen: "127.0.0.1:0", selfSigned: true} //nolint:exhaustruct
+
+	listener, err := config.listener(t.Context())
+	if err != nil {
+		t.Fatalf("listener() error = %v", err)
+	}
+
+	server := &http.Server{ //nolint:exhaustruct
+		Handler:           uiHandler(newControlApp(), "", serverAuth{token: "s3cret"}), //nolint:exhaustruct
+		ReadHeaderTimeout: time.Second,
+		ErrorLog:          log.New(io.Discard, "", 0),
+	}
+
+	go func() { _ = server.Serve(listener) }()
+
+	t.Cleanup(func() { _ = server.Close() })
+
+	conn, err := tls.Dial("tcp", listener.Addr().String(), &tls.Config{InsecureSkipVerify: true}) //nolint:exhaustruct,gosec
+	if err != nil {
+		t.Fatalf("Dial() error = %v", err)
+	}
+
+	fingerprint := certificateFingerprint(conn.ConnectionState().PeerCertificates[0].Raw)
+	_ = conn.Close()
+
+	// The colon-separated, upper-case form some tools print.
+	var colons []string
+	for idx := 0; idx < len(fingerprint); idx += 2 {
+		colons = append(colons, strings.ToUpper(fingerprint[idx:idx+2]))
+	}
+
+	addr := "https://" + listener.Addr().String()
+
+	tests := []struct {
+		name    string
+		args    []string
+		wantErr error
+	}{
+		{name: "pinned", args: []string{"-addr", addr, "-fingerprint", fingerprint}},
+		{name: "pinned with colons", args: []string{"-addr", addr, "-fingerprint", strings.Join(colons, ":")}},
+		{name: "unpinned", args: []string{"-addr", addr}, wantErr: ErrControlFailed},
+		{name: "other certificate", args: []string{"-addr", addr, "-fingerprint", "00"}, wantErr: ErrFingerprintMismatch},
+		{
+			name:    "plain http",
+			args:    []string{"-addr", listener.Addr().String(), "-fingerprint", fingerprint},
+			wantErr: ErrUsage,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var stdout bytes.Buffer
+
+			err := ctlCommand(t.Context(), append(tt.args, "-token", "s3cret", "status"), &stdout)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("ctlCommand() error = %v, want %v", err, tt.wantErr)
+			}
+
+			if tt.wantErr == nil && !strings.Contains(stdout.String(), `"state"`) {
+				t.Errorf("output = %q, want the status", stdout.String())
+			}
+		})
+	}
+}
+
+func TestCtlCommand_socket(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		args     []string
+		written  bool
+		serve    bool
+		wantErr  error
+		wantBody string
+	}{
+		{name: "status", args: []string{"status"}, wantBody: `"state": "running"`},
+		{name: "pause without token", args: []string{"pause"}, serve: true, wantBody: `"paused": true`},
+		{
+			name:     "credentials",
+			args:     []string{"credentials"},
+			written:  true,
+			wantBody: "export AWS_SESSION_TOKEN=token\n",
+		},
+		{name: "credentials not written yet", args: []string{"credentials"}, wantErr: ErrControlFailed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctx, cancel := context.WithTimeout(t.Context(), time.Second)
+			defer cancel()
+
+			app := newControlApp()
+			app.region = "eu-west-1"
+
+			if tt.written {
+				app.credentials = &types.Credentials{ //nolint:exhaustruct
+					AccessKeyId:     aws.String("AKIA"),
+					SecretAccessKey: aws.String("secret"),
+					SessionToken:    aws.String("token"),
+				}
+			}
+
+			if tt.serve {
+				go serveOneControl(ctx, app)
+			}
+
+			path := filepath.Join(shortTempDir(t), "trick.sock")
+
+			listener, err := listenSocket(ctx, path)
+			if err != nil {
+				t.Fatalf("listenSocket() error = %v", err)
+			}
+
+			go serveControlSocket(ctx, app, listener)
+
+			var stdout bytes.Buffer
+
+			err = ctlCommand(ctx, append([]string{"-socket", path}, tt.args...), &stdout)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("ctlCommand() error = %v, want %v", err, tt.wantErr)
+			}
+
+			if !strings.Contains(stdout.String(), tt.wantBody) {
+				t.Errorf("output = %q, want it to contain %q", stdout.String(), tt.wantBody)
+			}
+		})
+	}
+}
@@ -0,0 +1,120 @@
+// Copyright 2025 variHQ OÜ
+// SPDX-License-Identifier: BSD-3-Clause
+
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"github.com/wakeful/trick/internal/detect"
+)
+
+// detectionsCommand emits Sigma, SQL and EventBridge content matching the AssumeRole sequence of a chain.
+//
+//nolint:cyclop,funlen
+func detectionsCommand(_ context.Context, args []string, stdout io.Writer) error {
+	flags := flag.NewFlagSet("detections", flag.ContinueOnError)
+	config := flags.String("config", "", "path to config file")
+	profileName := flags.String("profile", "", "profile to describe (default select_profile)")
+	format := flags.String(
+		"format",
+		"sigma",
+		"output format: sigma, cloudtrail-lake, athena or eventbridge",
+	)
+	outputDir := flags.String("output-dir", "", "write every format into this directory instead of stdout")
+	eventDataStore := flags.String(
+		"event-data-store",
+		"<event-data-store-id>",
+		"CloudTrail Lake event data store ID used in the FROM clause",
+	)
+	athenaTable := flags.String("athena-table", "cloudtrail_logs", "Athena table holding CloudTrail logs")
+
+	ok, err := parseFlags(flags, args)
+	if !ok {
+		return err
+	}
+
+	profile, err := loadProfile(*config, *profileName)
+	if err != nil {
+		return err
+	}
+
+	chain := detect.Chain{
+		Name:        profile.Name,
+		Roles:       make([]string, 0, len(profile.Chain.UseRoles)),
+		SessionName: sessionName,
+		TTL:         profile.Chain.TTL,
+	}
+
+	for _, role := range profile.Chain.UseRoles {
+		chain.Roles = append(chain.Roles, role.ARN)
+	}
+
+	generators := map[string]func() (string, error){
+		"sigma":           chain.Sigma,
+		"cloudtrail-lake": func() (string, error) { return chain.CloudTrailLake(*eventDataStore) },
+		"athena":          func() (string, error) { return chain.Athena(*athenaTable) },
+		"eventbridge":     chain.EventBridge,
+	}
+
+	if *outputDir == "" {
+		generate, found := generators[*format]
+		if !found {
+			return fmt.Errorf("%w: %q", ErrUnknownFormat, *format)
+		}
+
+		content, err := generate()
+		if err != nil {
+			return fmt.Errorf("failed to generate %s: %w", *format, err)
+		}
+
+		_, err = io.WriteString(stdout, content)
+		if err != nil {
+			return fmt.Errorf("failed to write %s: %w", *format, err)
+		}
+
+		return nil
+	}
+
+	files := map[string]string{
+		"sigma":           "trick-" + profile.Name + ".sigma.yml",
+		"cloudtrail-lake": "trick-" + profile.Name + ".cloudtrail-lake.sql",
+		"athena":          "trick-" + profile.Name + ".athena.sql",
+		"eventbridge":     "trick-" + profile.Name + ".eventbridge.json",
+	}
+
+	const (
+		dirMode  = 0o750
+		fileMode = 0o600
+	)
+
+	err = os.MkdirAll(*outputDir, dirMode)
+	if err != nil {
+		return fmt.Errorf("failed to create output directory: %w", err)
+	}
+
+	for _, name := range slices.Sorted(maps.Keys(generators)) {
+		content, err := generators[name]()
+		if err != nil {
+			return fmt.Errorf("failed to generate %s: %w", name, err)
+		}
+
+		path := filepath.Join(*outputDir, files[name])
+
+		err = os.WriteFile(path, []byte(content), fileMode)
+		if err != nil {
+			return fmt.Errorf("failed to write %s: %w", name, err)
+		}
+
+		_, _ = fmt.Fprintln(stdout, path)
+	}
+
+	return nil
+}
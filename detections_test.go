@@ -0,0 +1,117 @@
+// Copyright 2025 variHQ OÜ
+// SPDX-License-Identifier: BSD-3-Clause
+
+package main
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const ringConfig = `select_profile = profile.ring
+
+profile "ring" {
+  chain {
+    ttl = 12
+
+    use {
+      arn = "arn:aws:iam::123456789012:role/trick-role-a"
+    }
+
+    use {
+      arn  = "arn:aws:iam::123456789012:role/trick-role-b"
+      skip = true
+    }
+
+    use {
+      arn = "arn:aws:iam::123456789012:role/trick-role-c"
+    }
+  }
+}
+`
+
+func writeConfig(t *testing.T, content string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "config.hcl")
+
+	err := os.WriteFile(path, []byte(content), 0o600)
+	if err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+
+	return path
+}
+
+func TestDetectionsCommand(t *testing.T) {
+	t.Parallel()
+
+	config := writeConfig(t, ringConfig)
+
+	tests := []struct {
+		name         string
+		args         []string
+		wantErr      error
+		wantContains string
+	}{
+		{
+			name:    "missing config",
+			args:    []string{},
+			wantErr: ErrMissingConfig,
+		},
+		{
+			name:    "unknown format",
+			args:    []string{"-config", config, "-format", "splunk"},
+			wantErr: ErrUnknownFormat,
+		},
+		{
+			name:         "sigma",
+			args:         []string{"-config", config},
+			wantContains: "name: trick_ring_hop_0",
+		},
+		{
+			name:         "eventbridge",
+			args:         []string{"-config", config, "-format", "eventbridge"},
+			wantContains: `"arn:aws:sts::123456789012:assumed-role/trick-role-c/trick"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var stdout bytes.Buffer
+
+			err := detectionsCommand(t.Context(), tt.args, &stdout)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("detections error = %v, want %v", err, tt.wantErr)
+			}
+
+			if !strings.Contains(stdout.String(), tt.wantContains) {
+				t.Errorf("detections output missing %q:\n%s", tt.wantContains, stdout.String())
+			}
+		})
+	}
+}
+
+func TestDetectionsCommand_OutputDir(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+
+	var stdout bytes.Buffer
+
+	err := detectionsCommand(t.Context(), []string{"-config", writeConfig(t, ringConfig), "-output-dir", dir}, &stdout)
+	if err != nil {
+		t.Fatalf("detections error = %v", err)
+	}
+
+	entries, _ := os.ReadDir(dir)
+	if len(entries) != 4 {
+		t.Errorf("detections wrote %d files, want 4", len(entries))
+	}
+}
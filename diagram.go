@@ -0,0 +1,58 @@
+// Copyright 2025 variHQ OÜ
+// SPDX-License-Identifier: BSD-3-Clause
+
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+
+	"github.com/wakeful/trick/internal/ui"
+)
+
+// diagramCommand prints the Mermaid state diagram the -ui flag serves, for the ring a run with the same flags would use.
+func diagramCommand(_ context.Context, args []string, stdout io.Writer) error {
+	flags := flag.NewFlagSet("diagram", flag.ContinueOnError)
+	declareRunFlags(flags)
+	flags.Usage = func() {
+		_, _ = fmt.Fprintln(flags.Output(), "Usage: trick diagram [trick run flags]")
+		_, _ = fmt.Fprintln(flags.Output(), "\nPrint the ring as a Mermaid state diagram.\n\nFlags:")
+		flags.PrintDefaults()
+	}
+
+	ok, err := parseFlags(flags, args)
+	if !ok {
+		return err
+	}
+
+	resolved, err := resolveSettings(flags, os.Getenv)
+	if err != nil {
+		return err
+	}
+
+	roles := resolved.roles.value
+	if len(roles) < 2 { //nolint:mnd
+		return ErrMinRoles
+	}
+
+	usable := make(map[string]struct{})
+
+	for _, role := range resolved.useRoles.value {
+		if !slices.Contains(roles, role) {
+			return fmt.Errorf("%w: %s", ErrUsableRoleNotInRoleList, role)
+		}
+
+		usable[role] = struct{}{}
+	}
+
+	_, err = io.WriteString(stdout, ui.Diagram(roles, usable, resolved.refresh.value))
+	if err != nil {
+		return fmt.Errorf("failed to write diagram: %w", err)
+	}
+
+	return nil
+}
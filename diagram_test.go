@@ -0,0 +1,40 @@
+// Copyright 2025 variHQ OÜ
+// SPDX-License-Identifier: BSD-3-Clause
+
+package main
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestDiagramCommand(t *testing.T) {
+	t.Parallel()
+
+	config := writeConfig(t, ringConfig)
+
+	var out bytes.Buffer
+
+	err := diagramCommand(t.Context(), []string{"-config", config, "-refresh", "5"}, &out)
+	if err != nil {
+		t.Fatalf("diagram error = %v", err)
+	}
+
+	for _, want := range []string{
+		"stateDiagram\n",
+		"    r0: trick-role-a\n",
+		"    r1 --> r2: wait 5min and jump\n",
+		"    r2 --> r0: lacks permission so we jump to trick-role-a\n",
+	} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("diagram output missing %q:\n%s", want, out.String())
+		}
+	}
+
+	err = diagramCommand(t.Context(), []string{"-role", "arn::42::role-a", "-role", "arn::42::role-b", "-use", "x"}, &out)
+	if !errors.Is(err, ErrUsableRoleNotInRoleList) {
+		t.Errorf("diagram error = %v, want %v", err, ErrUsableRoleNotInRoleList)
+	}
+}
@@ -0,0 +1,168 @@
+// Copyright 2025 variHQ OÜ
+// SPDX-License-Identifier: BSD-3-Clause
+
+package main
+
+import (
+	"errors"
+	"log/slog"
+)
+
+// ErrorClass names the kind of failure behind an exit code. It is logged as "class" and recorded in the audit log.
+type ErrorClass string
+
+const (
+	// ClassUsage is a command called with flags or arguments it does not accept.
+	ClassUsage ErrorClass = "usage"
+	// ClassConfig is an invalid config file, setting or chain.
+	ClassConfig ErrorClass = "config"
+	// ClassBootstrap is a failure to load AWS credentials or to prepare local state before the first hop.
+	ClassBootstrap ErrorClass = "bootstrap"
+	// ClassHopDenied is an AssumeRole call the target role's policies refused.
+	ClassHopDenied ErrorClass = "hop-denied"
+	// ClassThrottled is an AssumeRole call STS rejected for exceeding its rate limits.
+	ClassThrottled ErrorClass = "throttled"
+	// ClassWriteFailure is a failure to write fresh credentials to the output profile.
+	ClassWriteFailure ErrorClass = "write-failure"
+	// ClassGuardrail is a guardrail that stopped the chain.
+	ClassGuardrail ErrorClass = "guardrail"
+)
+
+// Exit codes of the trick binary; every ErrorClass has its own.
+const (
+	exitFailure      = 1
+	exitUsage        = 2
+	exitConfig       = 3
+	exitBootstrap    = 4
+	exitHopDenied    = 5
+	exitThrottled    = 6
+	exitWriteFailure = 7
+	exitGuardrail    = 8
+)
+
+// ClassError attaches an ErrorClass to the error it wraps.
+type ClassError struct {
+	Class ErrorClass
+	Err   error
+}
+
+func (e *ClassError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *ClassError) Unwrap() error {
+	return e.Err
+}
+
+// withClass wraps err in a ClassError unless it is nil, class is empty or err is already classified.
+func withClass(class ErrorClass, err error) error {
+	if err == nil || class == "" || errorClass(err) != "" {
+		return err
+	}
+
+	return &ClassError{Class: class, Err: err}
+}
+
+// errorClass returns the class attached to err, the class implied by a well-known sentinel, or "" when unknown.
+func errorClass(err error) ErrorClass {
+	var classErr *ClassError
+	if errors.As(err, &classErr) {
+		return classErr.Class
+	}
+
+	sentinels := []struct {
+		class ErrorClass
+		errs  []error
+	}{
+		{ClassGuardrail, []error{ErrGuardrailTripped}},
+		{ClassUsage, []error{
+			ErrUsage,
+			ErrUnknownCommand,
+			ErrUnknownTarget,
+			ErrUnknownSource,
+			ErrUnknownDestination,
+			ErrUnknownConfigAction,
+			ErrUnknownShell,
+			ErrUnknownControlAction,
+			ErrUnknownFormat,
+		}},
+		{ClassConfig, []error{
+			ErrMissingConfig,
+			ErrMissingChain,
+			ErrInvalidConfig,
+			ErrInvalidSetting,
+			ErrMinRoles,
+			ErrUsableRoleNotInRoleList,
+			ErrUnsafeListen,
+			ErrInvalidTLS,
+			ErrNotSocket,
+			ErrUnsafeSocketDir,
+		}},
+	}
+
+	for _, sentinel := range sentinels {
+		for _, target := range sentinel.errs {
+			if errors.Is(err, target) {
+				return sentinel.class
+			}
+		}
+	}
+
+	return ""
+}
+
+// exitCode maps err to the process exit code of its class, or 1 when it has none.
+func exitCode(err error) int {
+	codes := map[ErrorClass]int{
+		ClassUsage:        exitUsage,
+		ClassConfig:       exitConfig,
+		ClassBootstrap:    exitBootstrap,
+		ClassHopDenied:    exitHopDenied,
+		ClassThrottled:    exitThrottled,
+		ClassWriteFailure: exitWriteFailure,
+		ClassGuardrail:    exitGuardrail,
+	}
+
+	code, found := codes[errorClass(err)]
+	if !found {
+		return exitFailure
+	}
+
+	return code
+}
+
+// errorAttrs returns the slog attributes describing err, including its class when known.
+func errorAttrs(err error) []any {
+	attrs := []any{slog.String("error", err.Error())}
+
+	if class := errorClass(err); class != "" {
+		attrs = append(attrs, slog.String("class", string(class)))
+	}
+
+	return attrs
+}
+
+// apiError is implemented by the operation errors of the AWS SDK.
+type apiError interface {
+	ErrorCode() string
+}
+
+// assumeErrorClass classifies a failed AssumeRole call by the error code STS returned.
+func assumeErrorClass(err error) ErrorClass {
+	var apiErr apiError
+	if !errors.As(err, &apiErr) {
+		return ""
+	}
+
+	switch apiErr.ErrorCode() {
+	case "AccessDenied", "AccessDeniedException":
+		return ClassHopDenied
+	case "Throttling", "ThrottlingException", "RequestLimitExceeded", "TooManyRequestsException":
+		return ClassThrottled
+	case "ExpiredToken", "ExpiredTokenException", "InvalidClientTokenId", "SignatureDoesNotMatch",
+		"UnrecognizedClientException", "RegionDisabledException":
+		return ClassBootstrap
+	default:
+		return ""
+	}
+}
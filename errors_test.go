@@ -0,0 +1,103 @@
+// Copyright 2025 variHQ OÜ
+// SPDX-License-Identifier: BSD-3-Clause
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"testing"
+)
+
+// stsError mimics the operation errors the AWS SDK returns for a failed API call.
+type stsError struct{ code string }
+
+func (e stsError) Error() string     { return "api error " + e.code }
+func (e stsError) ErrorCode() string { return e.code }
+
+func TestExitCode(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		err       error
+		wantClass ErrorClass
+		wantCode  int
+	}{
+		{"unclassified", errors.New("boom"), "", exitFailure},
+		{"usage", fmt.Errorf("%w for run: bad flag", ErrUsage), ClassUsage, exitUsage},
+		{"usage sentinel", fmt.Errorf("%w: %q", ErrUnknownFormat, "pdf"), ClassUsage, exitUsage},
+		{"config sentinel", ErrMinRoles, ClassConfig, exitConfig},
+		{"config class", withClass(ClassConfig, errors.New("bad hcl")), ClassConfig, exitConfig},
+		{"bootstrap", withClass(ClassBootstrap, errors.New("no credentials")), ClassBootstrap, exitBootstrap},
+		{
+			"hop denied",
+			withClass(assumeErrorClass(stsError{"AccessDenied"}), stsError{"AccessDenied"}),
+			ClassHopDenied,
+			exitHopDenied,
+		},
+		{
+			"throttled",
+			withClass(assumeErrorClass(stsError{"Throttling"}), fmt.Errorf("wrapped: %w", stsError{"Throttling"})),
+			ClassThrottled,
+			exitThrottled,
+		},
+		{"write failure", withClass(ClassWriteFailure, errors.New("aws cli")), ClassWriteFailure, exitWriteFailure},
+		{"guardrail", fmt.Errorf("tick: %w", ErrKillDateReached), ClassGuardrail, exitGuardrail},
+		{
+			"existing class is kept",
+			withClass(ClassBootstrap, fmt.Errorf("init: %w", ErrAccountNotAllowed)),
+			ClassGuardrail,
+			exitGuardrail,
+		},
+		{"unknown api error", withClass(assumeErrorClass(stsError{"Other"}), stsError{"Other"}), "", exitFailure},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			if got := errorClass(tt.err); got != tt.wantClass {
+				t.Errorf("errorClass() = %q, want %q", got, tt.wantClass)
+			}
+
+			if got := exitCode(tt.err); got != tt.wantCode {
+				t.Errorf("exitCode() = %d, want %d", got, tt.wantCode)
+			}
+		})
+	}
+}
+
+func TestExitCode_missingInput(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		args []string
+	}{
+		{"fmt from-flags", []string{"fmt", "from-flags"}},
+		{"hunt", []string{"hunt"}},
+		{"lint-policies", []string{"lint-policies"}},
+		{"report", []string{"report"}},
+		{"status", []string{"status", "-audit", ""}},
+		{"validate", []string{"validate"}},
+		{"import", []string{"import"}},
+		{"gen", []string{"gen"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			found, err := dispatch(t.Context(), tt.args, io.Discard)
+			if !found || err == nil {
+				t.Fatalf("dispatch(%v) = %v, %v, want a usage error", tt.args, found, err)
+			}
+
+			if got := exitCode(err); got != exitUsage {
+				t.Errorf("exitCode(%v) = %d, want %d", err, got, exitUsage)
+			}
+		})
+	}
+}
@@ -0,0 +1,39 @@
+// Copyright 2025 variHQ OÜ
+// SPDX-License-Identifier: BSD-3-Clause
+
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"strings"
+)
+
+// ErrUnknownDestination indicates that trick export was asked for an unsupported output.
+var ErrUnknownDestination = errors.New("unknown export destination")
+
+func exporters() map[string]func(ctx context.Context, args []string, stdout io.Writer) error {
+	return map[string]func(ctx context.Context, args []string, stdout io.Writer) error{
+		"aws-config": exportAWSConfigCommand,
+	}
+}
+
+// exportCommand writes a trick profile in another tool's configuration format, e.g. `trick export aws-config`.
+func exportCommand(ctx context.Context, args []string, stdout io.Writer) error {
+	destinations := strings.Join(slices.Sorted(maps.Keys(exporters())), ", ")
+
+	if len(args) == 0 || strings.HasPrefix(args[0], "-") {
+		return fmt.Errorf("%w: expected one of %s", ErrUnknownDestination, destinations)
+	}
+
+	run, found := exporters()[args[0]]
+	if !found {
+		return fmt.Errorf("%w: %q, expected one of %s", ErrUnknownDestination, args[0], destinations)
+	}
+
+	return run(ctx, args[1:], stdout)
+}
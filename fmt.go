@@ -0,0 +1,177 @@
+// Copyright 2025 variHQ OÜ
+// SPDX-License-Identifier: BSD-3-Clause
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/wakeful/trick/internal/parser"
+)
+
+var (
+	// ErrNotFormatted indicates that trick fmt -check found files that are not in canonical form.
+	ErrNotFormatted = errors.New("config files are not formatted")
+	// ErrNotHCL indicates that trick fmt was given a JSON or YAML config, which it cannot rewrite.
+	ErrNotHCL = errors.New("only HCL config files can be formatted")
+	// ErrMissingRoles indicates that trick fmt from-flags was called without -role.
+	ErrMissingRoles = errors.New("at least one -role is required")
+)
+
+// fmtCommand rewrites config files in canonical form, or reads stdin and writes stdout when no file is given.
+func fmtCommand(ctx context.Context, args []string, stdout io.Writer) error {
+	if len(args) > 0 && args[0] == "from-flags" {
+		return fmtFromFlagsCommand(ctx, args[1:], stdout)
+	}
+
+	flags := flag.NewFlagSet("fmt", flag.ContinueOnError)
+	check := flags.Bool("check", false, "list files that are not formatted instead of rewriting them")
+	dropDefaults := flags.Bool("drop-defaults", false, "remove attributes set to their default value, e.g. skip = false")
+	flags.Usage = func() {
+		_, _ = fmt.Fprintln(flags.Output(), "Usage: trick fmt [flags] [config.hcl ...]")
+		_, _ = fmt.Fprintln(flags.Output(), "       trick fmt from-flags [flags]")
+		flags.PrintDefaults()
+	}
+
+	ok, err := parseFlags(flags, args)
+	if !ok {
+		return err
+	}
+
+	opts := parser.FormatOptions{DropDefaults: *dropDefaults}
+
+	if flags.NArg() == 0 {
+		return fmtStdin(opts, *check, stdout)
+	}
+
+	unformatted := 0
+
+	for _, path := range flags.Args() {
+		changed, err := fmtFile(path, opts, *check)
+		if err != nil {
+			return err
+		}
+
+		if changed && *check {
+			unformatted++
+
+			_, _ = fmt.Fprintln(stdout, path)
+		}
+	}
+
+	if unformatted > 0 {
+		return fmt.Errorf("%w: %d file(s)", ErrNotFormatted, unformatted)
+	}
+
+	return nil
+}
+
+func fmtStdin(opts parser.FormatOptions, check bool, stdout io.Writer) error {
+	content, err := io.ReadAll(os.Stdin)
+	if err != nil {
+		return fmt.Errorf("failed to read stdin: %w", err)
+	}
+
+	formatted, err := parser.Format(content, "<stdin>", opts)
+	if err != nil {
+		return err
+	}
+
+	if check {
+		if !bytes.Equal(content, formatted) {
+			return fmt.Errorf("%w: <stdin>", ErrNotFormatted)
+		}
+
+		return nil
+	}
+
+	_, err = stdout.Write(formatted)
+	if err != nil {
+		return fmt.Errorf("failed to write config: %w", err)
+	}
+
+	return nil
+}
+
+// fmtFile formats path in place unless check is set, and reports whether its content differs from canonical form.
+func fmtFile(path string, opts parser.FormatOptions, check bool) (bool, error) {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json", ".yaml", ".yml":
+		return false, fmt.Errorf("%w: %s", ErrNotHCL, path)
+	}
+
+	content, err := os.ReadFile(path) //nolint:gosec
+	if err != nil {
+		return false, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	formatted, err := parser.Format(content, path, opts)
+	if err != nil {
+		return false, fmt.Errorf("%s: %w", path, err)
+	}
+
+	if bytes.Equal(content, formatted) {
+		return false, nil
+	}
+
+	if check {
+		return true, nil
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return false, fmt.Errorf("failed to stat %s: %w", path, err)
+	}
+
+	err = os.WriteFile(path, formatted, info.Mode().Perm())
+	if err != nil {
+		return false, fmt.Errorf("failed to write %s: %w", path, err)
+	}
+
+	return true, nil
+}
+
+// fmtFromFlagsCommand turns the -role and -use flags of a plain trick run into a canonical config file.
+func fmtFromFlagsCommand(_ context.Context, args []string, stdout io.Writer) error {
+	flags := flag.NewFlagSet("fmt from-flags", flag.ContinueOnError)
+	profileName := flags.String("profile", "cli", "name of the generated profile")
+	refresh := flags.Int64("refresh", defaultRefreshTime, "refresh IAM every n minutes")
+	region := flags.String("region", "eu-west-1", "AWS region used for IAM communication")
+	output := flags.String("output", "", "write the profile to this file instead of stdout")
+
+	var (
+		roleVars    StringSlice
+		useRoleVars StringSlice
+	)
+
+	flags.Var(&roleVars, "role", "AWS role ARN to assume (can be specified multiple times)")
+	flags.Var(&useRoleVars, "use", "AWS role ARN with meaningful permissions (must exist in -role list)")
+
+	ok, err := parseFlags(flags, args)
+	if !ok {
+		return err
+	}
+
+	if len(roleVars) == 0 {
+		return fmt.Errorf("%w: %w", ErrUsage, ErrMissingRoles)
+	}
+
+	for _, role := range useRoleVars {
+		if !slices.Contains(roleVars, role) {
+			return fmt.Errorf("%w: %w: %s", ErrUsage, ErrUsableRoleNotInRoleList, role)
+		}
+	}
+
+	config := parser.FromFlags(*profileName, *region, *refresh, roleVars, useRoleVars)
+
+	return writeProfile(config, *output, stdout)
+}
@@ -0,0 +1,102 @@
+// Copyright 2025 variHQ OÜ
+// SPDX-License-Identifier: BSD-3-Clause
+
+package main
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestFmtCommand(t *testing.T) {
+	t.Parallel()
+
+	path := writeConfig(t, "profile \"lab\" {\nchain {\nuse {\nskip = false\narn = \"arn::42::role-a\"\n}\n}\n}\n")
+
+	var out bytes.Buffer
+
+	err := fmtCommand(t.Context(), []string{"-check", path}, &out)
+	if !errors.Is(err, ErrNotFormatted) {
+		t.Fatalf("fmt -check error = %v, want %v", err, ErrNotFormatted)
+	}
+
+	if out.String() != path+"\n" {
+		t.Errorf("fmt -check output = %q, want %q", out.String(), path+"\n")
+	}
+
+	err = fmtCommand(t.Context(), []string{"-drop-defaults", path}, &out)
+	if err != nil {
+		t.Fatalf("fmt error = %v", err)
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read config: %v", err)
+	}
+
+	want := "profile \"lab\" {\n  chain {\n    use {\n      arn = \"arn::42::role-a\"\n    }\n  }\n}\n"
+	if string(content) != want {
+		t.Errorf("fmt rewrote config to\n%s\nwant\n%s", content, want)
+	}
+
+	err = fmtCommand(t.Context(), []string{"-check", path}, &out)
+	if err != nil {
+		t.Errorf("fmt -check after fmt error = %v", err)
+	}
+
+	err = fmtCommand(t.Context(), []string{"config.yaml"}, &out)
+	if !errors.Is(err, ErrNotHCL) {
+		t.Errorf("fmt error = %v, want %v", err, ErrNotHCL)
+	}
+}
+
+func TestFmtFromFlags(t *testing.T) {
+	t.Parallel()
+
+	var out bytes.Buffer
+
+	err := fmtCommand(t.Context(), []string{
+		"from-flags", "-profile", "complex", "-refresh", "15",
+		"-role", "arn::42::role-a", "-role", "arn::42::role-b", "-use", "arn::42::role-b",
+	}, &out)
+	if err != nil {
+		t.Fatalf("fmt from-flags error = %v", err)
+	}
+
+	want := `select_profile = profile.complex
+
+profile "complex" {
+  region = "eu-west-1"
+
+  chain {
+    ttl = 15
+
+    use {
+      arn = "arn::42::role-a"
+    }
+
+    use {
+      arn  = "arn::42::role-b"
+      skip = true
+    }
+  }
+}
+`
+	if out.String() != want {
+		t.Errorf("fmt from-flags output =\n%s\nwant\n%s", out.String(), want)
+	}
+
+	err = fmtCommand(t.Context(), []string{"from-flags"}, &out)
+	if !errors.Is(err, ErrMissingRoles) {
+		t.Errorf("fmt from-flags error = %v, want %v", err, ErrMissingRoles)
+	}
+
+	err = fmtCommand(t.Context(), []string{"from-flags", "-role", "arn::42::role-a", "-use", "arn::42::role-c"}, &out)
+	if !errors.Is(err, ErrUsableRoleNotInRoleList) || !errors.Is(err, ErrUsage) ||
+		!strings.Contains(err.Error(), "arn::42::role-c") {
+		t.Errorf("fmt from-flags error = %v, want %v naming the role", err, ErrUsableRoleNotInRoleList)
+	}
+}
@@ -0,0 +1,118 @@
+// Copyright 2025 variHQ OÜ
+// SPDX-License-Identifier: BSD-3-Clause
+
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/wakeful/trick/internal/gen"
+	"github.com/wakeful/trick/internal/parser"
+)
+
+// ErrUnknownTarget indicates that trick gen was asked for an unsupported output.
+var ErrUnknownTarget = errors.New("unknown generator")
+
+// genOptions are the target specific flags of trick gen.
+type genOptions struct {
+	moduleSource string
+}
+
+func generators() map[string]func(chain gen.Chain, opts genOptions) (string, error) {
+	return map[string]func(chain gen.Chain, opts genOptions) (string, error){
+		"terraform": func(chain gen.Chain, opts genOptions) (string, error) {
+			return chain.Terraform(opts.moduleSource)
+		},
+		"cloudformation": func(chain gen.Chain, _ genOptions) (string, error) {
+			return chain.CloudFormation()
+		},
+		"iam-json": func(chain gen.Chain, _ genOptions) (string, error) {
+			return chain.IAMJSON()
+		},
+	}
+}
+
+// genChain converts a profile into the generator's view of the ring.
+func genChain(profile *parser.Profile) gen.Chain {
+	entries := profile.Chain.EntryPoints()
+
+	chain := gen.Chain{
+		Name:   profile.Name,
+		Region: profile.Region,
+		Roles:  make([]gen.Role, 0, len(profile.Chain.UseRoles)),
+	}
+
+	for _, role := range profile.Chain.UseRoles {
+		chain.Roles = append(chain.Roles, gen.Role{
+			ARN:   role.ARN,
+			Entry: slices.Contains(entries, role),
+		})
+	}
+
+	return chain
+}
+
+// genCommand generates infrastructure code for a chain, e.g. `trick gen terraform -config config.hcl`.
+func genCommand(_ context.Context, args []string, stdout io.Writer) error {
+	targets := strings.Join(slices.Sorted(maps.Keys(generators())), ", ")
+
+	if len(args) == 0 || strings.HasPrefix(args[0], "-") {
+		return fmt.Errorf("%w: expected one of %s", ErrUnknownTarget, targets)
+	}
+
+	target := args[0]
+
+	generate, found := generators()[target]
+	if !found {
+		return fmt.Errorf("%w: %q, expected one of %s", ErrUnknownTarget, target, targets)
+	}
+
+	var opts genOptions
+
+	flags := flag.NewFlagSet("gen "+target, flag.ContinueOnError)
+	config := flags.String("config", "", "path to config file")
+	profileName := flags.String("profile", "", "profile to generate (default select_profile)")
+	output := flags.String("output", "", "write to this file instead of stdout")
+	flags.StringVar(&opts.moduleSource, "module-source", gen.ModuleSource, "terraform: source of the role module")
+
+	ok, err := parseFlags(flags, args[1:])
+	if !ok {
+		return err
+	}
+
+	profile, err := loadProfile(*config, *profileName)
+	if err != nil {
+		return err
+	}
+
+	content, err := generate(genChain(profile), opts)
+	if err != nil {
+		return fmt.Errorf("failed to generate %s: %w", target, err)
+	}
+
+	if *output == "" {
+		_, err = io.WriteString(stdout, content)
+		if err != nil {
+			return fmt.Errorf("failed to write %s: %w", target, err)
+		}
+
+		return nil
+	}
+
+	const fileMode = 0o600
+
+	err = os.WriteFile(*output, []byte(content), fileMode)
+	if err != nil {
+		return fmt.Errorf("failed to write %s: %w", target, err)
+	}
+
+	return nil
+}
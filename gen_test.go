@@ -0,0 +1,77 @@
+// Copyright 2025 variHQ OÜ
+// SPDX-License-Identifier: BSD-3-Clause
+
+package main
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestGenCommand(t *testing.T) {
+	t.Parallel()
+
+	config := writeConfig(t, ringConfig)
+
+	tests := []struct {
+		name         string
+		args         []string
+		wantErr      error
+		wantContains string
+	}{
+		{
+			name:    "missing target",
+			args:    []string{"-config", config},
+			wantErr: ErrUnknownTarget,
+		},
+		{
+			name:    "unknown target",
+			args:    []string{"pulumi", "-config", config},
+			wantErr: ErrUnknownTarget,
+		},
+		{
+			name:    "missing config",
+			args:    []string{"terraform"},
+			wantErr: ErrMissingConfig,
+		},
+		{
+			name:         "first role is the default entry point",
+			args:         []string{"terraform", "-config", config},
+			wantContains: "  name        = \"a\"\n  chain_start = true\n\n  assume_from = \"c\"\n  can_assume  = \"b\"\n",
+		},
+		{
+			name:         "cloudformation",
+			args:         []string{"cloudformation", "-config", config},
+			wantContains: `"Type": "AWS::IAM::Role"`,
+		},
+		{
+			name:         "iam-json",
+			args:         []string{"iam-json", "-config", config},
+			wantContains: `"Resource": "arn:aws:iam::123456789012:role/trick-role-a"`,
+		},
+		{
+			name:         "module source",
+			args:         []string{"terraform", "-config", config, "-module-source", "git::https://example.com/role"},
+			wantContains: `source = "git::https://example.com/role"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var stdout bytes.Buffer
+
+			err := genCommand(t.Context(), tt.args, &stdout)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("gen error = %v, want %v", err, tt.wantErr)
+			}
+
+			if !strings.Contains(stdout.String(), tt.wantContains) {
+				t.Errorf("gen output missing %q:\n%s", tt.wantContains, stdout.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,245 @@
+// Copyright 2025 variHQ OÜ
+// SPDX-License-Identifier: BSD-3-Clause
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/wakeful/trick/internal/arn"
+	"github.com/wakeful/trick/internal/audit"
+	"github.com/wakeful/trick/internal/awsconfig"
+	"github.com/wakeful/trick/internal/parser"
+)
+
+var (
+	// ErrGuardrailTripped is the parent of every rules-of-engagement violation.
+	ErrGuardrailTripped = errors.New("guardrail tripped")
+	// ErrKillDateReached indicates that the not_after kill date has passed.
+	ErrKillDateReached = fmt.Errorf("%w: kill date reached", ErrGuardrailTripped)
+	// ErrAssumeBudgetExhausted indicates that max_assumes_per_day has been used up.
+	ErrAssumeBudgetExhausted = fmt.Errorf("%w: daily assume budget exhausted", ErrGuardrailTripped)
+	// ErrAccountNotAllowed indicates that a role lives outside allowed_accounts.
+	ErrAccountNotAllowed = fmt.Errorf("%w: account is not allowed", ErrGuardrailTripped)
+)
+
+// Guardrails enforces the rules-of-engagement limits from the config.
+// A nil *Guardrails allows everything.
+type Guardrails struct {
+	mu sync.Mutex
+	// notAfter is the kill date; the zero time disables it
+	notAfter time.Time
+	// allowedAccounts lists the account IDs roles may live in; empty allows all
+	allowedAccounts []string
+	// maxAssumesPerDay caps AssumeRole calls in any rolling 24 hours; zero disables it
+	maxAssumesPerDay int
+	// onExpire is the action taken once the kill date passes
+	onExpire string
+	// assumes holds the times of AssumeRole calls within the last 24 hours
+	assumes []time.Time
+	now     func() time.Time
+}
+
+// NewGuardrails builds the runtime guardrails from the parsed config block, returning nil when none are set.
+func NewGuardrails(cfg *parser.Guardrails) (*Guardrails, error) {
+	if cfg == nil {
+		return nil, nil //nolint:nilnil
+	}
+
+	deadline, err := cfg.Deadline()
+	if err != nil {
+		return nil, fmt.Errorf("invalid guardrails: %w", err)
+	}
+
+	onExpire := cfg.OnExpire
+	if onExpire == "" {
+		onExpire = parser.OnExpireStop
+	}
+
+	return &Guardrails{
+		mu:               sync.Mutex{},
+		notAfter:         deadline,
+		allowedAccounts:  cfg.AllowedAccounts,
+		maxAssumesPerDay: cfg.MaxAssumesPerDay,
+		onExpire:         onExpire,
+		assumes:          nil,
+		now:              time.Now,
+	}, nil
+}
+
+// accounts returns the allowed account IDs, or nil when every account is allowed.
+func (g *Guardrails) accounts() []string {
+	if g == nil {
+		return nil
+	}
+
+	return g.allowedAccounts
+}
+
+// checkExpiry returns ErrKillDateReached once the kill date has passed.
+func (g *Guardrails) checkExpiry() error {
+	if g == nil || g.notAfter.IsZero() {
+		return nil
+	}
+
+	if g.now().Before(g.notAfter) {
+		return nil
+	}
+
+	return fmt.Errorf("%w: not_after %s", ErrKillDateReached, g.notAfter.Format(time.RFC3339))
+}
+
+// reserveAssume records an AssumeRole call, or returns ErrAssumeBudgetExhausted if it would exceed the budget.
+func (g *Guardrails) reserveAssume() error {
+	if g == nil || g.maxAssumesPerDay == 0 {
+		return nil
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	now := g.now()
+	cutoff := now.Add(-24 * time.Hour)
+
+	kept := g.assumes[:0]
+	for _, at := range g.assumes {
+		if at.After(cutoff) {
+			kept = append(kept, at)
+		}
+	}
+
+	g.assumes = kept
+
+	if len(g.assumes) >= g.maxAssumesPerDay {
+		return fmt.Errorf("%w: %d assumes in the last 24h", ErrAssumeBudgetExhausted, len(g.assumes))
+	}
+
+	g.assumes = append(g.assumes, now)
+
+	return nil
+}
+
+// checkAccounts returns ErrAccountNotAllowed for the first role outside allowed.
+func checkAccounts(roles []string, allowed []string) error {
+	if len(allowed) == 0 {
+		return nil
+	}
+
+	for _, role := range roles {
+		parsed, _ := arn.Parse(role)
+		account := parsed.Account
+
+		found := false
+
+		for _, candidate := range allowed {
+			if strings.TrimSpace(candidate) == account {
+				found = true
+
+				break
+			}
+		}
+
+		if !found {
+			return fmt.Errorf("%w: %s (account %q)", ErrAccountNotAllowed, role, account)
+		}
+	}
+
+	return nil
+}
+
+// guardrailName maps a guardrail error to the config attribute that tripped.
+func guardrailName(err error) string {
+	switch {
+	case errors.Is(err, ErrKillDateReached):
+		return "not_after"
+	case errors.Is(err, ErrAssumeBudgetExhausted):
+		return "max_assumes_per_day"
+	case errors.Is(err, ErrAccountNotAllowed):
+		return "allowed_accounts"
+	default:
+		return ""
+	}
+}
+
+// tripGuardrail audits a tripped guardrail and applies on_expire when the kill date passed.
+// It returns err so callers can stop the run loop.
+func (a *App) tripGuardrail(err error) error {
+	slog.Error("guardrail tripped",
+		slog.String("guardrail", guardrailName(err)),
+		slog.String("class", string(ClassGuardrail)),
+		slog.String("error", err.Error()),
+	)
+
+	a.audit.Record(audit.Entry{ //nolint:exhaustruct
+		Event:     audit.EventGuardrailTripped,
+		Chain:     "main",
+		Role:      a.current,
+		Guardrail: guardrailName(err),
+		Class:     string(ClassGuardrail),
+		Error:     err.Error(),
+	})
+
+	if !errors.Is(err, ErrKillDateReached) || a.guardrails.onExpire != parser.OnExpireStopAndWipe {
+		return err
+	}
+
+	errWipe := a.profileWriter.wipeAWSProfile()
+	if errWipe != nil {
+		a.audit.Record(audit.Entry{ //nolint:exhaustruct
+			Event: audit.EventWipeFailed,
+			Chain: "main",
+			Error: errWipe.Error(),
+		})
+
+		return errors.Join(err, errWipe)
+	}
+
+	a.audit.Record(audit.Entry{ //nolint:exhaustruct
+		Event: audit.EventProfileWiped,
+		Chain: "main",
+	})
+
+	return err
+}
+
+// wipeAWSProfile deletes the output profile from the shared credentials and config files.
+func (p *ProfileWriter) wipeAWSProfile() error {
+	credentialsFile, err := awsconfig.CredentialsFile()
+	if err != nil {
+		return fmt.Errorf("unable to locate credentials file: %w", err)
+	}
+
+	configFile, err := awsconfig.ConfigFile()
+	if err != nil {
+		return fmt.Errorf("unable to locate config file: %w", err)
+	}
+
+	_, err = awsconfig.RemoveSection(credentialsFile, p.profileName)
+	if err != nil {
+		return fmt.Errorf("unable to wipe credentials: %w", err)
+	}
+
+	_, err = awsconfig.RemoveSection(configFile, awsconfig.ConfigSection(p.profileName))
+	if err != nil {
+		return fmt.Errorf("unable to wipe config: %w", err)
+	}
+
+	slog.Info("aws profile wiped", slog.String("profile", p.profileName))
+
+	return nil
+}
+
+// checkGuardrails enforces the kill date before a tick starts hopping.
+func (a *App) checkGuardrails() error {
+	err := a.guardrails.checkExpiry()
+	if err != nil {
+		return a.tripGuardrail(err)
+	}
+
+	return nil
+}
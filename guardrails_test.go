@@ -0,0 +1,224 @@
+// Copyright 2025 variHQ OÜ
+// SPDX-License-Identifier: BSD-3-Clause
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+	"github.com/aws/aws-sdk-go-v2/service/sts/types"
+	"github.com/wakeful/trick/internal/audit"
+	"github.com/wakeful/trick/internal/broadcast"
+	"github.com/wakeful/trick/internal/parser"
+)
+
+func TestNewGuardrails(t *testing.T) {
+	t.Parallel()
+
+	got, err := NewGuardrails(nil)
+	if err != nil || got != nil {
+		t.Errorf("NewGuardrails(nil) = %v, %v, want nil, nil", got, err)
+	}
+
+	got, err = NewGuardrails(&parser.Guardrails{NotAfter: "2025-02-01"})
+	if err != nil {
+		t.Fatalf("NewGuardrails() error = %v", err)
+	}
+
+	if got.onExpire != parser.OnExpireStop {
+		t.Errorf("NewGuardrails() onExpire = %q, want %q", got.onExpire, parser.OnExpireStop)
+	}
+
+	if !got.notAfter.Equal(time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("NewGuardrails() notAfter = %v", got.notAfter)
+	}
+
+	_, err = NewGuardrails(&parser.Guardrails{NotAfter: "tomorrow"})
+	if !errors.Is(err, parser.ErrInvalidNotAfter) {
+		t.Errorf("NewGuardrails() error = %v, want %v", err, parser.ErrInvalidNotAfter)
+	}
+}
+
+func TestGuardrails_reserveAssume(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	guard := &Guardrails{maxAssumesPerDay: 2, now: func() time.Time { return now }}
+
+	for range 2 {
+		err := guard.reserveAssume()
+		if err != nil {
+			t.Fatalf("reserveAssume() error = %v", err)
+		}
+	}
+
+	err := guard.reserveAssume()
+	if !errors.Is(err, ErrAssumeBudgetExhausted) {
+		t.Errorf("reserveAssume() error = %v, want %v", err, ErrAssumeBudgetExhausted)
+	}
+
+	now = now.Add(24*time.Hour + time.Second)
+
+	err = guard.reserveAssume()
+	if err != nil {
+		t.Errorf("reserveAssume() after 24h error = %v", err)
+	}
+
+	var disabled *Guardrails
+
+	err = disabled.reserveAssume()
+	if err != nil {
+		t.Errorf("nil reserveAssume() error = %v", err)
+	}
+}
+
+func TestSetRolePool_allowedAccounts(t *testing.T) {
+	t.Parallel()
+
+	roles := []string{
+		"arn:aws:iam::111111111111:role/role-a",
+		"arn:aws:iam::222222222222:role/role-b",
+	}
+
+	_, err := setRolePool(roles, "111111111111", "222222222222")
+	if err != nil {
+		t.Errorf("setRolePool() error = %v", err)
+	}
+
+	_, err = setRolePool(roles, "111111111111")
+	if !errors.Is(err, ErrAccountNotAllowed) || !errors.Is(err, ErrGuardrailTripped) {
+		t.Errorf("setRolePool() error = %v, want %v", err, ErrAccountNotAllowed)
+	}
+}
+
+func TestApp_tickGuardrails(t *testing.T) {
+	t.Parallel()
+
+	pool, err := setRolePool(
+		[]string{"arn:aws:iam::0987654321:role/role-a", "arn:aws:iam::0987654321:role/role-b"},
+	)
+	if err != nil {
+		t.Fatalf("setRolePool failed: %v", err)
+	}
+
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name          string
+		guardrails    *Guardrails
+		wantErr       error
+		wantGuardrail string
+	}{
+		{
+			name:          "kill date reached",
+			guardrails:    &Guardrails{notAfter: now, onExpire: parser.OnExpireStop, now: func() time.Time { return now }},
+			wantErr:       ErrKillDateReached,
+			wantGuardrail: "not_after",
+		},
+		{
+			name:          "budget exhausted",
+			guardrails:    &Guardrails{maxAssumesPerDay: 1, assumes: []time.Time{now}, now: func() time.Time { return now }},
+			wantErr:       ErrAssumeBudgetExhausted,
+			wantGuardrail: "max_assumes_per_day",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var log bytes.Buffer
+
+			a := &App{
+				client: MockSTSClient{
+					mockAssumeRoleOutput: map[string]sts.AssumeRoleOutput{
+						"arn:aws:iam::0987654321:role/role-a": {
+							Credentials: &types.Credentials{
+								AccessKeyId:     aws.String("access-key-id"),
+								SecretAccessKey: aws.String("secret-access-key"),
+								SessionToken:    aws.String("session-token"),
+							},
+						},
+					},
+				},
+				profileWriter: &ProfileWriter{
+					cmdExecutor: &MockCmdExecutor{
+						executeFunc: func(_ string, _ ...string) ([]byte, error) { return nil, nil },
+					},
+					profileName: "testing-profile",
+				},
+				region:      "eu-west-1",
+				roles:       pool,
+				usableRoles: make(map[string]struct{}),
+				broadcaster: broadcast.NewBroadcaster(),
+				audit:       audit.NewLogger(&log),
+				guardrails:  tt.guardrails,
+			}
+
+			err := a.tick(t.Context())
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("tick() error = %v, want %v", err, tt.wantErr)
+			}
+
+			entries, err := audit.Read(&log)
+			if err != nil {
+				t.Fatalf("audit.Read() error = %v", err)
+			}
+
+			last := entries[len(entries)-1]
+			if last.Event != audit.EventGuardrailTripped || last.Guardrail != tt.wantGuardrail {
+				t.Errorf("last audit entry = %+v, want %s trip", last, tt.wantGuardrail)
+			}
+		})
+	}
+}
+
+func TestApp_runPausedKillDate(t *testing.T) {
+	t.Parallel()
+
+	pool, err := setRolePool(
+		[]string{"arn:aws:iam::0987654321:role/role-a", "arn:aws:iam::0987654321:role/role-b"},
+	)
+	if err != nil {
+		t.Fatalf("setRolePool failed: %v", err)
+	}
+
+	var log bytes.Buffer
+
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	a := &App{ //nolint:exhaustruct
+		client:      MockSTSClient{}, //nolint:exhaustruct
+		roles:       pool,
+		usableRoles: make(map[string]struct{}),
+		broadcaster: broadcast.NewBroadcaster(),
+		audit:       audit.NewLogger(&log),
+		guardrails:  &Guardrails{notAfter: now, onExpire: parser.OnExpireStop, now: func() time.Time { return now }},
+		paused:      true,
+	}
+
+	ticker := time.NewTicker(time.Hour)
+	defer ticker.Stop()
+
+	ctx, cancel := context.WithTimeout(t.Context(), time.Second)
+	defer cancel()
+
+	err = a.run(ctx, ticker)
+	if !errors.Is(err, ErrKillDateReached) {
+		t.Fatalf("run() error = %v, want %v", err, ErrKillDateReached)
+	}
+
+	entries, err := audit.Read(&log)
+	if err != nil {
+		t.Fatalf("audit.Read() error = %v", err)
+	}
+
+	if len(entries) != 1 || entries[0].Event != audit.EventGuardrailTripped || entries[0].Guardrail != "not_after" {
+		t.Errorf("audit entries = %+v, want a single not_after trip", entries)
+	}
+}
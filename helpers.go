@@ -9,6 +9,7 @@ import (
 	"fmt"
 	"io"
 	"log/slog"
+	"path/filepath"
 
 	"github.com/aws/aws-sdk-go-v2/service/sts"
 )
@@ -33,21 +34,34 @@ func (a *App) nextRole() string {
 	}
 
 	a.roles = a.roles.Next()
+	a.position = (a.position + 1) % a.roles.Len()
 
 	slog.Debug("next role", slog.String("role", value))
 
 	return value
 }
 
+// rewindRole steps back to the role nextRole returned last, so a failed hop is retried on the next tick.
+func (a *App) rewindRole() {
+	a.roles = a.roles.Prev()
+	a.position = (a.position + a.roles.Len() - 1) % a.roles.Len()
+}
+
 // setRolePool initializes a circular role pool with the provided roles.
 // It requires at least two roles to work properly and returns an error otherwise.
+// When allowedAccounts is not empty, roles living in any other account are rejected.
 // Returns the initialized role pool and nil error on success.
-func setRolePool(roles []string) (*ring.Ring, error) {
+func setRolePool(roles []string, allowedAccounts ...string) (*ring.Ring, error) {
 	const minRoles = 2
 	if len(roles) < minRoles {
 		return nil, ErrMinRoles
 	}
 
+	err := checkAccounts(roles, allowedAccounts)
+	if err != nil {
+		return nil, err
+	}
+
 	rolesPool := ring.New(len(roles))
 	for i := range rolesPool.Len() {
 		rolesPool.Value = roles[i]
@@ -57,6 +71,16 @@ func setRolePool(roles []string) (*ring.Ring, error) {
 	return rolesPool, nil
 }
 
+// absPath returns path made absolute, falling back to path itself if the working directory is unknown.
+func absPath(path string) string {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return path
+	}
+
+	return abs
+}
+
 // getLogger creates and returns a slog.Logger configured with the specified output and log level.
 // The verbosity is set to debug if verbose is true; otherwise, it defaults to info level.
 func getLogger(output io.Writer, verbose *bool) *slog.Logger {
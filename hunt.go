@@ -0,0 +1,120 @@
+// Copyright 2025 variHQ OÜ
+// SPDX-License-Identifier: BSD-3-Clause
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/wakeful/trick/internal/arn"
+	"github.com/wakeful/trick/internal/hunt"
+)
+
+// ErrMissingInput indicates that a command needs at least one input path.
+var ErrMissingInput = errors.New("at least one input path is required")
+
+// huntCommand reads local CloudTrail exports and reports AssumeRole cycles that look like a persistence ring.
+//
+//nolint:cyclop,funlen
+func huntCommand(_ context.Context, args []string, stdout io.Writer) error {
+	flags := flag.NewFlagSet("hunt", flag.ContinueOnError)
+	format := flags.String("format", "table", "output format: table or json")
+	minLength := flags.Int("min-length", 2, "ignore rings with fewer roles")
+	maxLength := flags.Int("max-length", 16, "ignore rings with more roles")
+	minRotations := flags.Int("min-rotations", 1, "ignore rings that were not completed this many times")
+	flags.Usage = func() {
+		_, _ = fmt.Fprintln(flags.Output(), "Usage: trick hunt [flags] <file or directory>...")
+		flags.PrintDefaults()
+	}
+
+	ok, err := parseFlags(flags, args)
+	if !ok {
+		return err
+	}
+
+	if flags.NArg() == 0 {
+		return fmt.Errorf("%w: %w", ErrUsage, ErrMissingInput)
+	}
+
+	events, err := hunt.Load(flags.Args()...)
+	if err != nil {
+		return fmt.Errorf("failed to read CloudTrail: %w", err)
+	}
+
+	found, complete := hunt.FindCycles(events, *maxLength)
+	if !complete {
+		slog.Warn("the AssumeRole graph is too dense to search completely, some rings may be missing; "+
+			"lower -max-length to search it all", slog.Int("max_length", *maxLength))
+	}
+
+	cycles := make([]hunt.Cycle, 0)
+
+	for _, cycle := range found {
+		if cycle.Length() >= *minLength && cycle.Rotations >= *minRotations {
+			cycles = append(cycles, cycle)
+		}
+	}
+
+	switch *format {
+	case "json":
+		encoder := json.NewEncoder(stdout)
+		encoder.SetIndent("", "  ")
+
+		err = encoder.Encode(cycles)
+		if err != nil {
+			return fmt.Errorf("failed to write cycles: %w", err)
+		}
+
+		return nil
+	case "table":
+	default:
+		return fmt.Errorf("%w: %q", ErrUnknownFormat, *format)
+	}
+
+	_, _ = fmt.Fprintf(stdout, "%d AssumeRole events, %d ring(s)\n", len(events), len(cycles))
+
+	if len(cycles) == 0 {
+		return nil
+	}
+
+	const padding = 2
+
+	table := tabwriter.NewWriter(stdout, 0, 0, padding, ' ', 0)
+	_, _ = fmt.Fprintln(table, "\nLENGTH\tHOPS\tROTATIONS\tCADENCE\tSESSIONS\tFIRST SEEN\tLAST SEEN\tRING")
+
+	for _, cycle := range cycles {
+		names := make([]string, 0, cycle.Length()+1)
+		for _, role := range cycle.Roles {
+			names = append(names, arn.Name(role))
+		}
+
+		names = append(names, names[0])
+
+		_, _ = fmt.Fprintf(table, "%d\t%d\t%d\t%s\t%s\t%s\t%s\t%s\n",
+			cycle.Length(),
+			cycle.Hops,
+			cycle.Rotations,
+			cycle.Cadence.Round(time.Second),
+			strings.Join(cycle.Sessions, ","),
+			cycle.FirstSeen.UTC().Format(time.RFC3339),
+			cycle.LastSeen.UTC().Format(time.RFC3339),
+			strings.Join(names, " -> "),
+		)
+	}
+
+	err = table.Flush()
+	if err != nil {
+		return fmt.Errorf("failed to write cycles: %w", err)
+	}
+
+	return nil
+}
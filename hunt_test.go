@@ -0,0 +1,78 @@
+// Copyright 2025 variHQ OÜ
+// SPDX-License-Identifier: BSD-3-Clause
+
+package main
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const trailExport = `{"Records": [
+  {"eventTime": "2025-01-01T10:00:00Z", "eventSource": "sts.amazonaws.com", "eventName": "AssumeRole",
+   "userIdentity": {"arn": "arn:aws:sts::123456789012:assumed-role/role-a/trick"},
+   "requestParameters": {"roleArn": "arn:aws:iam::123456789012:role/role-b", "roleSessionName": "trick"}},
+  {"eventTime": "2025-01-01T10:12:00Z", "eventSource": "sts.amazonaws.com", "eventName": "AssumeRole",
+   "userIdentity": {"arn": "arn:aws:sts::123456789012:assumed-role/role-b/trick"},
+   "requestParameters": {"roleArn": "arn:aws:iam::123456789012:role/role-a", "roleSessionName": "trick"}}
+]}`
+
+func TestHuntCommand(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "trail.json")
+
+	err := os.WriteFile(path, []byte(trailExport), 0o600)
+	if err != nil {
+		t.Fatalf("failed to write export: %v", err)
+	}
+
+	tests := []struct {
+		name         string
+		args         []string
+		wantErr      error
+		wantContains string
+	}{
+		{
+			name:    "missing input",
+			args:    []string{},
+			wantErr: ErrMissingInput,
+		},
+		{
+			name:         "table",
+			args:         []string{path},
+			wantContains: "role-a -> role-b -> role-a",
+		},
+		{
+			name:         "json",
+			args:         []string{"-format", "json", path},
+			wantContains: `"arn:aws:iam::123456789012:role/role-b"`,
+		},
+		{
+			name:         "filtered by rotations",
+			args:         []string{"-min-rotations", "2", path},
+			wantContains: "2 AssumeRole events, 0 ring(s)",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var stdout bytes.Buffer
+
+			err := huntCommand(t.Context(), tt.args, &stdout)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("hunt error = %v, want %v", err, tt.wantErr)
+			}
+
+			if !strings.Contains(stdout.String(), tt.wantContains) {
+				t.Errorf("hunt output missing %q:\n%s", tt.wantContains, stdout.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,255 @@
+// Copyright 2025 variHQ OÜ
+// SPDX-License-Identifier: BSD-3-Clause
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/wakeful/trick/internal/arn"
+	"github.com/wakeful/trick/internal/parser"
+	"github.com/wakeful/trick/internal/tfimport"
+)
+
+// ErrUnknownSource indicates that trick import was asked for an unsupported input.
+var ErrUnknownSource = errors.New("unknown import source")
+
+func importers() map[string]func(ctx context.Context, args []string, stdout io.Writer) error {
+	return map[string]func(ctx context.Context, args []string, stdout io.Writer) error{
+		"aws-config": importAWSConfigCommand,
+		"terraform":  importTerraformCommand,
+	}
+}
+
+// importCommand builds a trick profile from another tool's configuration, e.g. `trick import aws-config`.
+func importCommand(ctx context.Context, args []string, stdout io.Writer) error {
+	sources := strings.Join(slices.Sorted(maps.Keys(importers())), ", ")
+
+	if len(args) == 0 || strings.HasPrefix(args[0], "-") {
+		return fmt.Errorf("%w: expected one of %s", ErrUnknownSource, sources)
+	}
+
+	run, found := importers()[args[0]]
+	if !found {
+		return fmt.Errorf("%w: %q, expected one of %s", ErrUnknownSource, args[0], sources)
+	}
+
+	return run(ctx, args[1:], stdout)
+}
+
+// roleMapping overrides flags of a single role, keyed by output name or role name in the mapping file. Use marks a
+// usable role, written as skip = true just like a role passed with -use.
+type roleMapping struct {
+	Use   *bool `json:"use"`
+	Entry *bool `json:"entry"`
+}
+
+func readMapping(path string) (map[string]roleMapping, error) {
+	mapping := make(map[string]roleMapping)
+
+	if path == "" {
+		return mapping, nil
+	}
+
+	content, err := os.ReadFile(path) //nolint:gosec
+	if err != nil {
+		return nil, fmt.Errorf("failed to read mapping file: %w", err)
+	}
+
+	err = json.Unmarshal(content, &mapping)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode mapping file: %w", err)
+	}
+
+	return mapping, nil
+}
+
+// openInput opens path, or returns stdin for an empty path or "-".
+func openInput(path string) (io.ReadCloser, error) {
+	if path == "" || path == "-" {
+		return io.NopCloser(os.Stdin), nil
+	}
+
+	file, err := os.Open(path) //nolint:gosec
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s: %w", path, err)
+	}
+
+	return file, nil
+}
+
+// importTerraformCommand turns `terraform output -json` into a trick profile.
+//
+//nolint:cyclop,funlen
+func importTerraformCommand(_ context.Context, args []string, stdout io.Writer) error {
+	flags := flag.NewFlagSet("import terraform", flag.ContinueOnError)
+	keyPattern := flags.String(
+		"key-pattern",
+		tfimport.DefaultKeyPattern,
+		"regular expression selecting role ARN outputs; the first group orders them",
+	)
+	statePath := flags.String(
+		"state",
+		"",
+		"terraform.tfstate or `terraform show -json` output; orders roles by their can_assume relationships",
+	)
+	usePattern := flags.String(
+		"use-pattern",
+		"",
+		"regular expression on the names of usable roles, the ones the chain stops at; written as skip = true like -use",
+	)
+	mappingPath := flags.String("mapping", "", `JSON file mapping output or role names to {"use": bool, "entry": bool}`)
+	profileName := flags.String("profile", "terraform", "name of the generated profile")
+	region := flags.String("region", "eu-west-1", "region of the generated profile")
+	ttl := flags.Int64("ttl", 12, "minutes between jumps") //nolint:mnd
+	output := flags.String("output", "", "write the profile to this file instead of stdout")
+	flags.Usage = func() {
+		_, _ = fmt.Fprintln(flags.Output(), "Usage: trick import terraform [flags] [outputs.json | -]")
+		flags.PrintDefaults()
+	}
+
+	ok, err := parseFlags(flags, args)
+	if !ok {
+		return err
+	}
+
+	pattern, err := regexp.Compile(*keyPattern)
+	if err != nil {
+		return fmt.Errorf("invalid -key-pattern: %w", err)
+	}
+
+	var usable *regexp.Regexp
+	if *usePattern != "" {
+		usable, err = regexp.Compile(*usePattern)
+		if err != nil {
+			return fmt.Errorf("invalid -use-pattern: %w", err)
+		}
+	}
+
+	mapping, err := readMapping(*mappingPath)
+	if err != nil {
+		return err
+	}
+
+	input, err := openInput(flags.Arg(0))
+	if err != nil {
+		return err
+	}
+
+	defer func() { _ = input.Close() }()
+
+	outputs, err := tfimport.ReadOutputs(input)
+	if err != nil {
+		return fmt.Errorf("failed to read terraform outputs: %w", err)
+	}
+
+	roles, err := tfimport.SelectRoles(outputs, pattern)
+	if err != nil {
+		return fmt.Errorf("failed to select roles: %w", err)
+	}
+
+	if *statePath != "" {
+		roles, err = orderByState(roles, *statePath)
+		if err != nil {
+			return err
+		}
+	}
+
+	chain := &parser.Chain{TTL: *ttl, UseRoles: make([]*parser.UseRoles, 0, len(roles))}
+
+	for _, role := range roles {
+		use := &parser.UseRoles{
+			ARN:   role.ARN,
+			Skip:  usable != nil && usable.MatchString(arn.Name(role.ARN)),
+			Entry: role.Entry,
+		}
+
+		for _, key := range []string{role.Key, arn.Name(role.ARN)} {
+			override, found := mapping[key]
+			if !found {
+				continue
+			}
+
+			if override.Use != nil {
+				use.Skip = *override.Use
+			}
+
+			if override.Entry != nil {
+				use.Entry = *override.Entry
+			}
+		}
+
+		chain.UseRoles = append(chain.UseRoles, use)
+	}
+
+	config := &parser.Config{
+		SelectProfile: *profileName,
+		Profiles: []*parser.Profile{{
+			Name:       *profileName,
+			Region:     *region,
+			Chain:      chain,
+			Guardrails: nil,
+		}},
+	}
+
+	return writeProfile(config, *output, stdout)
+}
+
+// writeProfile validates config and writes it as HCL to path, or to stdout when path is empty.
+func writeProfile(config *parser.Config, path string, stdout io.Writer) error {
+	encoded := config.Encode()
+
+	_, err := parser.Parse(encoded)
+	if err != nil {
+		return fmt.Errorf("generated profile is invalid: %w", err)
+	}
+
+	if path == "" {
+		_, err = stdout.Write(encoded)
+		if err != nil {
+			return fmt.Errorf("failed to write profile: %w", err)
+		}
+
+		return nil
+	}
+
+	const fileMode = 0o600
+
+	err = os.WriteFile(path, encoded, fileMode)
+	if err != nil {
+		return fmt.Errorf("failed to write profile: %w", err)
+	}
+
+	return nil
+}
+
+func orderByState(roles []tfimport.Role, path string) ([]tfimport.Role, error) {
+	file, err := os.Open(path) //nolint:gosec
+	if err != nil {
+		return nil, fmt.Errorf("failed to open state: %w", err)
+	}
+
+	defer func() { _ = file.Close() }()
+
+	trust, err := tfimport.ReadState(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read state: %w", err)
+	}
+
+	ordered, err := tfimport.OrderByTrust(roles, trust)
+	if err != nil {
+		return nil, fmt.Errorf("failed to order roles: %w", err)
+	}
+
+	return ordered, nil
+}
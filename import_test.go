@@ -0,0 +1,121 @@
+// Copyright 2025 variHQ OÜ
+// SPDX-License-Identifier: BSD-3-Clause
+
+package main
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const terraformOutputs = `{
+  "role_arn_a": {"sensitive": false, "type": "string", "value": "arn:aws:iam::123456789012:role/trick-role-a"},
+  "role_arn_b": {"sensitive": false, "type": "string", "value": "arn:aws:iam::123456789012:role/trick-role-b"},
+  "role_arn_c": {"sensitive": false, "type": "string", "value": "arn:aws:iam::123456789012:role/trick-role-c"}
+}`
+
+// terraformState trusts a -> c -> b -> a, with c as the entry point.
+const terraformState = `{"version": 4, "resources": [
+  {"mode": "managed", "type": "aws_iam_role", "instances": [{"attributes": {
+    "arn": "arn:aws:iam::123456789012:role/trick-role-a",
+    "assume_role_policy": "{\"Statement\":[{\"Effect\":\"Allow\",\"Action\":\"sts:AssumeRole\",\"Principal\":{\"AWS\":\"arn:aws:iam::123456789012:role/trick-role-b\"}}]}"}}]},
+  {"mode": "managed", "type": "aws_iam_role", "instances": [{"attributes": {
+    "arn": "arn:aws:iam::123456789012:role/trick-role-b",
+    "assume_role_policy": "{\"Statement\":[{\"Effect\":\"Allow\",\"Action\":\"sts:AssumeRole\",\"Principal\":{\"AWS\":\"arn:aws:iam::123456789012:role/trick-role-c\"}}]}"}}]},
+  {"mode": "managed", "type": "aws_iam_role", "instances": [{"attributes": {
+    "arn": "arn:aws:iam::123456789012:role/trick-role-c",
+    "assume_role_policy": "{\"Statement\":[{\"Effect\":\"Allow\",\"Action\":\"sts:AssumeRole\",\"Principal\":{\"AWS\":[\"arn:aws:iam::123456789012:root\"]}},{\"Effect\":\"Allow\",\"Action\":\"sts:AssumeRole\",\"Principal\":{\"AWS\":\"arn:aws:iam::123456789012:role/trick-role-a\"}}]}"}}]}
+]}`
+
+func TestImportCommand(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+
+	files := map[string]string{
+		"outputs.json": terraformOutputs,
+		"state.json":   terraformState,
+		"mapping.json": `{"role_arn_a": {"use": true}, "trick-role-c": {"entry": false}}`,
+	}
+
+	for name, content := range files {
+		err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o600)
+		if err != nil {
+			t.Fatalf("failed to write %s: %v", name, err)
+		}
+	}
+
+	outputs := filepath.Join(dir, "outputs.json")
+
+	tests := []struct {
+		name         string
+		args         []string
+		wantErr      error
+		wantContains []string
+	}{
+		{
+			name:    "unknown source",
+			args:    []string{"pulumi", outputs},
+			wantErr: ErrUnknownSource,
+		},
+		{
+			name: "ordered by key",
+			args: []string{"terraform", "-profile", "lab", outputs},
+			wantContains: []string{
+				"select_profile = profile.lab\n",
+				"role/trick-role-a\"\n    }\n\n    use {\n      arn = \"arn:aws:iam::123456789012:role/trick-role-b",
+			},
+		},
+		{
+			name: "ordered by state",
+			args: []string{"terraform", "-state", filepath.Join(dir, "state.json"), outputs},
+			wantContains: []string{
+				"arn   = \"arn:aws:iam::123456789012:role/trick-role-c\"\n      entry = true\n    }\n\n" +
+					"    use {\n      arn = \"arn:aws:iam::123456789012:role/trick-role-b\"",
+			},
+		},
+		{
+			name: "usable by naming convention",
+			args: []string{"terraform", "-use-pattern", "-b$", outputs},
+			wantContains: []string{
+				"arn  = \"arn:aws:iam::123456789012:role/trick-role-b\"\n      skip = true\n",
+			},
+		},
+		{
+			name: "mapping file",
+			args: []string{
+				"terraform",
+				"-mapping", filepath.Join(dir, "mapping.json"),
+				"-state", filepath.Join(dir, "state.json"),
+				outputs,
+			},
+			wantContains: []string{
+				"arn  = \"arn:aws:iam::123456789012:role/trick-role-a\"\n      skip = true\n",
+				"use {\n      arn = \"arn:aws:iam::123456789012:role/trick-role-c\"\n    }",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var stdout bytes.Buffer
+
+			err := importCommand(t.Context(), tt.args, &stdout)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("import error = %v, want %v", err, tt.wantErr)
+			}
+
+			for _, want := range tt.wantContains {
+				if !strings.Contains(stdout.String(), want) {
+					t.Errorf("import output missing %q:\n%s", want, stdout.String())
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,118 @@
+// Copyright 2025 variHQ OÜ
+// SPDX-License-Identifier: BSD-3-Clause
+
+package arn
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrInvalidARN is returned when a string is not a well-formed ARN.
+var ErrInvalidARN = errors.New("invalid ARN")
+
+// ErrNotAssumedRole is returned when an ARN is not an STS assumed-role session.
+var ErrNotAssumedRole = errors.New("not an assumed-role ARN")
+
+// ErrNotRole is returned when an ARN is not an IAM role.
+var ErrNotRole = errors.New("not an IAM role ARN")
+
+// SessionName is the RoleSessionName trick uses for every AssumeRole call.
+const SessionName = "trick"
+
+// ARN is the parsed form of arn:partition:service:region:account:resource.
+type ARN struct {
+	Partition string
+	Service   string
+	Region    string
+	Account   string
+	Resource  string
+}
+
+// Parse splits value into its ARN fields.
+func Parse(value string) (ARN, error) {
+	const fields = 6
+
+	parts := strings.SplitN(value, ":", fields)
+	if len(parts) != fields || parts[0] != "arn" {
+		return ARN{}, fmt.Errorf("%w: %q", ErrInvalidARN, value)
+	}
+
+	return ARN{
+		Partition: parts[1],
+		Service:   parts[2],
+		Region:    parts[3],
+		Account:   parts[4],
+		Resource:  parts[5],
+	}, nil
+}
+
+// String joins the fields back into an ARN.
+func (a ARN) String() string {
+	return strings.Join([]string{"arn", a.Partition, a.Service, a.Region, a.Account, a.Resource}, ":")
+}
+
+// Name returns the short, human-readable name of a role: the last path element, or the last field for
+// values that are not role ARNs.
+func Name(value string) string {
+	if strings.Contains(value, "/") {
+		parts := strings.Split(value, "/")
+
+		return parts[len(parts)-1]
+	}
+
+	parts := strings.Split(value, ":")
+
+	return parts[len(parts)-1]
+}
+
+// AssumedRole returns the STS session ARN a caller gets after assuming roleARN with sessionName.
+func AssumedRole(roleARN, sessionName string) (string, error) {
+	parsed, err := Parse(roleARN)
+	if err != nil {
+		return "", err
+	}
+
+	if parsed.Service != "iam" || !strings.HasPrefix(parsed.Resource, "role/") {
+		return "", fmt.Errorf("%w: %q", ErrNotRole, roleARN)
+	}
+
+	parsed.Service = "sts"
+	parsed.Resource = "assumed-role/" + Name(parsed.Resource) + "/" + sessionName
+
+	return parsed.String(), nil
+}
+
+// RoleFromAssumed maps an STS assumed-role session ARN back to the role ARN and session name.
+// Role paths are not part of the session ARN, so the returned role ARN has none.
+func RoleFromAssumed(sessionARN string) (string, string, error) {
+	parsed, err := Parse(sessionARN)
+	if err != nil {
+		return "", "", err
+	}
+
+	const parts = 3
+
+	resource := strings.SplitN(parsed.Resource, "/", parts)
+	if parsed.Service != "sts" || len(resource) != parts || resource[0] != "assumed-role" {
+		return "", "", fmt.Errorf("%w: %q", ErrNotAssumedRole, sessionARN)
+	}
+
+	parsed.Service = "iam"
+	parsed.Resource = "role/" + resource[1]
+
+	return parsed.String(), resource[2], nil
+}
+
+// WithoutPath drops the path from a role ARN so it can be compared with ARNs derived from sessions.
+func WithoutPath(roleARN string) string {
+	parsed, err := Parse(roleARN)
+	if err != nil || !strings.HasPrefix(parsed.Resource, "role/") {
+		return roleARN
+	}
+
+	parsed.Resource = "role/" + Name(parsed.Resource)
+
+	return parsed.String()
+}
@@ -0,0 +1,86 @@
+// Copyright 2025 variHQ OÜ
+// SPDX-License-Identifier: BSD-3-Clause
+
+package arn_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/wakeful/trick/internal/arn"
+)
+
+func TestParse(t *testing.T) {
+	t.Parallel()
+
+	got, err := arn.Parse("arn:aws:iam::123456789012:role/path/role-a")
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	if got.Account != "123456789012" || got.Resource != "role/path/role-a" || got.Service != "iam" {
+		t.Errorf("Parse() = %+v", got)
+	}
+
+	if got.String() != "arn:aws:iam::123456789012:role/path/role-a" {
+		t.Errorf("String() = %q", got.String())
+	}
+
+	_, err = arn.Parse("arn::42::role-a")
+	if !errors.Is(err, arn.ErrInvalidARN) {
+		t.Errorf("Parse() error = %v, want %v", err, arn.ErrInvalidARN)
+	}
+}
+
+func TestName(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]string{
+		"arn:aws:iam::123456789012:role/path/role-a": "role-a",
+		"arn:aws:iam::123456789012:role/role-b":      "role-b",
+		"arn::42::role-c":                            "role-c",
+		"role-d":                                     "role-d",
+	}
+
+	for value, want := range tests {
+		if got := arn.Name(value); got != want {
+			t.Errorf("Name(%q) = %q, want %q", value, got, want)
+		}
+	}
+}
+
+func TestAssumedRole(t *testing.T) {
+	t.Parallel()
+
+	got, err := arn.AssumedRole("arn:aws:iam::123456789012:role/path/role-a", "trick")
+	if err != nil {
+		t.Fatalf("AssumedRole() error = %v", err)
+	}
+
+	if got != "arn:aws:sts::123456789012:assumed-role/role-a/trick" {
+		t.Errorf("AssumedRole() = %q", got)
+	}
+
+	role, session, err := arn.RoleFromAssumed(got)
+	if err != nil {
+		t.Fatalf("RoleFromAssumed() error = %v", err)
+	}
+
+	if role != "arn:aws:iam::123456789012:role/role-a" || session != "trick" {
+		t.Errorf("RoleFromAssumed() = %q, %q", role, session)
+	}
+
+	_, err = arn.AssumedRole("arn:aws:iam::123456789012:user/alice", "trick")
+	if !errors.Is(err, arn.ErrNotRole) {
+		t.Errorf("AssumedRole() error = %v, want %v", err, arn.ErrNotRole)
+	}
+
+	_, _, err = arn.RoleFromAssumed("arn:aws:iam::123456789012:role/role-a")
+	if !errors.Is(err, arn.ErrNotAssumedRole) {
+		t.Errorf("RoleFromAssumed() error = %v, want %v", err, arn.ErrNotAssumedRole)
+	}
+
+	if got := arn.WithoutPath("arn:aws:iam::123456789012:role/path/role-a"); got != "arn:aws:iam::123456789012:role/role-a" {
+		t.Errorf("WithoutPath() = %q", got)
+	}
+}
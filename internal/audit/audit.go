@@ -0,0 +1,137 @@
+// Copyright 2025 variHQ OÜ
+// SPDX-License-Identifier: BSD-3-Clause
+
+package audit
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+const (
+	// EventStart is recorded once the chain is loaded and before the first hop.
+	EventStart = "start"
+	// EventAssume is recorded after a successful AssumeRole call.
+	EventAssume = "assume"
+	// EventAssumeFailed is recorded when AssumeRole returns an error.
+	EventAssumeFailed = "assume-failed"
+	// EventCredentialsWritten is recorded after the output profile was updated.
+	EventCredentialsWritten = "credentials-written"
+	// EventWriteFailed is recorded when the output profile could not be updated.
+	EventWriteFailed = "write-failed"
+	// EventStop is recorded when the run loop exits.
+	EventStop = "stop"
+	// EventGuardrailTripped is recorded when a rules-of-engagement limit stops the chain.
+	EventGuardrailTripped = "guardrail-tripped"
+	// EventProfileWiped is recorded after on_expire = stop_and_wipe deleted the output profile.
+	EventProfileWiped = "profile-wiped"
+	// EventWipeFailed is recorded when the output profile could not be deleted.
+	EventWipeFailed = "wipe-failed"
+	// EventConfigReloaded is recorded after the ring was rebuilt from reloaded settings.
+	EventConfigReloaded = "config-reloaded"
+)
+
+// Entry is a single line of the audit log.
+type Entry struct {
+	Time        time.Time  `json:"time"`
+	Event       string     `json:"event"`
+	Chain       string     `json:"chain,omitempty"`
+	Role        string     `json:"role,omitempty"`
+	Usable      bool       `json:"usable,omitempty"`
+	Roles       []string   `json:"roles,omitempty"`
+	UsableRoles []string   `json:"usable_roles,omitempty"`
+	Refresh     int64      `json:"refresh,omitempty"`
+	Expiration  *time.Time `json:"expiration,omitempty"`
+	Guardrail   string     `json:"guardrail,omitempty"`
+	Class       string     `json:"class,omitempty"`
+	Error       string     `json:"error,omitempty"`
+}
+
+// Logger appends entries to the audit log as JSON lines.
+// A nil *Logger is valid and discards every entry.
+type Logger struct {
+	mu  sync.Mutex
+	enc *json.Encoder
+	now func() time.Time
+}
+
+// NewLogger returns a Logger writing JSON lines to output.
+func NewLogger(output io.Writer) *Logger {
+	return &Logger{
+		mu:  sync.Mutex{},
+		enc: json.NewEncoder(output),
+		now: time.Now,
+	}
+}
+
+// Record appends entry to the log, stamping it with the current time when Time is unset.
+func (l *Logger) Record(entry Entry) {
+	if l == nil {
+		return
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if entry.Time.IsZero() {
+		entry.Time = l.now().UTC()
+	}
+
+	err := l.enc.Encode(entry)
+	if err != nil {
+		slog.Error("failed to write audit entry",
+			slog.String("event", entry.Event),
+			slog.String("error", err.Error()),
+		)
+	}
+}
+
+// ErrEmptyLog is returned by Read when the input holds no entries.
+var ErrEmptyLog = errors.New("audit log is empty")
+
+// Read decodes every JSON line from input, skipping blank lines.
+func Read(input io.Reader) ([]Entry, error) {
+	entries := make([]Entry, 0)
+	scanner := bufio.NewScanner(input)
+
+	const maxLine = 1 << 20
+
+	scanner.Buffer(make([]byte, 0, bufio.MaxScanTokenSize), maxLine)
+
+	line := 0
+
+	for scanner.Scan() {
+		line++
+
+		raw := scanner.Bytes()
+		if len(raw) == 0 {
+			continue
+		}
+
+		var entry Entry
+
+		err := json.Unmarshal(raw, &entry)
+		if err != nil {
+			return nil, fmt.Errorf("invalid audit entry on line %d: %w", line, err)
+		}
+
+		entries = append(entries, entry)
+	}
+
+	err := scanner.Err()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read audit log: %w", err)
+	}
+
+	if len(entries) == 0 {
+		return nil, ErrEmptyLog
+	}
+
+	return entries, nil
+}
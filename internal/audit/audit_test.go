@@ -0,0 +1,107 @@
+// Copyright 2025 variHQ OÜ
+// SPDX-License-Identifier: BSD-3-Clause
+
+package audit_test
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/wakeful/trick/internal/audit"
+)
+
+func TestLogger_Record(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+
+	logger := audit.NewLogger(&buf)
+	logger.Record(audit.Entry{Event: audit.EventAssume, Role: "arn::42::role-a", Usable: true})
+	logger.Record(audit.Entry{Event: audit.EventStop})
+
+	entries, err := audit.Read(&buf)
+	if err != nil {
+		t.Fatalf("Read() error = %v", err)
+	}
+
+	if len(entries) != 2 {
+		t.Fatalf("Read() got %d entries, want 2", len(entries))
+	}
+
+	if entries[0].Role != "arn::42::role-a" || !entries[0].Usable {
+		t.Errorf("Read() first entry = %+v", entries[0])
+	}
+
+	if entries[1].Time.IsZero() {
+		t.Error("Record() should stamp entries without a time")
+	}
+}
+
+func TestLogger_RecordNil(t *testing.T) {
+	t.Parallel()
+
+	var logger *audit.Logger
+
+	logger.Record(audit.Entry{Event: audit.EventStart})
+}
+
+func TestRead(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		input   string
+		want    int
+		wantErr error
+	}{
+		{
+			name:    "empty log",
+			input:   "\n\n",
+			wantErr: audit.ErrEmptyLog,
+		},
+		{
+			name:  "skips blank lines",
+			input: `{"time":"2025-01-01T00:00:00Z","event":"start"}` + "\n\n" + `{"time":"2025-01-01T00:00:01Z","event":"stop"}`,
+			want:  2,
+		},
+		{
+			name:  "invalid json",
+			input: `{"time":`,
+			want:  0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := audit.Read(strings.NewReader(tt.input))
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Read() error = %v, want %v", err, tt.wantErr)
+			}
+
+			if tt.want == 0 {
+				if err == nil {
+					t.Error("Read() expected an error")
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Read() unexpected error = %v", err)
+			}
+
+			if len(got) != tt.want {
+				t.Errorf("Read() got %d entries, want %d", len(got), tt.want)
+			}
+
+			if !got[0].Time.Equal(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)) {
+				t.Errorf("Read() first time = %v", got[0].Time)
+			}
+		})
+	}
+}
@@ -0,0 +1,125 @@
+// Copyright 2025 variHQ OÜ
+// SPDX-License-Identifier: BSD-3-Clause
+
+package awsconfig
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// CredentialsFile returns the shared credentials file path, honouring AWS_SHARED_CREDENTIALS_FILE.
+func CredentialsFile() (string, error) {
+	return sharedFile("AWS_SHARED_CREDENTIALS_FILE", "credentials")
+}
+
+// ConfigFile returns the shared config file path, honouring AWS_CONFIG_FILE.
+func ConfigFile() (string, error) {
+	return sharedFile("AWS_CONFIG_FILE", "config")
+}
+
+func sharedFile(env, name string) (string, error) {
+	if path := os.Getenv(env); path != "" {
+		return path, nil
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("unable to locate home directory: %w", err)
+	}
+
+	return filepath.Join(home, ".aws", name), nil
+}
+
+// ConfigSection returns the section name used for profile in the shared config file.
+func ConfigSection(profile string) string {
+	if profile == "default" {
+		return profile
+	}
+
+	return "profile " + profile
+}
+
+// sectionName returns the trimmed name of an INI section header, or false if line is not a header.
+func sectionName(line string) (string, bool) {
+	trimmed := strings.TrimSpace(line)
+	if !strings.HasPrefix(trimmed, "[") || !strings.HasSuffix(trimmed, "]") {
+		return "", false
+	}
+
+	return strings.Join(strings.Fields(trimmed[1:len(trimmed)-1]), " "), true
+}
+
+// HasSection reports whether the INI file at path contains section name.
+// A missing file contains no sections.
+func HasSection(path, name string) (bool, error) {
+	content, err := os.ReadFile(path) //nolint:gosec
+	if errors.Is(err, fs.ErrNotExist) {
+		return false, nil
+	}
+
+	if err != nil {
+		return false, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for scanner.Scan() {
+		if section, ok := sectionName(scanner.Text()); ok && section == name {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// RemoveSection deletes section name and its keys from the INI file at path, keeping everything else intact.
+// It reports whether the section was found; a missing file is not an error.
+func RemoveSection(path, name string) (bool, error) {
+	info, err := os.Stat(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return false, nil
+	}
+
+	if err != nil {
+		return false, fmt.Errorf("failed to stat %s: %w", path, err)
+	}
+
+	content, err := os.ReadFile(path) //nolint:gosec
+	if err != nil {
+		return false, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	var (
+		out      bytes.Buffer
+		found    bool
+		dropping bool
+	)
+
+	for line := range strings.Lines(string(content)) {
+		if section, ok := sectionName(line); ok {
+			dropping = section == name
+			found = found || dropping
+		}
+
+		if !dropping {
+			out.WriteString(line)
+		}
+	}
+
+	if !found {
+		return false, nil
+	}
+
+	err = os.WriteFile(path, out.Bytes(), info.Mode().Perm())
+	if err != nil {
+		return false, fmt.Errorf("failed to write %s: %w", path, err)
+	}
+
+	return true, nil
+}
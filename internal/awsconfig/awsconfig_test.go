@@ -0,0 +1,110 @@
+// Copyright 2025 variHQ OÜ
+// SPDX-License-Identifier: BSD-3-Clause
+
+package awsconfig_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/wakeful/trick/internal/awsconfig"
+)
+
+const sharedConfig = `[default]
+region = eu-west-1
+
+[profile trick-jump-credentials]
+region = eu-west-1
+aws_access_key_id = AKIA
+
+[profile other]
+region = us-east-1
+`
+
+func TestRemoveSection(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		content   string
+		section   string
+		wantFound bool
+		want      string
+	}{
+		{
+			name:      "removes only the named section",
+			content:   sharedConfig,
+			section:   awsconfig.ConfigSection("trick-jump-credentials"),
+			wantFound: true,
+			want:      "[default]\nregion = eu-west-1\n\n[profile other]\nregion = us-east-1\n",
+		},
+		{
+			name:      "missing section leaves the file untouched",
+			content:   sharedConfig,
+			section:   "profile nope",
+			wantFound: false,
+			want:      sharedConfig,
+		},
+		{
+			name:      "last section without trailing newline",
+			content:   "[a]\nk = v\n[b]\nk = v",
+			section:   "b",
+			wantFound: true,
+			want:      "[a]\nk = v\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			path := filepath.Join(t.TempDir(), "config")
+
+			err := os.WriteFile(path, []byte(tt.content), 0o600)
+			if err != nil {
+				t.Fatalf("failed to write config: %v", err)
+			}
+
+			found, err := awsconfig.RemoveSection(path, tt.section)
+			if err != nil {
+				t.Fatalf("RemoveSection() error = %v", err)
+			}
+
+			if found != tt.wantFound {
+				t.Errorf("RemoveSection() found = %v, want %v", found, tt.wantFound)
+			}
+
+			got, _ := os.ReadFile(path)
+			if string(got) != tt.want {
+				t.Errorf("RemoveSection() content = %q, want %q", got, tt.want)
+			}
+
+			has, err := awsconfig.HasSection(path, tt.section)
+			if err != nil || has {
+				t.Errorf("HasSection() = %v, %v after removal", has, err)
+			}
+		})
+	}
+}
+
+func TestRemoveSection_MissingFile(t *testing.T) {
+	t.Parallel()
+
+	found, err := awsconfig.RemoveSection(filepath.Join(t.TempDir(), "missing"), "default")
+	if err != nil || found {
+		t.Errorf("RemoveSection() = %v, %v, want false, nil", found, err)
+	}
+}
+
+func TestConfigSection(t *testing.T) {
+	t.Parallel()
+
+	if got := awsconfig.ConfigSection("default"); got != "default" {
+		t.Errorf("ConfigSection(default) = %q", got)
+	}
+
+	if got := awsconfig.ConfigSection("trick"); got != "profile trick" {
+		t.Errorf("ConfigSection(trick) = %q", got)
+	}
+}
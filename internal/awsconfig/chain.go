@@ -0,0 +1,240 @@
+// Copyright 2025 variHQ OÜ
+// SPDX-License-Identifier: BSD-3-Clause
+
+package awsconfig
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/wakeful/trick/internal/arn"
+	"github.com/wakeful/trick/internal/parser"
+)
+
+var (
+	// ErrProfileMissing is returned when the profile to follow is not defined.
+	ErrProfileMissing = errors.New("profile is not defined")
+	// ErrNoRoleProfile is returned when a profile expected to assume a role has no role_arn.
+	ErrNoRoleProfile = errors.New("profile has no role_arn")
+	// ErrAmbiguousChain is returned when several chains end in the file and none was picked.
+	ErrAmbiguousChain = errors.New("several role chains found, pick one")
+)
+
+// CLIProfile is the part of a shared config profile that describes role chaining.
+type CLIProfile struct {
+	Name            string
+	RoleARN         string
+	SourceProfile   string
+	Region          string
+	RoleSessionName string
+}
+
+// ReadProfiles parses a shared config file into its profiles, keyed by profile name.
+func ReadProfiles(input io.Reader) (map[string]CLIProfile, error) {
+	profiles := make(map[string]CLIProfile)
+	current := ""
+
+	scanner := bufio.NewScanner(input)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, ";") {
+			continue
+		}
+
+		if section, ok := sectionName(line); ok {
+			current = strings.TrimPrefix(section, "profile ")
+			if _, found := profiles[current]; !found {
+				profiles[current] = CLIProfile{Name: current} //nolint:exhaustruct
+			}
+
+			continue
+		}
+
+		key, value, found := strings.Cut(line, "=")
+		if !found || current == "" {
+			continue
+		}
+
+		profile := profiles[current]
+
+		switch strings.ToLower(strings.TrimSpace(key)) {
+		case "role_arn":
+			profile.RoleARN = strings.TrimSpace(value)
+		case "source_profile":
+			profile.SourceProfile = strings.TrimSpace(value)
+		case "region":
+			profile.Region = strings.TrimSpace(value)
+		case "role_session_name":
+			profile.RoleSessionName = strings.TrimSpace(value)
+		}
+
+		profiles[current] = profile
+	}
+
+	err := scanner.Err()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read shared config: %w", err)
+	}
+
+	return profiles, nil
+}
+
+// Tails returns the role profiles no other profile sources from, i.e. where a chain ends. In a file holding only a
+// closed ring there is no such profile and the ring's smallest profile name is returned.
+func Tails(profiles map[string]CLIProfile) []string {
+	sourced := make(map[string]bool)
+	for _, profile := range profiles {
+		sourced[profile.SourceProfile] = true
+	}
+
+	tails := make([]string, 0)
+	inRing := make([]string, 0)
+
+	for _, name := range slices.Sorted(maps.Keys(profiles)) {
+		if profiles[name].RoleARN == "" {
+			continue
+		}
+
+		if sourced[name] {
+			inRing = append(inRing, name)
+		} else {
+			tails = append(tails, name)
+		}
+	}
+
+	if len(tails) == 0 && len(inRing) > 0 {
+		return inRing[:1]
+	}
+
+	return tails
+}
+
+// Trace is a chain recovered by following source_profile links.
+type Trace struct {
+	Chain *parser.Chain
+	// Region is the region of the profile the trace started from.
+	Region string
+	// Ring is set when the links close on themselves, so every hop including the wrap-around is configured.
+	Ring bool
+	// Source is the credentials profile the chain is entered from, empty for a closed ring.
+	Source string
+	// Branch lists, in assume order, the profiles the walk passed before it reached the ring. They hang off the
+	// ring rather than belong to it, so they are left out of Chain.
+	Branch []string
+}
+
+// Follow walks source_profile links backwards from the profile named tail and returns the roles in assume order.
+// The walk stops at a profile without role_arn or one missing from the config file, whose credentials enter the
+// chain, or when it reaches a profile it has already seen, which means the links form a ring. Only the profiles
+// on that ring make up the chain; those walked on the way to it are reported as the Branch.
+func Follow(profiles map[string]CLIProfile, tail string) (*Trace, error) {
+	current, found := profiles[tail]
+	if !found {
+		return nil, fmt.Errorf("%w: %q", ErrProfileMissing, tail)
+	}
+
+	trace := &Trace{Chain: nil, Region: current.Region, Ring: false, Source: "", Branch: nil}
+	walked := make([]CLIProfile, 0)
+	seen := make(map[string]bool)
+
+	for {
+		if seen[current.Name] {
+			start := slices.IndexFunc(walked, func(profile CLIProfile) bool { return profile.Name == current.Name })
+
+			for _, profile := range slices.Backward(walked[:start]) {
+				trace.Branch = append(trace.Branch, profile.Name)
+			}
+
+			walked = walked[start:]
+			trace.Ring = true
+
+			break
+		}
+
+		if current.RoleARN == "" {
+			return nil, fmt.Errorf("%w: %q", ErrNoRoleProfile, current.Name)
+		}
+
+		seen[current.Name] = true
+		walked = append(walked, current)
+
+		if current.SourceProfile == "" {
+			break
+		}
+
+		// Profiles only defined in the shared credentials file hold static keys, just like those without role_arn.
+		source, found := profiles[current.SourceProfile]
+		if !found || source.RoleARN == "" {
+			trace.Source = current.SourceProfile
+
+			break
+		}
+
+		current = source
+	}
+
+	slices.Reverse(walked)
+
+	// A wrap-around hop exported as its own profile assumes the first role again.
+	if len(walked) > 1 && arn.WithoutPath(walked[len(walked)-1].RoleARN) == arn.WithoutPath(walked[0].RoleARN) {
+		walked = walked[:len(walked)-1]
+		trace.Ring = true
+	}
+
+	trace.Chain = &parser.Chain{TTL: 0, UseRoles: make([]*parser.UseRoles, 0, len(walked))}
+
+	for idx, profile := range walked {
+		trace.Chain.UseRoles = append(trace.Chain.UseRoles, &parser.UseRoles{
+			ARN:   profile.RoleARN,
+			Skip:  false,
+			Entry: idx == 0 && trace.Source != "",
+		})
+	}
+
+	return trace, nil
+}
+
+// Export renders one shared config profile per hop of the chain: the entry point sourced from sourceProfile, every
+// following role sourced from its predecessor, and the wrap-around hop from the last role back to the first.
+func Export(profile *parser.Profile, prefix, sourceProfile, sessionName string) string {
+	roles := profile.Chain.UseRoles
+	if entries := profile.Chain.EntryPoints(); len(entries) > 0 {
+		start := slices.Index(roles, entries[0])
+		roles = append(slices.Clone(roles[start:]), roles[:start]...)
+	}
+
+	var out strings.Builder
+
+	write := func(name, roleARN, source string) {
+		_, _ = fmt.Fprintf(&out, "[%s]\n", ConfigSection(name))
+		_, _ = fmt.Fprintf(&out, "role_arn = %s\n", roleARN)
+		_, _ = fmt.Fprintf(&out, "source_profile = %s\n", source)
+		_, _ = fmt.Fprintf(&out, "role_session_name = %s\n", sessionName)
+
+		if profile.Region != "" {
+			_, _ = fmt.Fprintf(&out, "region = %s\n", profile.Region)
+		}
+
+		out.WriteString("\n")
+	}
+
+	previous := sourceProfile
+
+	for _, role := range roles {
+		name := prefix + arn.Name(role.ARN)
+		write(name, role.ARN, previous)
+		previous = name
+	}
+
+	if len(roles) > 1 {
+		write(prefix+arn.Name(roles[0].ARN)+"-wrap", roles[0].ARN, previous)
+	}
+
+	return strings.TrimSuffix(out.String(), "\n")
+}
@@ -0,0 +1,210 @@
+// Copyright 2025 variHQ OÜ
+// SPDX-License-Identifier: BSD-3-Clause
+
+package awsconfig_test
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/wakeful/trick/internal/awsconfig"
+	"github.com/wakeful/trick/internal/parser"
+)
+
+const linkedConfig = `[default]
+region = eu-west-1
+
+; a linear chain entered from the default credentials
+[profile hop-a]
+role_arn = arn:aws:iam::123456789012:role/role-a
+source_profile = default
+
+[profile hop-b]
+role_arn       = arn:aws:iam::123456789012:role/role-b
+source_profile = hop-a
+region         = us-east-1
+
+# a closed ring
+[profile ring-x]
+role_arn = arn:aws:iam::123456789012:role/role-x
+source_profile = ring-y
+
+[profile ring-y]
+role_arn = arn:aws:iam::123456789012:role/role-y
+source_profile = ring-x
+
+[profile credentials-only]
+role_arn = arn:aws:iam::123456789012:role/role-z
+source_profile = static-keys
+`
+
+func arns(chain *parser.Chain) []string {
+	out := make([]string, 0, len(chain.UseRoles))
+	for _, role := range chain.UseRoles {
+		out = append(out, role.ARN)
+	}
+
+	return out
+}
+
+func TestFollow(t *testing.T) {
+	t.Parallel()
+
+	profiles, err := awsconfig.ReadProfiles(strings.NewReader(linkedConfig))
+	if err != nil {
+		t.Fatalf("ReadProfiles() error = %v", err)
+	}
+
+	if got := awsconfig.Tails(profiles); !reflect.DeepEqual(got, []string{"credentials-only", "hop-b"}) {
+		t.Errorf("Tails() = %v, want [credentials-only hop-b]", got)
+	}
+
+	trace, err := awsconfig.Follow(profiles, "hop-b")
+	if err != nil {
+		t.Fatalf("Follow() error = %v", err)
+	}
+
+	want := []string{"arn:aws:iam::123456789012:role/role-a", "arn:aws:iam::123456789012:role/role-b"}
+	if got := arns(trace.Chain); !reflect.DeepEqual(got, want) {
+		t.Errorf("Follow() roles = %v, want %v", got, want)
+	}
+
+	if trace.Ring || trace.Source != "default" || trace.Region != "us-east-1" || !trace.Chain.UseRoles[0].Entry {
+		t.Errorf("Follow() = %+v, want a linear chain entered from default", trace)
+	}
+
+	trace, err = awsconfig.Follow(profiles, "ring-x")
+	if err != nil {
+		t.Fatalf("Follow() error = %v", err)
+	}
+
+	want = []string{"arn:aws:iam::123456789012:role/role-y", "arn:aws:iam::123456789012:role/role-x"}
+	if got := arns(trace.Chain); !trace.Ring || !reflect.DeepEqual(got, want) {
+		t.Errorf("Follow() = %v (ring %v), want ring %v", got, trace.Ring, want)
+	}
+
+	trace, err = awsconfig.Follow(profiles, "credentials-only")
+	if err != nil {
+		t.Fatalf("Follow() error = %v", err)
+	}
+
+	if trace.Ring || trace.Source != "static-keys" || len(trace.Chain.UseRoles) != 1 {
+		t.Errorf("Follow() = %+v, want a chain entered from the credentials file", trace)
+	}
+
+	_, err = awsconfig.Follow(profiles, "missing")
+	if !errors.Is(err, awsconfig.ErrProfileMissing) {
+		t.Errorf("Follow() error = %v, want %v", err, awsconfig.ErrProfileMissing)
+	}
+
+	_, err = awsconfig.Follow(profiles, "default")
+	if !errors.Is(err, awsconfig.ErrNoRoleProfile) {
+		t.Errorf("Follow() error = %v, want %v", err, awsconfig.ErrNoRoleProfile)
+	}
+}
+
+func TestFollowBranch(t *testing.T) {
+	t.Parallel()
+
+	// ring-a, ring-b and ring-c form a ring, the outside profiles lead into it.
+	profiles, err := awsconfig.ReadProfiles(strings.NewReader(`[profile outside-tail]
+role_arn = arn:aws:iam::123456789012:role/tail
+source_profile = outside-hop
+
+[profile outside-hop]
+role_arn = arn:aws:iam::123456789012:role/hop
+source_profile = ring-a
+
+[profile ring-a]
+role_arn = arn:aws:iam::123456789012:role/role-a
+source_profile = ring-c
+
+[profile ring-b]
+role_arn = arn:aws:iam::123456789012:role/role-b
+source_profile = ring-a
+
+[profile ring-c]
+role_arn = arn:aws:iam::123456789012:role/role-c
+source_profile = ring-b
+`))
+	if err != nil {
+		t.Fatalf("ReadProfiles() error = %v", err)
+	}
+
+	trace, err := awsconfig.Follow(profiles, "outside-tail")
+	if err != nil {
+		t.Fatalf("Follow() error = %v", err)
+	}
+
+	want := []string{
+		"arn:aws:iam::123456789012:role/role-b",
+		"arn:aws:iam::123456789012:role/role-c",
+		"arn:aws:iam::123456789012:role/role-a",
+	}
+	if got := arns(trace.Chain); !trace.Ring || !reflect.DeepEqual(got, want) {
+		t.Errorf("Follow() = %v (ring %v), want ring %v", got, trace.Ring, want)
+	}
+
+	if want := []string{"outside-hop", "outside-tail"}; !reflect.DeepEqual(trace.Branch, want) {
+		t.Errorf("Follow() branch = %v, want %v", trace.Branch, want)
+	}
+
+	trace, err = awsconfig.Follow(profiles, "ring-a")
+	if err != nil {
+		t.Fatalf("Follow() error = %v", err)
+	}
+
+	if len(trace.Branch) != 0 || len(trace.Chain.UseRoles) != 3 {
+		t.Errorf("Follow() from the ring = %v, branch %v", arns(trace.Chain), trace.Branch)
+	}
+}
+
+func TestExport(t *testing.T) {
+	t.Parallel()
+
+	profile := &parser.Profile{
+		Name:   "ring",
+		Region: "eu-west-1",
+		Chain: &parser.Chain{
+			TTL: 12,
+			UseRoles: []*parser.UseRoles{
+				{ARN: "arn:aws:iam::123456789012:role/role-a"},
+				{ARN: "arn:aws:iam::123456789012:role/role-b", Entry: true},
+				{ARN: "arn:aws:iam::123456789012:role/role-c"},
+			},
+		},
+	}
+
+	exported := awsconfig.Export(profile, "trick-", "default", "trick")
+
+	if !strings.HasPrefix(exported, "[profile trick-role-b]\nrole_arn = arn:aws:iam::123456789012:role/role-b\n"+
+		"source_profile = default\n") {
+		t.Errorf("Export() does not start at the entry point:\n%s", exported)
+	}
+
+	profiles, err := awsconfig.ReadProfiles(strings.NewReader(exported))
+	if err != nil {
+		t.Fatalf("ReadProfiles() error = %v", err)
+	}
+
+	tails := awsconfig.Tails(profiles)
+	if !reflect.DeepEqual(tails, []string{"trick-role-b-wrap"}) {
+		t.Fatalf("Tails() = %v, want the wrap-around hop", tails)
+	}
+
+	trace, err := awsconfig.Follow(profiles, tails[0])
+	if err != nil {
+		t.Fatalf("Follow() error = %v", err)
+	}
+
+	want := []string{
+		"arn:aws:iam::123456789012:role/role-b",
+		"arn:aws:iam::123456789012:role/role-c",
+		"arn:aws:iam::123456789012:role/role-a",
+	}
+	if got := arns(trace.Chain); !trace.Ring || !reflect.DeepEqual(got, want) {
+		t.Errorf("Follow(Export()) = %v (ring %v), want ring %v", got, trace.Ring, want)
+	}
+}
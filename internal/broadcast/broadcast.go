@@ -4,91 +4,352 @@
 package broadcast
 
 import (
+	"encoding/json"
+	"errors"
 	"log/slog"
+	"slices"
+	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
+	"time"
 )
 
+// Version is the schema version of the JSON payload sent with every event.
+// Version 1 was the plain-text "jump" event carrying only the chain and role.
+const Version = 2
+
+const (
+	// EventJump is published after a successful hop; its role is the one now held.
+	EventJump = "jump"
+	// EventAssumeStarted is published right before AssumeRole is called for a hop.
+	EventAssumeStarted = "assume-started"
+	// EventAssumeFailed is published when AssumeRole returns an error.
+	EventAssumeFailed = "assume-failed"
+	// EventSkippedTransit is published when a hop lands on a role without meaningful permissions and the chain moves on.
+	EventSkippedTransit = "skipped-transit"
+	// EventCredentialsWritten is published after the output profile was updated.
+	EventCredentialsWritten = "credentials-written"
+	// EventExpiryWarning is published when the written credentials expire before the next attempt to refresh them.
+	EventExpiryWarning = "expiry-warning"
+	// EventChainBroken is published when a tick fails.
+	EventChainBroken = "chain-broken"
+	// EventChainRecovered is published by the first successful tick after the chain broke.
+	EventChainRecovered = "chain-recovered"
+	// EventConfigReloaded is published after the chain was rebuilt from a reloaded config.
+	EventConfigReloaded = "config-reloaded"
+	// EventChainUpdated is published when the drawing of the chain changes: it was reloaded, paused or resumed, or a
+	// hop was pinned, disabled or turned unhealthy or healthy again.
+	EventChainUpdated = "chain-updated"
+)
+
+// NodeID returns the ID of the node that stands for hop in the diagram of a ring.
+func NodeID(hop int) string {
+	return "r" + strconv.Itoa(hop)
+}
+
+// HistorySize is the number of past messages a Broadcaster keeps for replay.
+const HistorySize = 256
+
+// Message is a single event sent to subscribers and, as JSON, to SSE clients.
 type Message struct {
-	Chain string
-	Role  string
+	// ID increases by one with every published message, starting at 1
+	ID      uint64    `json:"id"`
+	Version int       `json:"version"`
+	Event   string    `json:"event"`
+	Time    time.Time `json:"time"`
+	Chain   string    `json:"chain"`
+	Role    string    `json:"role,omitempty"`
+	// Hop is the position of Role in the ring, starting at 0
+	Hop int `json:"hop"`
+	// Node is the ID of the diagram node of Hop, see NodeID
+	Node         string     `json:"node,omitempty"`
+	PreviousRole string     `json:"previous_role,omitempty"`
+	Expiration   *time.Time `json:"expiration,omitempty"`
+	Class        string     `json:"class,omitempty"`
+	Error        string     `json:"error,omitempty"`
+	// DurationMS is how long the reported step took, or how long the chain was broken for chain-recovered
+	DurationMS int64 `json:"duration_ms,omitempty"`
 }
 
+// String formats the message as a server-sent event named after Event with a JSON data payload.
+// Published messages also carry their ID, which browsers send back as Last-Event-ID when they reconnect.
 func (m *Message) String() string {
+	data, err := json.Marshal(m)
+	if err != nil {
+		slog.Error("failed to encode event", slog.String("event", m.Event), slog.String("error", err.Error()))
+
+		data = []byte("{}")
+	}
+
 	var builder strings.Builder
-	builder.WriteString("event: jump\n")
-	builder.WriteString("data: chain: ")
-	builder.WriteString(m.Chain)
+
+	if m.ID != 0 {
+		builder.WriteString("id: ")
+		builder.WriteString(strconv.FormatUint(m.ID, 10))
+		builder.WriteString("\n")
+	}
+
+	builder.WriteString("event: ")
+	builder.WriteString(m.Event)
 	builder.WriteString("\n")
-	builder.WriteString("data: role: ")
-	builder.WriteString(m.Role)
+	builder.WriteString("data: ")
+	builder.Write(data)
 	builder.WriteString("\n\n")
 
 	return builder.String()
 }
 
+// DefaultBuffer is the number of messages a subscriber can fall behind before messages are dropped for it.
+const DefaultBuffer = 10
+
+// ErrSlowConsumer is reported by a Subscription that PolicyDisconnect closed because its buffer was full.
+var ErrSlowConsumer = errors.New("slow consumer disconnected")
+
+// Policy decides what happens to a subscriber whose buffer is full when a message is published.
+type Policy string
+
+const (
+	// PolicyDrop drops the message for that subscriber and counts it.
+	PolicyDrop Policy = "drop"
+	// PolicyDisconnect closes the subscription, so the consumer notices the gap and can resubscribe from its last ID.
+	PolicyDisconnect Policy = "disconnect"
+)
+
+// Options configure a subscription. The zero value receives every message with the default buffer and PolicyDrop.
+type Options struct {
+	// Name identifies the subscriber in logs, e.g. the remote address of an SSE client
+	Name string
+	// Chains limits the subscription to these chains; empty matches every chain
+	Chains []string
+	// Events limits the subscription to these event types; empty matches every event
+	Events []string
+	// Buffer is the channel size; zero uses DefaultBuffer
+	Buffer int
+	// Policy applies when the buffer is full; empty uses PolicyDrop
+	Policy Policy
+	// LastID replays the matching kept messages published after it; zero replays only the last jump
+	LastID uint64
+}
+
+// Match reports whether msg passes the chain and event filters.
+func (o *Options) Match(msg Message) bool {
+	return (len(o.Chains) == 0 || slices.Contains(o.Chains, msg.Chain)) &&
+		(len(o.Events) == 0 || slices.Contains(o.Events, msg.Event))
+}
+
+// Subscription receives the published messages matching its Options on C.
+type Subscription struct {
+	// C is closed by Close, or when PolicyDisconnect removed a slow consumer
+	C <-chan Message
+
+	target      chan Message
+	opts        Options
+	broadcaster *Broadcaster
+	dropped     atomic.Uint64
+	slow        atomic.Bool
+}
+
+// Dropped returns how many messages were dropped because the subscriber's buffer was full.
+func (s *Subscription) Dropped() uint64 {
+	return s.dropped.Load()
+}
+
+// Err returns ErrSlowConsumer once the subscription was disconnected for falling behind, and nil otherwise.
+func (s *Subscription) Err() error {
+	if s.slow.Load() {
+		return ErrSlowConsumer
+	}
+
+	return nil
+}
+
+// Close unsubscribes and closes C; it is safe to call more than once.
+func (s *Subscription) Close() {
+	s.broadcaster.mu.Lock()
+	defer s.broadcaster.mu.Unlock()
+
+	s.broadcaster.remove(s)
+}
+
 type Broadcaster struct {
 	mu      sync.RWMutex
-	subs    map[chan Message]struct{}
+	subs    map[*Subscription]struct{}
 	lastMsg *Message
+	// history holds the last HistorySize published messages, oldest first
+	history []Message
+	lastID  uint64
+	now     func() time.Time
 }
 
 func NewBroadcaster() *Broadcaster {
 	return &Broadcaster{
 		mu:      sync.RWMutex{},
-		subs:    make(map[chan Message]struct{}),
+		subs:    make(map[*Subscription]struct{}),
 		lastMsg: nil,
+		history: make([]Message, 0, HistorySize),
+		lastID:  0,
+		now:     time.Now,
 	}
 }
 
+// Subscribe returns a channel receiving every message published from now on, starting with the last jump.
 func (b *Broadcaster) Subscribe() (<-chan Message, func()) {
-	const buffer = 10
+	sub := b.SubscribeWith(Options{}) //nolint:exhaustruct
+
+	return sub.C, sub.Close
+}
+
+// SubscribeFrom is Subscribe for a client that already saw every message up to lastID.
+// It replays the kept messages published after lastID instead of the last jump; when some of them were already
+// dropped from the history, it replays the whole history. A lastID ahead of every published message comes from a
+// client of an earlier run and is treated like no lastID at all.
+func (b *Broadcaster) SubscribeFrom(lastID uint64) (<-chan Message, func()) {
+	sub := b.SubscribeWith(Options{LastID: lastID}) //nolint:exhaustruct
 
-	target := make(chan Message, buffer)
+	return sub.C, sub.Close
+}
+
+// SubscribeWith registers a subscriber configured by opts, queueing its replay ahead of live messages.
+// The buffer grows to fit the replay.
+func (b *Broadcaster) SubscribeWith(opts Options) *Subscription {
+	if opts.Buffer <= 0 {
+		opts.Buffer = DefaultBuffer
+	}
+
+	if opts.Policy == "" {
+		opts.Policy = PolicyDrop
+	}
 
 	b.mu.Lock()
-	b.subs[target] = struct{}{}
+	defer b.mu.Unlock()
 
-	if b.lastMsg != nil {
-		select {
-		case target <- *b.lastMsg:
-			slog.Debug("sent current role to new subscriber")
-		default:
-			slog.Debug("failed to send current role to new subscriber")
-		}
+	replay := make([]Message, 0, 1)
+
+	switch {
+	case opts.LastID != 0 && opts.LastID <= b.lastID:
+		replay = b.since(opts.LastID)
+	case b.lastMsg != nil:
+		replay = append(replay, *b.lastMsg)
+	}
+
+	replay = slices.DeleteFunc(replay, func(msg Message) bool { return !opts.Match(msg) })
+
+	target := make(chan Message, max(opts.Buffer, len(replay)))
+	for _, msg := range replay {
+		target <- msg
 	}
 
-	b.mu.Unlock()
+	sub := &Subscription{ //nolint:exhaustruct
+		C:           target,
+		target:      target,
+		opts:        opts,
+		broadcaster: b,
+	}
+	b.subs[sub] = struct{}{}
+
+	slog.Debug("new subscriber", slog.String("subscriber", opts.Name), slog.Int("replayed", len(replay)))
+
+	return sub
+}
+
+// History returns the kept messages published after afterID, oldest first.
+func (b *Broadcaster) History(afterID uint64) []Message {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	return b.since(afterID)
+}
 
-	unsub := func() {
-		b.mu.Lock()
+func (b *Broadcaster) since(afterID uint64) []Message {
+	messages := make([]Message, 0, len(b.history))
 
-		if _, ok := b.subs[target]; ok {
-			delete(b.subs, target)
-			close(target)
+	for _, msg := range b.history {
+		if msg.ID > afterID {
+			messages = append(messages, msg)
 		}
+	}
+
+	return messages
+}
 
-		b.mu.Unlock()
+// remove unregisters sub and closes its channel unless that already happened; b.mu must be held.
+func (b *Broadcaster) remove(sub *Subscription) {
+	if _, ok := b.subs[sub]; !ok {
+		return
 	}
 
-	return target, unsub
+	delete(b.subs, sub)
+	close(sub.target)
+
+	if dropped := sub.Dropped(); dropped > 0 {
+		slog.Warn("subscriber missed messages",
+			slog.String("subscriber", sub.opts.Name),
+			slog.Uint64("dropped", dropped),
+		)
+	}
 }
 
+// Publish stamps msg with the next ID, the schema version, the node of its hop when it names a role and, when unset,
+// the current time, keeps it in the history and delivers it to every matching subscriber. The last jump is also sent
+// to new subscribers so they learn the current role. A subscriber with a full buffer loses the message, or is
+// disconnected under PolicyDisconnect.
 func (b *Broadcaster) Publish(msg Message) {
 	b.mu.Lock()
-	b.lastMsg = &msg
-	b.mu.Unlock()
+	defer b.mu.Unlock()
 
-	b.mu.RLock()
+	b.lastID++
+	msg.ID = b.lastID
+	msg.Version = Version
+
+	if msg.Role != "" {
+		msg.Node = NodeID(msg.Hop)
+	}
+
+	if msg.Time.IsZero() {
+		msg.Time = b.now().UTC()
+	}
+
+	if msg.Event == EventJump {
+		b.lastMsg = &msg
+	}
+
+	if len(b.history) == HistorySize {
+		b.history = append(b.history[:0], b.history[1:]...)
+	}
+
+	b.history = append(b.history, msg)
+
+	for sub := range b.subs {
+		if !sub.opts.Match(msg) {
+			continue
+		}
 
-	for ch := range b.subs {
 		select {
-		case ch <- msg:
+		case sub.target <- msg:
 			slog.Debug("message delivered to subscriber")
+
+			continue
 		default:
-			slog.Debug("message dropped")
 		}
-	}
 
-	b.mu.RUnlock()
+		if sub.opts.Policy == PolicyDisconnect {
+			slog.Warn("disconnecting slow subscriber",
+				slog.String("subscriber", sub.opts.Name),
+				slog.Uint64("id", msg.ID),
+			)
+
+			sub.slow.Store(true)
+			b.remove(sub)
+
+			continue
+		}
+
+		if sub.dropped.Add(1) == 1 {
+			slog.Warn("subscriber buffer full, dropping messages",
+				slog.String("subscriber", sub.opts.Name),
+				slog.Uint64("id", msg.ID),
+			)
+		}
+	}
 }
@@ -4,6 +4,7 @@
 package broadcast_test
 
 import (
+	"errors"
 	"testing"
 	"time"
 
@@ -13,26 +14,46 @@ import (
 func TestMessage_String(t *testing.T) {
 	t.Parallel()
 
+	expiration := time.Date(2025, 6, 1, 12, 15, 0, 0, time.UTC)
+
 	tests := []struct {
 		name     string
 		message  broadcast.Message
 		expected string
 	}{
 		{
-			name: "basic message",
-			message: broadcast.Message{
-				Chain: "test-chain",
-				Role:  "test-role",
+			name: "jump",
+			message: broadcast.Message{ //nolint:exhaustruct
+				ID:           7,
+				Version:      broadcast.Version,
+				Event:        broadcast.EventJump,
+				Time:         time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC),
+				Chain:        "test-chain",
+				Role:         "test-role",
+				Hop:          1,
+				PreviousRole: "entry-role",
+				Expiration:   &expiration,
+				DurationMS:   250,
 			},
-			expected: "event: jump\ndata: chain: test-chain\ndata: role: test-role\n\n",
+			expected: "id: 7\nevent: jump\n" +
+				`data: {"id":7,"version":2,"event":"jump","time":"2025-06-01T12:00:00Z","chain":"test-chain",` +
+				`"role":"test-role","hop":1,"previous_role":"entry-role","expiration":"2025-06-01T12:15:00Z",` +
+				`"duration_ms":250}` + "\n\n",
 		},
 		{
-			name: "empty message",
-			message: broadcast.Message{
-				Chain: "",
-				Role:  "",
+			name: "unpublished message without an id",
+			message: broadcast.Message{ //nolint:exhaustruct
+				Version: broadcast.Version,
+				Event:   broadcast.EventAssumeFailed,
+				Time:    time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC),
+				Chain:   "main",
+				Role:    "role-b",
+				Class:   "hop-denied",
+				Error:   "access denied",
 			},
-			expected: "event: jump\ndata: chain: \ndata: role: \n\n",
+			expected: "event: assume-failed\n" +
+				`data: {"id":0,"version":2,"event":"assume-failed","time":"2025-06-01T12:00:00Z","chain":"main",` +
+				`"role":"role-b","hop":0,"class":"hop-denied","error":"access denied"}` + "\n\n",
 		},
 	}
 
@@ -48,6 +69,54 @@ func TestMessage_String(t *testing.T) {
 	}
 }
 
+func TestBroadcaster_PublishStampsMessage(t *testing.T) {
+	t.Parallel()
+
+	b := broadcast.NewBroadcaster()
+
+	ch, unsub := b.Subscribe()
+	defer unsub()
+
+	b.Publish(broadcast.Message{Event: broadcast.EventChainBroken, Chain: "main", Role: "role-c", Hop: 12}) //nolint:exhaustruct
+
+	select {
+	case received := <-ch:
+		if received.Version != broadcast.Version {
+			t.Errorf("Version = %d, expected %d", received.Version, broadcast.Version)
+		}
+
+		if received.Time.IsZero() {
+			t.Error("Time was not stamped")
+		}
+
+		if received.Node != "r12" {
+			t.Errorf("Node = %q, expected r12", received.Node)
+		}
+	case <-time.After(100 * time.Millisecond):
+		t.Error("did not receive message within timeout")
+	}
+}
+
+func TestBroadcaster_SubscribeReplaysLastJump(t *testing.T) {
+	t.Parallel()
+
+	b := broadcast.NewBroadcaster()
+	b.Publish(broadcast.Message{Event: broadcast.EventJump, Chain: "main", Role: "role-a"})          //nolint:exhaustruct
+	b.Publish(broadcast.Message{Event: broadcast.EventCredentialsWritten, Chain: "main", Role: "x"}) //nolint:exhaustruct
+
+	ch, unsub := b.Subscribe()
+	defer unsub()
+
+	select {
+	case received := <-ch:
+		if received.Event != broadcast.EventJump || received.Role != "role-a" {
+			t.Errorf("received %+v, expected the last jump", received)
+		}
+	case <-time.After(100 * time.Millisecond):
+		t.Error("did not receive the last jump within timeout")
+	}
+}
+
 func TestBroadcaster_Publish(t *testing.T) {
 	t.Parallel()
 
@@ -120,3 +189,159 @@ func TestBroadcaster_PublishWithFullBuffer(t *testing.T) {
 	case <-time.After(50 * time.Millisecond):
 	}
 }
+
+func TestBroadcaster_SubscribeFrom(t *testing.T) {
+	t.Parallel()
+
+	b := broadcast.NewBroadcaster()
+
+	for range broadcast.HistorySize + 4 {
+		b.Publish(broadcast.Message{Event: broadcast.EventJump, Chain: "main"}) //nolint:exhaustruct
+	}
+
+	tests := []struct {
+		name      string
+		lastID    uint64
+		wantFirst uint64
+		wantCount int
+	}{
+		{name: "caught up", lastID: broadcast.HistorySize + 4, wantFirst: 0, wantCount: 0},
+		{name: "missed two", lastID: broadcast.HistorySize + 2, wantFirst: broadcast.HistorySize + 3, wantCount: 2},
+		{name: "older than the history", lastID: 1, wantFirst: 5, wantCount: broadcast.HistorySize},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			history := b.History(tt.lastID)
+			if len(history) != tt.wantCount {
+				t.Fatalf("History(%d) returned %d messages, expected %d", tt.lastID, len(history), tt.wantCount)
+			}
+
+			ch, unsub := b.SubscribeFrom(tt.lastID)
+			defer unsub()
+
+			for i := range tt.wantCount {
+				received := <-ch
+				if want := tt.wantFirst + uint64(i); received.ID != want { //nolint:gosec
+					t.Errorf("replayed message %d has ID %d, expected %d", i, received.ID, want)
+				}
+			}
+
+			select {
+			case msg := <-ch:
+				t.Errorf("replayed unexpected message: %+v", msg)
+			default:
+			}
+		})
+	}
+}
+
+func TestBroadcaster_SubscribeFromEarlierRun(t *testing.T) {
+	t.Parallel()
+
+	b := broadcast.NewBroadcaster()
+	b.Publish(broadcast.Message{Event: broadcast.EventJump, Chain: "main", Role: "role-a"}) //nolint:exhaustruct
+	b.Publish(broadcast.Message{Event: broadcast.EventCredentialsWritten, Chain: "main"})   //nolint:exhaustruct
+
+	ch, unsub := b.SubscribeFrom(42)
+	defer unsub()
+
+	select {
+	case received := <-ch:
+		if received.Event != broadcast.EventJump || received.Role != "role-a" {
+			t.Errorf("replayed %+v, want the last jump", received)
+		}
+	default:
+		t.Fatal("the last jump was not replayed to a client of an earlier run")
+	}
+
+	select {
+	case msg := <-ch:
+		t.Errorf("replayed unexpected message: %+v", msg)
+	default:
+	}
+}
+
+func TestBroadcaster_SubscribeWithFilter(t *testing.T) {
+	t.Parallel()
+
+	b := broadcast.NewBroadcaster()
+
+	sub := b.SubscribeWith(broadcast.Options{ //nolint:exhaustruct
+		Chains: []string{"main"},
+		Events: []string{broadcast.EventAssumeFailed, broadcast.EventChainBroken},
+	})
+	defer sub.Close()
+
+	b.Publish(broadcast.Message{Event: broadcast.EventJump, Chain: "main"})          //nolint:exhaustruct
+	b.Publish(broadcast.Message{Event: broadcast.EventAssumeFailed, Chain: "other"}) //nolint:exhaustruct
+	b.Publish(broadcast.Message{Event: broadcast.EventChainBroken, Chain: "main"})   //nolint:exhaustruct
+
+	select {
+	case received := <-sub.C:
+		if received.Event != broadcast.EventChainBroken || received.ID != 3 {
+			t.Errorf("received %+v, expected chain-broken with ID 3", received)
+		}
+	case <-time.After(100 * time.Millisecond):
+		t.Fatal("did not receive the matching message within timeout")
+	}
+
+	select {
+	case msg := <-sub.C:
+		t.Errorf("received unexpected message: %+v", msg)
+	default:
+	}
+}
+
+func TestBroadcaster_SlowConsumerPolicy(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		policy      broadcast.Policy
+		wantDropped uint64
+		wantErr     error
+		wantOpen    bool
+	}{
+		{name: "drop", policy: broadcast.PolicyDrop, wantDropped: 3, wantErr: nil, wantOpen: true},
+		{name: "disconnect", policy: broadcast.PolicyDisconnect, wantDropped: 0, wantErr: broadcast.ErrSlowConsumer, wantOpen: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			b := broadcast.NewBroadcaster()
+
+			sub := b.SubscribeWith(broadcast.Options{Buffer: 2, Policy: tt.policy}) //nolint:exhaustruct
+			defer sub.Close()
+
+			for range 5 {
+				b.Publish(broadcast.Message{Event: broadcast.EventJump, Chain: "main"}) //nolint:exhaustruct
+			}
+
+			if got := sub.Dropped(); got != tt.wantDropped {
+				t.Errorf("Dropped() = %d, expected %d", got, tt.wantDropped)
+			}
+
+			if !errors.Is(sub.Err(), tt.wantErr) {
+				t.Errorf("Err() = %v, expected %v", sub.Err(), tt.wantErr)
+			}
+
+			received := 0
+			for range sub.C {
+				received++
+
+				if received == 2 && tt.wantOpen {
+					break
+				}
+			}
+
+			if received != 2 {
+				t.Errorf("received %d buffered messages, expected 2", received)
+			}
+		})
+	}
+}
@@ -0,0 +1,243 @@
+// Copyright 2025 variHQ OÜ
+// SPDX-License-Identifier: BSD-3-Clause
+
+package detect
+
+import (
+	"crypto/sha1" //nolint:gosec // SHA-1 is what UUIDv5 is defined with.
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"text/template"
+
+	"github.com/wakeful/trick/internal/arn"
+)
+
+// ErrShortChain is returned when a chain has fewer than two roles and therefore no ring to detect.
+var ErrShortChain = errors.New("a ring needs at least two roles")
+
+// Chain describes the ring trick builds and therefore what defenders should look for.
+type Chain struct {
+	// Name is the profile name, used in rule titles and identifiers.
+	Name string
+	// Roles are the role ARNs in ring order.
+	Roles []string
+	// SessionName is the RoleSessionName trick passes to AssumeRole.
+	SessionName string
+	// TTL is the number of minutes between jumps.
+	TTL int64
+}
+
+// Edge is a single AssumeRole hop of the ring, from the session of Source into Target.
+type Edge struct {
+	Index         int
+	Source        string
+	SourceSession string
+	Target        string
+}
+
+// Edges returns every hop of the ring in order, including the wrap-around from the last role to the first.
+func (c Chain) Edges() ([]Edge, error) {
+	const minRoles = 2
+	if len(c.Roles) < minRoles {
+		return nil, ErrShortChain
+	}
+
+	edges := make([]Edge, 0, len(c.Roles))
+
+	for idx, target := range c.Roles {
+		source := c.Roles[(idx+len(c.Roles)-1)%len(c.Roles)]
+
+		session, err := arn.AssumedRole(source, c.SessionName)
+		if err != nil {
+			return nil, fmt.Errorf("hop %d: %w", idx, err)
+		}
+
+		edges = append(edges, Edge{
+			Index:         idx,
+			Source:        source,
+			SourceSession: session,
+			Target:        target,
+		})
+	}
+
+	return edges, nil
+}
+
+// uuid derives a stable UUIDv5-style identifier so regenerated rules keep their IDs.
+func uuid(parts ...string) string {
+	sum := sha1.Sum([]byte("trick\x00" + strings.Join(parts, "\x00"))) //nolint:gosec
+
+	const (
+		version = 0x50
+		variant = 0x80
+	)
+
+	sum[6] = (sum[6] & 0x0f) | version
+	sum[8] = (sum[8] & 0x3f) | variant
+
+	return fmt.Sprintf("%x-%x-%x-%x-%x", sum[0:4], sum[4:6], sum[6:8], sum[8:10], sum[10:16])
+}
+
+type templateData struct {
+	Chain
+
+	Edges          []Edge
+	Timespan       int64
+	EventDataStore string
+	AthenaTable    string
+}
+
+func (c Chain) data() (templateData, error) {
+	edges, err := c.Edges()
+	if err != nil {
+		return templateData{}, err
+	}
+
+	return templateData{
+		Chain:          c,
+		Edges:          edges,
+		Timespan:       int64(len(c.Roles)+1) * c.TTL,
+		EventDataStore: "",
+		AthenaTable:    "",
+	}, nil
+}
+
+func render(name, source string, data any) (string, error) {
+	tmpl, err := template.New(name).Funcs(template.FuncMap{
+		"uuid": uuid,
+		"slug": func(value string) string {
+			return strings.Map(func(r rune) rune {
+				if r >= 'a' && r <= 'z' || r >= '0' && r <= '9' {
+					return r
+				}
+
+				if r >= 'A' && r <= 'Z' {
+					return r + ('a' - 'A')
+				}
+
+				return '_'
+			}, value)
+		},
+		"name": arn.Name,
+	}).Parse(source)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse %s template: %w", name, err)
+	}
+
+	var out strings.Builder
+
+	err = tmpl.Execute(&out, data)
+	if err != nil {
+		return "", fmt.Errorf("failed to execute %s template: %w", name, err)
+	}
+
+	return out.String(), nil
+}
+
+//go:embed templates/sigma.yml.tmpl
+var sigmaTemplate string
+
+//go:embed templates/cloudtrail-lake.sql.tmpl
+var cloudTrailLakeTemplate string
+
+//go:embed templates/athena.sql.tmpl
+var athenaTemplate string
+
+// Sigma returns one Sigma rule per hop, a ring-wide rule and a temporal_ordered correlation rule that fires
+// when the hops happen in ring order, as a multi-document YAML stream.
+func (c Chain) Sigma() (string, error) {
+	data, err := c.data()
+	if err != nil {
+		return "", err
+	}
+
+	return render("sigma", sigmaTemplate, data)
+}
+
+// CloudTrailLake returns a CloudTrail Lake SQL query for the ring's AssumeRole hops.
+func (c Chain) CloudTrailLake(eventDataStore string) (string, error) {
+	data, err := c.data()
+	if err != nil {
+		return "", err
+	}
+
+	data.EventDataStore = eventDataStore
+
+	return render("cloudtrail-lake", cloudTrailLakeTemplate, data)
+}
+
+// Athena returns an Athena SQL query over the standard CloudTrail table for the ring's AssumeRole hops.
+func (c Chain) Athena(table string) (string, error) {
+	data, err := c.data()
+	if err != nil {
+		return "", err
+	}
+
+	data.AthenaTable = table
+
+	return render("athena", athenaTemplate, data)
+}
+
+// Rule is a named EventBridge event pattern.
+type Rule struct {
+	Name         string         `json:"Name"`
+	Description  string         `json:"Description"`
+	EventPattern map[string]any `json:"EventPattern"`
+}
+
+func pattern(detail map[string]any) map[string]any {
+	detail["eventSource"] = []string{"sts.amazonaws.com"}
+	detail["eventName"] = []string{"AssumeRole"}
+
+	return map[string]any{
+		"source":      []string{"aws.sts"},
+		"detail-type": []string{"AWS API Call via CloudTrail"},
+		"detail":      detail,
+	}
+}
+
+// EventBridge returns EventBridge rules: one matching any hop into the ring and one per hop.
+func (c Chain) EventBridge() (string, error) {
+	edges, err := c.Edges()
+	if err != nil {
+		return "", err
+	}
+
+	rules := []Rule{{
+		Name:        "trick-" + c.Name + "-ring",
+		Description: "AssumeRole into any member of the trick ring " + c.Name,
+		EventPattern: pattern(map[string]any{
+			"requestParameters": map[string]any{
+				"roleArn":         c.Roles,
+				"roleSessionName": []string{c.SessionName},
+			},
+		}),
+	}}
+
+	for _, edge := range edges {
+		rules = append(rules, Rule{
+			Name: fmt.Sprintf("trick-%s-hop-%d", c.Name, edge.Index),
+			Description: fmt.Sprintf(
+				"trick ring %s hop %d: %s assumes %s",
+				c.Name, edge.Index, arn.Name(edge.Source), arn.Name(edge.Target),
+			),
+			EventPattern: pattern(map[string]any{
+				"userIdentity": map[string]any{"arn": []string{edge.SourceSession}},
+				"requestParameters": map[string]any{
+					"roleArn":         []string{edge.Target},
+					"roleSessionName": []string{c.SessionName},
+				},
+			}),
+		})
+	}
+
+	out, err := json.MarshalIndent(rules, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to encode EventBridge rules: %w", err)
+	}
+
+	return string(out) + "\n", nil
+}
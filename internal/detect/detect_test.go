@@ -0,0 +1,124 @@
+// Copyright 2025 variHQ OÜ
+// SPDX-License-Identifier: BSD-3-Clause
+
+package detect_test
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/wakeful/trick/internal/detect"
+)
+
+func chain() detect.Chain {
+	return detect.Chain{
+		Name: "simple",
+		Roles: []string{
+			"arn:aws:iam::123456789012:role/trick-role-a",
+			"arn:aws:iam::123456789012:role/trick-role-b",
+			"arn:aws:iam::123456789012:role/trick-role-c",
+		},
+		SessionName: "trick",
+		TTL:         12,
+	}
+}
+
+func TestChain_Edges(t *testing.T) {
+	t.Parallel()
+
+	edges, err := chain().Edges()
+	if err != nil {
+		t.Fatalf("Edges() error = %v", err)
+	}
+
+	if len(edges) != 3 {
+		t.Fatalf("Edges() = %d edges, want 3", len(edges))
+	}
+
+	wrap := edges[0]
+	if wrap.SourceSession != "arn:aws:sts::123456789012:assumed-role/trick-role-c/trick" ||
+		wrap.Target != "arn:aws:iam::123456789012:role/trick-role-a" {
+		t.Errorf("Edges() wrap-around = %+v", wrap)
+	}
+
+	_, err = detect.Chain{Roles: []string{"arn:aws:iam::1:role/a"}}.Edges()
+	if !errors.Is(err, detect.ErrShortChain) {
+		t.Errorf("Edges() error = %v, want %v", err, detect.ErrShortChain)
+	}
+}
+
+func TestChain_Sigma(t *testing.T) {
+	t.Parallel()
+
+	got, err := chain().Sigma()
+	if err != nil {
+		t.Fatalf("Sigma() error = %v", err)
+	}
+
+	if docs := strings.Count(got, "\n---\n"); docs != 4 {
+		t.Errorf("Sigma() has %d separators, want 4", docs)
+	}
+
+	for _, want := range []string{
+		"userIdentity.arn: 'arn:aws:sts::123456789012:assumed-role/trick-role-b/trick'",
+		"requestParameters.roleArn: 'arn:aws:iam::123456789012:role/trick-role-c'",
+		"type: temporal_ordered",
+		"        - trick_simple_hop_2\n",
+		"timespan: 48m",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("Sigma() missing %q", want)
+		}
+	}
+
+	again, _ := chain().Sigma()
+	if again != got {
+		t.Error("Sigma() should be deterministic")
+	}
+}
+
+func TestChain_SQL(t *testing.T) {
+	t.Parallel()
+
+	lake, err := chain().CloudTrailLake("eds-1234")
+	if err != nil {
+		t.Fatalf("CloudTrailLake() error = %v", err)
+	}
+
+	athena, err := chain().Athena("cloudtrail_logs")
+	if err != nil {
+		t.Fatalf("Athena() error = %v", err)
+	}
+
+	for _, want := range []string{"FROM\n    eds-1234", "OR (userIdentity.arn = 'arn:aws:sts::123456789012:assumed-role/trick-role-a/trick'"} {
+		if !strings.Contains(lake, want) {
+			t.Errorf("CloudTrailLake() missing %q", want)
+		}
+	}
+
+	if !strings.Contains(athena, "FROM\n    cloudtrail_logs") || strings.Count(athena, "useridentity.arn = ") != 3 {
+		t.Errorf("Athena() = %s", athena)
+	}
+}
+
+func TestChain_EventBridge(t *testing.T) {
+	t.Parallel()
+
+	got, err := chain().EventBridge()
+	if err != nil {
+		t.Fatalf("EventBridge() error = %v", err)
+	}
+
+	var rules []detect.Rule
+
+	err = json.Unmarshal([]byte(got), &rules)
+	if err != nil {
+		t.Fatalf("EventBridge() returned invalid JSON: %v", err)
+	}
+
+	if len(rules) != 4 || rules[1].Name != "trick-simple-hop-0" {
+		t.Errorf("EventBridge() rules = %+v", rules)
+	}
+}
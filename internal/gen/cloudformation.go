@@ -0,0 +1,94 @@
+// Copyright 2025 variHQ OÜ
+// SPDX-License-Identifier: BSD-3-Clause
+
+package gen
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// logicalID turns a role name into a CloudFormation logical ID, which must be alphanumeric.
+func logicalID(prefix, roleName string) string {
+	var out strings.Builder
+
+	out.WriteString(prefix)
+
+	upper := true
+
+	for _, r := range roleName {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			upper = true
+
+			continue
+		}
+
+		if upper {
+			r = unicode.ToUpper(r)
+			upper = false
+		}
+
+		out.WriteRune(r)
+	}
+
+	return out.String()
+}
+
+// CloudFormation renders a JSON CloudFormation template with an AWS::IAM::Role per ring member, each carrying
+// its trust policy and an inline policy for its successor, and an output per role ARN.
+func (c Chain) CloudFormation() (string, error) {
+	members, err := c.members()
+	if err != nil {
+		return "", err
+	}
+
+	err = sameAccount(members)
+	if err != nil {
+		return "", err
+	}
+
+	generated, err := generate(members)
+	if err != nil {
+		return "", err
+	}
+
+	resources := make(map[string]any, len(generated))
+	outputs := make(map[string]any, len(generated))
+
+	for _, member := range generated {
+		id := logicalID("Role", member.RoleName)
+
+		resources[id] = map[string]any{
+			"Type": "AWS::IAM::Role",
+			"Properties": map[string]any{
+				"RoleName":                 member.RoleName,
+				"Path":                     member.Path,
+				"AssumeRolePolicyDocument": member.Trust,
+				"Policies": []map[string]any{{
+					"PolicyName":     member.RoleName,
+					"PolicyDocument": member.Permissions,
+				}},
+			},
+		}
+
+		outputs[logicalID("RoleArn", member.RoleName)] = map[string]any{
+			"Value": map[string]any{"Fn::GetAtt": []string{id, "Arn"}},
+		}
+	}
+
+	template := map[string]any{
+		"AWSTemplateFormatVersion": "2010-09-09",
+		"Description":              fmt.Sprintf("trick ring %s generated by trick gen cloudformation", c.Name),
+		"Resources":                resources,
+		"Outputs":                  outputs,
+	}
+
+	out, err := json.MarshalIndent(template, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to encode CloudFormation template: %w", err)
+	}
+
+	return string(out) + "\n", nil
+}
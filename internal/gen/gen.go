@@ -0,0 +1,142 @@
+// Copyright 2025 variHQ OÜ
+// SPDX-License-Identifier: BSD-3-Clause
+
+package gen
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"text/template"
+
+	"github.com/wakeful/trick/internal/arn"
+)
+
+var (
+	// ErrShortChain is returned when a chain has fewer than two roles.
+	ErrShortChain = errors.New("a ring needs at least two roles")
+	// ErrMixedAccounts is returned when the ring spans accounts, which the generated code cannot express.
+	ErrMixedAccounts = errors.New("every role of the ring must live in the same account")
+	// ErrRoleName is returned when a role name does not carry the prefix the generated roles use.
+	ErrRoleName = errors.New("role name must start with " + RolePrefix)
+)
+
+// RolePrefix is the name prefix of every role built by example/modules/role.
+const RolePrefix = "trick-role-"
+
+// Role is one member of the ring.
+type Role struct {
+	ARN string
+	// Entry marks a role that trusts the account root, so the chain can be entered from outside the ring.
+	Entry bool
+}
+
+// Chain is the ring to generate infrastructure for.
+type Chain struct {
+	// Name is the profile name.
+	Name   string
+	Region string
+	// Roles are the ring members in order.
+	Roles []Role
+}
+
+// member is a ring role with its neighbours.
+type member struct {
+	// Name is the role name without RolePrefix, as example/modules/role takes it.
+	Name string
+	// RoleName is the full IAM role name.
+	RoleName string
+	ARN      string
+	// Path is the IAM path of the role, "/" unless the ARN carries one.
+	Path    string
+	Account string
+	// Partition is the ARN partition, e.g. aws.
+	Partition string
+	From      string
+	FromARN   string
+	// FromAccount is the predecessor's account, which differs from Account in cross-account rings.
+	FromAccount string
+	To          string
+	ToARN       string
+	Start       bool
+	// Label is Name made safe for a Terraform block label, unique within the ring.
+	Label string
+}
+
+// members resolves every role's predecessor and successor, wrapping around at both ends.
+func (c Chain) members() ([]member, error) {
+	const minRoles = 2
+	if len(c.Roles) < minRoles {
+		return nil, ErrShortChain
+	}
+
+	members := make([]member, 0, len(c.Roles))
+
+	for _, role := range c.Roles {
+		parsed, err := arn.Parse(role.ARN)
+		if err != nil {
+			return nil, fmt.Errorf("role %q: %w", role.ARN, err)
+		}
+
+		roleName := arn.Name(role.ARN)
+
+		members = append(members, member{ //nolint:exhaustruct
+			Name:      strings.TrimPrefix(roleName, RolePrefix),
+			RoleName:  roleName,
+			ARN:       role.ARN,
+			Path:      rolePath(parsed.Resource),
+			Account:   parsed.Account,
+			Partition: parsed.Partition,
+			Start:     role.Entry,
+		})
+	}
+
+	for idx := range members {
+		from := members[(idx+len(members)-1)%len(members)]
+		to := members[(idx+1)%len(members)]
+
+		members[idx].From, members[idx].FromARN, members[idx].FromAccount = from.Name, from.ARN, from.Account
+		members[idx].To, members[idx].ToARN = to.Name, to.ARN
+	}
+
+	return members, nil
+}
+
+// rolePath returns the IAM path of a role resource such as role/path/name.
+func rolePath(resource string) string {
+	name := strings.TrimPrefix(resource, "role/")
+
+	idx := strings.LastIndex(name, "/")
+	if idx < 0 {
+		return "/"
+	}
+
+	return "/" + name[:idx+1]
+}
+
+// sameAccount checks that the ring can be deployed into a single account.
+func sameAccount(members []member) error {
+	for _, member := range members {
+		if member.Account != members[0].Account {
+			return fmt.Errorf("%w: %s and %s", ErrMixedAccounts, members[0].Account, member.Account)
+		}
+	}
+
+	return nil
+}
+
+func render(name, source string, data any) (string, error) {
+	tmpl, err := template.New(name).Parse(source)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse %s template: %w", name, err)
+	}
+
+	var out strings.Builder
+
+	err = tmpl.Execute(&out, data)
+	if err != nil {
+		return "", fmt.Errorf("failed to execute %s template: %w", name, err)
+	}
+
+	return out.String(), nil
+}
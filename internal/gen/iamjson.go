@@ -0,0 +1,54 @@
+// Copyright 2025 variHQ OÜ
+// SPDX-License-Identifier: BSD-3-Clause
+
+package gen
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/wakeful/trick/internal/iam"
+)
+
+// IAMJSON renders the policy pairs in the shape of `aws iam get-account-authorization-details`, so the output can be
+// applied with any tooling and checked again with trick lint-policies.
+func (c Chain) IAMJSON() (string, error) {
+	generated, err := c.Members()
+	if err != nil {
+		return "", err
+	}
+
+	type inline struct {
+		PolicyName     string      `json:"PolicyName"`
+		PolicyDocument *iam.Policy `json:"PolicyDocument"`
+	}
+
+	type role struct {
+		Path                     string      `json:"Path"`
+		RoleName                 string      `json:"RoleName"`
+		Arn                      string      `json:"Arn"`
+		AssumeRolePolicyDocument *iam.Policy `json:"AssumeRolePolicyDocument"`
+		RolePolicyList           []inline    `json:"RolePolicyList"`
+	}
+
+	details := struct {
+		RoleDetailList []role `json:"RoleDetailList"`
+	}{RoleDetailList: make([]role, 0, len(generated))}
+
+	for _, member := range generated {
+		details.RoleDetailList = append(details.RoleDetailList, role{
+			Path:                     member.Path,
+			RoleName:                 member.RoleName,
+			Arn:                      member.ARN,
+			AssumeRolePolicyDocument: member.Trust,
+			RolePolicyList:           []inline{{PolicyName: member.RoleName, PolicyDocument: member.Permissions}},
+		})
+	}
+
+	out, err := json.MarshalIndent(details, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to encode IAM policies: %w", err)
+	}
+
+	return string(out) + "\n", nil
+}
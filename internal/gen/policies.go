@@ -0,0 +1,116 @@
+// Copyright 2025 variHQ OÜ
+// SPDX-License-Identifier: BSD-3-Clause
+
+package gen
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/wakeful/trick/internal/arn"
+	"github.com/wakeful/trick/internal/iam"
+)
+
+// ErrOpenRing is returned when the generated policies would not let every role assume its successor.
+var ErrOpenRing = errors.New("generated policies do not close the ring")
+
+// Member is a ring role with the policy pair that wires it to its neighbours.
+type Member struct {
+	RoleName string
+	ARN      string
+	// Path is copied from member.Path.
+	Path string
+	// Trust admits the predecessor and, on entry points, the account root.
+	Trust *iam.Policy
+	// Permissions allow sts:AssumeRole on the successor only.
+	Permissions *iam.Policy
+}
+
+// trust mirrors example/modules/role: the predecessor is matched on aws:PrincipalArn under a "*" principal, so
+// the policy can be created before the predecessor role exists.
+func (m member) trust() *iam.Policy {
+	statements := make(iam.Statements, 0, 2) //nolint:mnd
+
+	if m.Start {
+		root := "arn:" + m.Partition + ":iam::" + m.Account + ":root"
+
+		statements = append(statements, iam.Statement{ //nolint:exhaustruct
+			Sid:       "ChainStart",
+			Effect:    iam.Allow,
+			Principal: &iam.Principal{AWS: iam.StringList{root}}, //nolint:exhaustruct
+			Action:    iam.StringList{iam.AssumeRole},
+		})
+	}
+
+	statements = append(statements, iam.Statement{ //nolint:exhaustruct
+		Sid:       "Predecessor",
+		Effect:    iam.Allow,
+		Principal: &iam.Principal{AWS: iam.StringList{"*"}}, //nolint:exhaustruct
+		Action:    iam.StringList{iam.AssumeRole},
+		Condition: iam.Condition{
+			"StringEquals": {"aws:PrincipalAccount": {m.FromAccount}},
+			"StringLike":   {"aws:PrincipalArn": {m.FromARN}},
+		},
+	})
+
+	return &iam.Policy{Version: iam.Version, ID: "", Statement: statements}
+}
+
+func (m member) permissions() *iam.Policy {
+	return &iam.Policy{
+		Version: iam.Version,
+		ID:      "",
+		Statement: iam.Statements{{ //nolint:exhaustruct
+			Sid:      "Successor",
+			Effect:   iam.Allow,
+			Action:   iam.StringList{iam.AssumeRole},
+			Resource: iam.StringList{m.ToARN},
+		}},
+	}
+}
+
+// Members returns the trust and permission policies of every ring member, after checking with the policy
+// linter that each role, including the last, can assume its successor.
+func (c Chain) Members() ([]Member, error) {
+	members, err := c.members()
+	if err != nil {
+		return nil, err
+	}
+
+	return generate(members)
+}
+
+// generate builds the policy pairs of members and checks that they close the ring.
+func generate(members []member) ([]Member, error) {
+	catalog := iam.NewCatalog()
+	result := make([]Member, 0, len(members))
+
+	for _, member := range members {
+		generated := Member{
+			RoleName:    member.RoleName,
+			ARN:         member.ARN,
+			Path:        member.Path,
+			Trust:       member.trust(),
+			Permissions: member.permissions(),
+		}
+
+		catalog.AddRole(&iam.Role{
+			ARN:      generated.ARN,
+			Name:     generated.RoleName,
+			Trust:    generated.Trust,
+			Inline:   []*iam.Policy{generated.Permissions},
+			Attached: nil,
+		})
+
+		result = append(result, generated)
+	}
+
+	for _, member := range members {
+		check := catalog.CheckEdge(member.ARN, member.ToARN, arn.SessionName)
+		if !check.Pass {
+			return nil, fmt.Errorf("%w: %s -> %s: %s", ErrOpenRing, member.RoleName, check.Target, check.Reason)
+		}
+	}
+
+	return result, nil
+}
@@ -0,0 +1,142 @@
+// Copyright 2025 variHQ OÜ
+// SPDX-License-Identifier: BSD-3-Clause
+
+package gen_test
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/wakeful/trick/internal/gen"
+	"github.com/wakeful/trick/internal/iam"
+)
+
+var crossAccountChain = gen.Chain{
+	Name:   "cross",
+	Region: "eu-west-1",
+	Roles: []gen.Role{
+		{ARN: "arn:aws:iam::111111111111:role/hop-a", Entry: true},
+		{ARN: "arn:aws:iam::222222222222:role/hop-b"},
+		{ARN: "arn:aws:iam::111111111111:role/path/hop-c"},
+	},
+}
+
+func TestChain_Members(t *testing.T) {
+	t.Parallel()
+
+	members, err := exampleChain.Members()
+	if err != nil {
+		t.Fatalf("Members() error = %v", err)
+	}
+
+	if len(members) != 3 || len(members[0].Trust.Statement) != 2 || len(members[1].Trust.Statement) != 1 {
+		t.Fatalf("Members() = %+v, want a chain_start statement on the first role only", members)
+	}
+
+	trust, err := members[0].Trust.Marshal()
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+
+	for _, want := range []string{
+		`"AWS": "arn:aws:iam::123456789012:root"`,
+		`"aws:PrincipalArn": "arn:aws:iam::123456789012:role/trick-role-c"`,
+		`"aws:PrincipalAccount": "123456789012"`,
+	} {
+		if !strings.Contains(trust, want) {
+			t.Errorf("trust policy missing %s:\n%s", want, trust)
+		}
+	}
+
+	_, err = gen.Chain{Roles: []gen.Role{{ARN: "arn:aws:iam::1:role/a"}}}.Members()
+	if !errors.Is(err, gen.ErrShortChain) {
+		t.Errorf("Members() error = %v, want %v", err, gen.ErrShortChain)
+	}
+}
+
+func TestChain_IAMJSON(t *testing.T) {
+	t.Parallel()
+
+	out, err := crossAccountChain.IAMJSON()
+	if err != nil {
+		t.Fatalf("IAMJSON() error = %v", err)
+	}
+
+	catalog := iam.NewCatalog()
+
+	err = catalog.Load(strings.NewReader(out))
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	for idx, role := range crossAccountChain.Roles {
+		next := crossAccountChain.Roles[(idx+1)%len(crossAccountChain.Roles)]
+
+		if check := catalog.CheckEdge(role.ARN, next.ARN, "trick"); !check.Pass {
+			t.Errorf("CheckEdge(%s, %s) = %s", role.ARN, next.ARN, check.Reason)
+		}
+	}
+}
+
+func TestChain_CloudFormation(t *testing.T) {
+	t.Parallel()
+
+	out, err := exampleChain.CloudFormation()
+	if err != nil {
+		t.Fatalf("CloudFormation() error = %v", err)
+	}
+
+	var template struct {
+		Resources map[string]struct {
+			Type       string `json:"Type"`
+			Properties struct {
+				RoleName string `json:"RoleName"`
+				Path     string `json:"Path"`
+			} `json:"Properties"`
+		} `json:"Resources"`
+		Outputs map[string]any `json:"Outputs"`
+	}
+
+	err = json.Unmarshal([]byte(out), &template)
+	if err != nil {
+		t.Fatalf("CloudFormation() is not JSON: %v", err)
+	}
+
+	role, found := template.Resources["RoleTrickRoleB"]
+	if !found || role.Type != "AWS::IAM::Role" || role.Properties.RoleName != "trick-role-b" ||
+		role.Properties.Path != "/" {
+		t.Errorf("CloudFormation() resources = %+v", template.Resources)
+	}
+
+	if _, found := template.Outputs["RoleArnTrickRoleC"]; !found || len(template.Outputs) != 3 {
+		t.Errorf("CloudFormation() outputs = %v", template.Outputs)
+	}
+
+	out, err = gen.Chain{
+		Name:   "nested",
+		Region: "eu-west-1",
+		Roles: []gen.Role{
+			{ARN: "arn:aws:iam::123456789012:role/trick-role-a", Entry: true},
+			{ARN: "arn:aws:iam::123456789012:role/red/team/trick-role-b"},
+		},
+	}.CloudFormation()
+	if err != nil {
+		t.Fatalf("CloudFormation() error = %v", err)
+	}
+
+	err = json.Unmarshal([]byte(out), &template)
+	if err != nil {
+		t.Fatalf("CloudFormation() is not JSON: %v", err)
+	}
+
+	if path := template.Resources["RoleTrickRoleB"].Properties.Path; path != "/red/team/" {
+		t.Errorf("CloudFormation() path = %q, want %q", path, "/red/team/")
+	}
+
+	_, err = crossAccountChain.CloudFormation()
+	if !errors.Is(err, gen.ErrMixedAccounts) {
+		t.Errorf("CloudFormation() error = %v, want %v", err, gen.ErrMixedAccounts)
+	}
+}
@@ -0,0 +1,96 @@
+// Copyright 2025 variHQ OÜ
+// SPDX-License-Identifier: BSD-3-Clause
+
+package gen
+
+import (
+	_ "embed"
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/hashicorp/hcl/v2/hclwrite"
+)
+
+const (
+	// TerraformVersion is the Terraform release the generated root pins, matching example/main.tf.
+	TerraformVersion = "1.13.4"
+	// AWSProviderVersion is the hashicorp/aws release the generated root pins.
+	AWSProviderVersion = "6.18.0"
+	// ModuleSource is the default source of the per-role module.
+	ModuleSource = "./modules/role"
+)
+
+//go:embed templates/terraform.tf.tmpl
+var terraformTemplate string
+
+// Terraform renders a Terraform root with one call to the role module per ring member, each wired to its
+// predecessor and successor, chain_start on the entry points and an output per role ARN.
+func (c Chain) Terraform(source string) (string, error) {
+	members, err := c.members()
+	if err != nil {
+		return "", err
+	}
+
+	err = sameAccount(members)
+	if err != nil {
+		return "", err
+	}
+
+	for _, member := range members {
+		if !strings.HasPrefix(member.RoleName, RolePrefix) || member.Name == "" {
+			return "", fmt.Errorf("%w: %q", ErrRoleName, member.RoleName)
+		}
+	}
+
+	labelModules(members)
+
+	if source == "" {
+		source = ModuleSource
+	}
+
+	rendered, err := render("terraform", terraformTemplate, struct {
+		Name             string
+		Region           string
+		Source           string
+		TerraformVersion string
+		ProviderVersion  string
+		Modules          []member
+	}{
+		Name:             c.Name,
+		Region:           c.Region,
+		Source:           source,
+		TerraformVersion: TerraformVersion,
+		ProviderVersion:  AWSProviderVersion,
+		Modules:          members,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return string(hclwrite.Format([]byte(rendered))), nil
+}
+
+// labelModules sets the block label of every member. IAM role names may hold + = , . @, which Terraform identifiers
+// cannot, so those become _ and a numeric suffix keeps names that collide apart.
+func labelModules(members []member) {
+	taken := make(map[string]bool, len(members))
+
+	for idx := range members {
+		label := strings.Map(func(r rune) rune {
+			if r == '-' || r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r) {
+				return r
+			}
+
+			return '_'
+		}, members[idx].Name)
+
+		unique := label
+		for suffix := 2; taken[unique]; suffix++ {
+			unique = fmt.Sprintf("%s_%d", label, suffix)
+		}
+
+		taken[unique] = true
+		members[idx].Label = unique
+	}
+}
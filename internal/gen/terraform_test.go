@@ -0,0 +1,169 @@
+// Copyright 2025 variHQ OÜ
+// SPDX-License-Identifier: BSD-3-Clause
+
+package gen_test
+
+import (
+	"errors"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/wakeful/trick/internal/gen"
+)
+
+// exampleChain is the ring example/main.tf builds by hand.
+var exampleChain = gen.Chain{
+	Name:   "example",
+	Region: "eu-west-1",
+	Roles: []gen.Role{
+		{ARN: "arn:aws:iam::123456789012:role/trick-role-a", Entry: true},
+		{ARN: "arn:aws:iam::123456789012:role/trick-role-b"},
+		{ARN: "arn:aws:iam::123456789012:role/trick-role-c"},
+	},
+}
+
+// withoutHeader drops the leading comment block, which differs between the example and generated code.
+func withoutHeader(content string) string {
+	lines := strings.Split(content, "\n")
+	for len(lines) > 0 && (strings.HasPrefix(lines[0], "#") || lines[0] == "") {
+		lines = lines[1:]
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+func TestChain_Terraform_example(t *testing.T) {
+	t.Parallel()
+
+	want, err := os.ReadFile("../../example/main.tf")
+	if err != nil {
+		t.Fatalf("failed to read example: %v", err)
+	}
+
+	got, err := exampleChain.Terraform("")
+	if err != nil {
+		t.Fatalf("Terraform() error = %v", err)
+	}
+
+	if withoutHeader(got) != withoutHeader(string(want)) {
+		t.Errorf("Terraform() does not match example/main.tf:\n%s", got)
+	}
+}
+
+func TestChain_Terraform(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name         string
+		roles        []gen.Role
+		wantErr      error
+		wantContains []string
+	}{
+		{
+			name:    "single role",
+			roles:   []gen.Role{{ARN: "arn:aws:iam::123456789012:role/trick-role-a"}},
+			wantErr: gen.ErrShortChain,
+		},
+		{
+			name: "mixed accounts",
+			roles: []gen.Role{
+				{ARN: "arn:aws:iam::123456789012:role/trick-role-a"},
+				{ARN: "arn:aws:iam::210987654321:role/trick-role-b"},
+			},
+			wantErr: gen.ErrMixedAccounts,
+		},
+		{
+			name: "foreign role name",
+			roles: []gen.Role{
+				{ARN: "arn:aws:iam::123456789012:role/trick-role-a"},
+				{ARN: "arn:aws:iam::123456789012:role/admin"},
+			},
+			wantErr: gen.ErrRoleName,
+		},
+		{
+			name: "several entry points",
+			roles: []gen.Role{
+				{ARN: "arn:aws:iam::123456789012:role/trick-role-a"},
+				{ARN: "arn:aws:iam::123456789012:role/trick-role-b", Entry: true},
+				{ARN: "arn:aws:iam::123456789012:role/trick-role-c", Entry: true},
+			},
+			wantContains: []string{
+				"  name        = \"b\"\n  chain_start = true\n",
+				"  name        = \"c\"\n  chain_start = true\n\n  assume_from = \"b\"\n  can_assume  = \"a\"\n",
+				"  name        = \"a\"\n  assume_from = \"c\"\n",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			chain := gen.Chain{Name: "test", Region: "eu-west-1", Roles: tt.roles}
+
+			got, err := chain.Terraform("")
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Terraform() error = %v, want %v", err, tt.wantErr)
+			}
+
+			for _, want := range tt.wantContains {
+				if !strings.Contains(got, want) {
+					t.Errorf("Terraform() missing %q:\n%s", want, got)
+				}
+			}
+		})
+	}
+}
+
+func TestChain_Terraform_labels(t *testing.T) {
+	t.Parallel()
+
+	chain := gen.Chain{Name: "test", Region: "eu-west-1", Roles: []gen.Role{
+		{ARN: "arn:aws:iam::123456789012:role/trick-role-ops.admin", Entry: true},
+		{ARN: "arn:aws:iam::123456789012:role/trick-role-ops_admin"},
+		{ARN: "arn:aws:iam::123456789012:role/trick-role-red+team@lab"},
+	}}
+
+	got, err := chain.Terraform("")
+	if err != nil {
+		t.Fatalf("Terraform() error = %v", err)
+	}
+
+	file, diags := hclsyntax.ParseConfig([]byte(got), "main.tf", hcl.InitialPos)
+	if diags.HasErrors() {
+		t.Fatalf("Terraform() is not valid HCL: %v", diags)
+	}
+
+	labels := make([]string, 0)
+
+	for _, block := range file.Body.(*hclsyntax.Body).Blocks { //nolint:forcetypeassert
+		if block.Type == "module" || block.Type == "output" {
+			labels = append(labels, block.Labels[0])
+		}
+	}
+
+	want := []string{
+		"role_ops_admin", "role_arn_ops_admin",
+		"role_ops_admin_2", "role_arn_ops_admin_2",
+		"role_red_team_lab", "role_arn_red_team_lab",
+	}
+	if !reflect.DeepEqual(labels, want) {
+		t.Errorf("Terraform() labels = %v, want %v", labels, want)
+	}
+
+	for _, label := range labels {
+		if !hclsyntax.ValidIdentifier(label) {
+			t.Errorf("Terraform() label %q is not an identifier", label)
+		}
+	}
+
+	for _, name := range []string{`name        = "ops.admin"`, `module.role_red_team_lab.role_arn`} {
+		if !strings.Contains(got, name) {
+			t.Errorf("Terraform() missing %q:\n%s", name, got)
+		}
+	}
+}
@@ -0,0 +1,411 @@
+// Copyright 2025 variHQ OÜ
+// SPDX-License-Identifier: BSD-3-Clause
+
+package hunt
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/wakeful/trick/internal/arn"
+)
+
+// Event is a successful AssumeRole call from one role session into another role.
+type Event struct {
+	Time        time.Time
+	Account     string
+	Source      string
+	Target      string
+	SessionName string
+}
+
+type record struct {
+	EventTime          time.Time `json:"eventTime"`
+	EventSource        string    `json:"eventSource"`
+	EventName          string    `json:"eventName"`
+	ErrorCode          string    `json:"errorCode"`
+	RecipientAccountID string    `json:"recipientAccountId"`
+	UserIdentity       struct {
+		Type string `json:"type"`
+		ARN  string `json:"arn"`
+	} `json:"userIdentity"`
+	RequestParameters struct {
+		RoleARN         string `json:"roleArn"`
+		RoleSessionName string `json:"roleSessionName"`
+	} `json:"requestParameters"`
+}
+
+// toEvent keeps successful role-to-role AssumeRole records and drops everything else.
+func (r record) toEvent() (Event, bool) {
+	if r.EventSource != "sts.amazonaws.com" || r.EventName != "AssumeRole" || r.ErrorCode != "" {
+		return Event{}, false
+	}
+
+	source, _, err := arn.RoleFromAssumed(r.UserIdentity.ARN)
+	if err != nil || r.RequestParameters.RoleARN == "" {
+		return Event{}, false
+	}
+
+	return Event{
+		Time:        r.EventTime,
+		Account:     r.RecipientAccountID,
+		Source:      source,
+		Target:      arn.WithoutPath(r.RequestParameters.RoleARN),
+		SessionName: r.RequestParameters.RoleSessionName,
+	}, true
+}
+
+// Read decodes CloudTrail records from input, which may be gzip-compressed and hold either a
+// {"Records": [...]} export, a bare JSON array of records, or one record per line.
+func Read(input io.Reader) ([]Event, error) {
+	buffered := bufio.NewReader(input)
+
+	magic, _ := buffered.Peek(2) //nolint:mnd
+	if bytes.Equal(magic, []byte{0x1f, 0x8b}) {
+		reader, err := gzip.NewReader(buffered)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open gzip stream: %w", err)
+		}
+
+		defer func() { _ = reader.Close() }()
+
+		buffered = bufio.NewReader(reader)
+	}
+
+	decoder := json.NewDecoder(buffered)
+	events := make([]Event, 0)
+
+	for {
+		var raw json.RawMessage
+
+		err := decoder.Decode(&raw)
+		if errors.Is(err, io.EOF) {
+			return events, nil
+		}
+
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode CloudTrail records: %w", err)
+		}
+
+		records, err := decodeRecords(raw)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, rec := range records {
+			if event, ok := rec.toEvent(); ok {
+				events = append(events, event)
+			}
+		}
+	}
+}
+
+func decodeRecords(raw json.RawMessage) ([]record, error) {
+	trimmed := bytes.TrimSpace(raw)
+
+	if len(trimmed) > 0 && trimmed[0] == '[' {
+		var records []record
+
+		err := json.Unmarshal(trimmed, &records)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode CloudTrail records: %w", err)
+		}
+
+		return records, nil
+	}
+
+	var export struct {
+		Records []record `json:"Records"`
+	}
+
+	err := json.Unmarshal(trimmed, &export)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode CloudTrail records: %w", err)
+	}
+
+	if export.Records != nil {
+		return export.Records, nil
+	}
+
+	var single record
+
+	err = json.Unmarshal(trimmed, &single)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode CloudTrail record: %w", err)
+	}
+
+	return []record{single}, nil
+}
+
+// Load reads every .json and .json.gz file under paths, walking directories recursively.
+func Load(paths ...string) ([]Event, error) {
+	events := make([]Event, 0)
+
+	for _, root := range paths {
+		err := filepath.WalkDir(root, func(path string, entry fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+
+			if entry.IsDir() {
+				return nil
+			}
+
+			if path != root && !strings.HasSuffix(path, ".json") && !strings.HasSuffix(path, ".json.gz") {
+				return nil
+			}
+
+			file, err := os.Open(path) //nolint:gosec
+			if err != nil {
+				return fmt.Errorf("failed to open %s: %w", path, err)
+			}
+
+			defer func() { _ = file.Close() }()
+
+			found, err := Read(file)
+			if err != nil {
+				return fmt.Errorf("%s: %w", path, err)
+			}
+
+			events = append(events, found...)
+
+			return nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to load CloudTrail exports: %w", err)
+		}
+	}
+
+	return events, nil
+}
+
+// Cycle is a closed ring of roles that assumed each other in order.
+type Cycle struct {
+	// Roles are the ring members starting from the lexically smallest role.
+	Roles []string `json:"roles"`
+	// Hops is the number of AssumeRole events observed on the ring's edges.
+	Hops int `json:"hops"`
+	// Rotations is the number of complete turns, bounded by the least used edge.
+	Rotations int `json:"rotations"`
+	// Cadence is the median time the ring takes for a turn, measured between consecutive uses of each edge so the
+	// near-instant transit hops within a tick do not drown it.
+	Cadence time.Duration `json:"cadence"`
+	// Sessions are the distinct RoleSessionName values seen on the ring.
+	Sessions  []string  `json:"sessions"`
+	Accounts  []string  `json:"accounts"`
+	FirstSeen time.Time `json:"first_seen"`
+	LastSeen  time.Time `json:"last_seen"`
+}
+
+// Length returns the number of roles in the ring.
+func (c Cycle) Length() int {
+	return len(c.Roles)
+}
+
+type edge struct {
+	from string
+	to   string
+}
+
+// searchBudget bounds the edges FindCycles follows, so a dense graph cannot keep it busy for hours.
+const searchBudget = 1_000_000
+
+// FindCycles builds the AssumeRole graph from events and returns every elementary cycle of at most maxLength
+// roles, ordered by first sighting. complete is false when the search ran out of budget and more cycles may exist.
+func FindCycles(events []Event, maxLength int) ([]Cycle, bool) {
+	byEdge := make(map[edge][]Event)
+	adjacency := make(map[string][]string)
+
+	for _, event := range events {
+		key := edge{from: event.Source, to: event.Target}
+		if _, ok := byEdge[key]; !ok {
+			adjacency[event.Source] = append(adjacency[event.Source], event.Target)
+		}
+
+		byEdge[key] = append(byEdge[key], event)
+	}
+
+	nodes := slices.Sorted(maps.Keys(adjacency))
+
+	cycles := make([]Cycle, 0)
+	rings, complete := elementaryCycles(nodes, adjacency, maxLength, searchBudget)
+
+	for _, ring := range rings {
+		cycles = append(cycles, describe(ring, byEdge))
+	}
+
+	slices.SortFunc(cycles, func(a, b Cycle) int { return a.FirstSeen.Compare(b.FirstSeen) })
+
+	return cycles, complete
+}
+
+// elementaryCycles enumerates simple cycles, each rooted at its smallest node so it is reported once. The walk
+// from a start only enters nodes that can still get back to it within maxLength roles, and gives up once it has
+// followed budget edges, reporting false.
+func elementaryCycles(nodes []string, adjacency map[string][]string, maxLength, budget int) ([][]string, bool) {
+	found := make([][]string, 0)
+
+	for _, start := range nodes {
+		distance := distancesTo(start, adjacency, maxLength)
+		path := []string{start}
+		onPath := map[string]bool{start: true}
+
+		var walk func(node string)
+
+		walk = func(node string) {
+			for _, next := range adjacency[node] {
+				if budget <= 0 {
+					return
+				}
+
+				budget--
+
+				remaining, reaches := distance[next]
+
+				switch {
+				case next == start && len(path) > 1:
+					found = append(found, slices.Clone(path))
+				case next <= start || onPath[next] || !reaches || len(path)+remaining > maxLength:
+					continue
+				default:
+					path = append(path, next)
+					onPath[next] = true
+
+					walk(next)
+
+					onPath[next] = false
+					path = path[:len(path)-1]
+				}
+			}
+		}
+
+		walk(start)
+
+		if budget <= 0 {
+			return found, false
+		}
+	}
+
+	return found, true
+}
+
+// distancesTo returns how many hops each node larger than start needs to get back to start, through nodes larger
+// than start, for the nodes that can do so within maxLength hops.
+func distancesTo(start string, adjacency map[string][]string, maxLength int) map[string]int {
+	reverse := make(map[string][]string)
+
+	for from, targets := range adjacency {
+		for _, to := range targets {
+			reverse[to] = append(reverse[to], from)
+		}
+	}
+
+	distance := make(map[string]int)
+	queue := []string{start}
+	seen := map[string]bool{start: true}
+
+	for depth := 1; depth <= maxLength && len(queue) > 0; depth++ {
+		next := make([]string, 0)
+
+		for _, node := range queue {
+			for _, from := range reverse[node] {
+				if from <= start || seen[from] {
+					continue
+				}
+
+				seen[from] = true
+				distance[from] = depth
+				next = append(next, from)
+			}
+		}
+
+		queue = next
+	}
+
+	return distance
+}
+
+func describe(ring []string, byEdge map[edge][]Event) Cycle {
+	cycle := Cycle{ //nolint:exhaustruct
+		Roles:     ring,
+		Rotations: -1,
+	}
+
+	times := make([]time.Time, 0)
+	turns := make([]time.Duration, 0)
+	sessions := make(map[string]struct{})
+	accounts := make(map[string]struct{})
+
+	for idx, from := range ring {
+		events := byEdge[edge{from: from, to: ring[(idx+1)%len(ring)]}]
+
+		if cycle.Rotations < 0 || len(events) < cycle.Rotations {
+			cycle.Rotations = len(events)
+		}
+
+		turns = append(turns, gaps(events)...)
+
+		for _, event := range events {
+			times = append(times, event.Time)
+			sessions[event.SessionName] = struct{}{}
+
+			if event.Account != "" {
+				accounts[event.Account] = struct{}{}
+			}
+		}
+	}
+
+	slices.SortFunc(times, time.Time.Compare)
+
+	cycle.Hops = len(times)
+	cycle.FirstSeen = times[0]
+	cycle.LastSeen = times[len(times)-1]
+	cycle.Cadence = median(turns)
+	cycle.Sessions = sortedKeys(sessions)
+	cycle.Accounts = sortedKeys(accounts)
+
+	return cycle
+}
+
+// gaps returns the time between consecutive uses of an edge, each one turn of the ring.
+func gaps(events []Event) []time.Duration {
+	times := make([]time.Time, 0, len(events))
+	for _, event := range events {
+		times = append(times, event.Time)
+	}
+
+	slices.SortFunc(times, time.Time.Compare)
+
+	found := make([]time.Duration, 0, len(times))
+	for idx := 1; idx < len(times); idx++ {
+		found = append(found, times[idx].Sub(times[idx-1]))
+	}
+
+	return found
+}
+
+func median(durations []time.Duration) time.Duration {
+	if len(durations) == 0 {
+		return 0
+	}
+
+	slices.Sort(durations)
+
+	return durations[len(durations)/2]
+}
+
+func sortedKeys(set map[string]struct{}) []string {
+	return slices.Sorted(maps.Keys(set))
+}
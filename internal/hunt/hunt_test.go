@@ -0,0 +1,209 @@
+// Copyright 2025 variHQ OÜ
+// SPDX-License-Identifier: BSD-3-Clause
+
+package hunt_test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/wakeful/trick/internal/hunt"
+)
+
+const account = "123456789012"
+
+func assumeRecord(at time.Time, from, to string) map[string]any {
+	return map[string]any{
+		"eventTime":          at.Format(time.RFC3339),
+		"eventSource":        "sts.amazonaws.com",
+		"eventName":          "AssumeRole",
+		"recipientAccountId": account,
+		"userIdentity": map[string]any{
+			"type": "AssumedRole",
+			"arn":  fmt.Sprintf("arn:aws:sts::%s:assumed-role/%s/trick", account, from),
+		},
+		"requestParameters": map[string]any{
+			"roleArn":         fmt.Sprintf("arn:aws:iam::%s:role/%s", account, to),
+			"roleSessionName": "trick",
+		},
+	}
+}
+
+func ringExport(t *testing.T) []byte {
+	t.Helper()
+
+	base := time.Date(2025, 1, 1, 10, 0, 0, 0, time.UTC)
+	ring := []string{"role-a", "role-b", "role-c"}
+	records := make([]map[string]any, 0)
+
+	for hop := range 7 {
+		from := ring[hop%len(ring)]
+		to := ring[(hop+1)%len(ring)]
+		records = append(records, assumeRecord(base.Add(time.Duration(hop)*12*time.Minute), from, to))
+	}
+
+	denied := assumeRecord(base, "role-c", "role-x")
+	denied["errorCode"] = "AccessDenied"
+	records = append(records, denied)
+
+	other := assumeRecord(base, "role-x", "role-y")
+	other["eventName"] = "GetCallerIdentity"
+	records = append(records, other)
+
+	content, err := json.Marshal(map[string]any{"Records": records})
+	if err != nil {
+		t.Fatalf("failed to encode records: %v", err)
+	}
+
+	return content
+}
+
+func TestRead(t *testing.T) {
+	t.Parallel()
+
+	export := ringExport(t)
+
+	var compressed bytes.Buffer
+
+	writer := gzip.NewWriter(&compressed)
+	_, _ = writer.Write(export)
+	_ = writer.Close()
+
+	for name, input := range map[string][]byte{"plain": export, "gzip": compressed.Bytes()} {
+		events, err := hunt.Read(bytes.NewReader(input))
+		if err != nil {
+			t.Fatalf("Read(%s) error = %v", name, err)
+		}
+
+		if len(events) != 7 {
+			t.Errorf("Read(%s) = %d events, want 7", name, len(events))
+		}
+	}
+}
+
+func TestFindCycles(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+
+	err := os.WriteFile(filepath.Join(dir, "trail.json"), ringExport(t), 0o600)
+	if err != nil {
+		t.Fatalf("failed to write export: %v", err)
+	}
+
+	events, err := hunt.Load(dir)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	// A one-way edge must not produce a cycle.
+	events = append(events, hunt.Event{
+		Time:   time.Date(2025, 1, 1, 9, 0, 0, 0, time.UTC),
+		Source: "arn:aws:iam::123456789012:role/role-a",
+		Target: "arn:aws:iam::123456789012:role/role-z",
+	})
+
+	cycles, complete := hunt.FindCycles(events, 8)
+	if len(cycles) != 1 || !complete {
+		t.Fatalf("FindCycles() = %d cycles, want 1", len(cycles))
+	}
+
+	got := cycles[0]
+	want := []string{
+		"arn:aws:iam::123456789012:role/role-a",
+		"arn:aws:iam::123456789012:role/role-b",
+		"arn:aws:iam::123456789012:role/role-c",
+	}
+
+	if !reflect.DeepEqual(got.Roles, want) {
+		t.Errorf("FindCycles() roles = %v, want %v", got.Roles, want)
+	}
+
+	if got.Length() != 3 || got.Hops != 7 || got.Rotations != 2 {
+		t.Errorf("FindCycles() length = %d, hops = %d, rotations = %d", got.Length(), got.Hops, got.Rotations)
+	}
+
+	if got.Cadence != 36*time.Minute {
+		t.Errorf("FindCycles() cadence = %v, want 36m", got.Cadence)
+	}
+
+	if !reflect.DeepEqual(got.Sessions, []string{"trick"}) {
+		t.Errorf("FindCycles() sessions = %v", got.Sessions)
+	}
+
+	if got.LastSeen.Sub(got.FirstSeen) != 72*time.Minute {
+		t.Errorf("FindCycles() first/last seen = %v / %v", got.FirstSeen, got.LastSeen)
+	}
+
+	if short, _ := hunt.FindCycles(events, 2); len(short) != 0 {
+		t.Error("FindCycles() should honour maxLength")
+	}
+}
+
+func TestFindCycles_transitHops(t *testing.T) {
+	t.Parallel()
+
+	// Each tick hops a -> b -> c within a second and rests on c, the only usable role, for 10 minutes.
+	base := time.Date(2025, 1, 1, 10, 0, 0, 0, time.UTC)
+	events := make([]hunt.Event, 0)
+
+	for tick := range 4 {
+		at := base.Add(time.Duration(tick) * 10 * time.Minute)
+
+		for idx, hop := range [][2]string{{"role-c", "role-a"}, {"role-a", "role-b"}, {"role-b", "role-c"}} {
+			events = append(events, hunt.Event{ //nolint:exhaustruct
+				Time:   at.Add(time.Duration(idx) * 500 * time.Millisecond),
+				Source: "arn:aws:iam::123456789012:role/" + hop[0],
+				Target: "arn:aws:iam::123456789012:role/" + hop[1],
+			})
+		}
+	}
+
+	cycles, _ := hunt.FindCycles(events, 8)
+	if len(cycles) != 1 || cycles[0].Cadence != 10*time.Minute {
+		t.Fatalf("FindCycles() = %+v, want one ring turning every 10m", cycles)
+	}
+}
+
+func TestFindCycles_denseGraph(t *testing.T) {
+	t.Parallel()
+
+	// Every role assumes every other one: far more rings than any export would hold.
+	base := time.Date(2025, 1, 1, 10, 0, 0, 0, time.UTC)
+	events := make([]hunt.Event, 0)
+
+	for from := range 14 {
+		for to := range 14 {
+			if from != to {
+				events = append(events, hunt.Event{ //nolint:exhaustruct
+					Time:   base,
+					Source: fmt.Sprintf("arn:aws:iam::123456789012:role/role-%02d", from),
+					Target: fmt.Sprintf("arn:aws:iam::123456789012:role/role-%02d", to),
+				})
+			}
+		}
+	}
+
+	done := make(chan bool)
+
+	go func() {
+		_, complete := hunt.FindCycles(events, 16)
+		done <- complete
+	}()
+
+	select {
+	case complete := <-done:
+		if complete {
+			t.Error("FindCycles() reported a complete search of a complete graph")
+		}
+	case <-time.After(30 * time.Second):
+		t.Fatal("FindCycles() did not stop within its budget")
+	}
+}
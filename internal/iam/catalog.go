@@ -0,0 +1,361 @@
+// Copyright 2025 variHQ OÜ
+// SPDX-License-Identifier: BSD-3-Clause
+
+package iam
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/wakeful/trick/internal/arn"
+)
+
+// ErrUnknownDocument is returned when a JSON file is not one of the IAM API outputs Load understands.
+var ErrUnknownDocument = errors.New("unrecognised IAM document")
+
+// AssumeRole is the action every edge of a ring needs.
+const AssumeRole = "sts:AssumeRole"
+
+// Role is an IAM role with its trust policy and permission policies.
+type Role struct {
+	ARN   string
+	Name  string
+	Trust *Policy
+	// Inline are the role's inline permission policies.
+	Inline []*Policy
+	// Attached are the ARNs of managed policies attached to the role.
+	Attached []string
+}
+
+// Catalog holds the roles and managed policies of one or more accounts.
+type Catalog struct {
+	roles   map[string]*Role
+	managed map[string]*Policy
+	// inline holds get-role-policy output keyed by role name until the role itself is seen.
+	inline map[string][]*Policy
+}
+
+// NewCatalog returns an empty catalog.
+func NewCatalog() *Catalog {
+	return &Catalog{
+		roles:   make(map[string]*Role),
+		managed: make(map[string]*Policy),
+		inline:  make(map[string][]*Policy),
+	}
+}
+
+// AddRole adds or replaces a role, keyed by its ARN without path.
+func (c *Catalog) AddRole(role *Role) {
+	c.roles[arn.WithoutPath(role.ARN)] = role
+}
+
+// AddManaged adds a managed policy under its ARN.
+func (c *Catalog) AddManaged(policyARN string, policy *Policy) {
+	c.managed[policyARN] = policy
+}
+
+// Role looks up a role by ARN; the path of the ARN is ignored.
+func (c *Catalog) Role(roleARN string) (*Role, bool) {
+	role, ok := c.roles[arn.WithoutPath(roleARN)]
+
+	return role, ok
+}
+
+// Permissions returns the inline and resolvable attached policies of role.
+func (c *Catalog) Permissions(role *Role) []*Policy {
+	policies := slices.Clone(role.Inline)
+	policies = append(policies, c.inline[role.Name]...)
+
+	for _, policyARN := range role.Attached {
+		if policy, ok := c.managed[policyARN]; ok {
+			policies = append(policies, policy)
+		}
+	}
+
+	return policies
+}
+
+type namedDocument struct {
+	PolicyName     string   `json:"PolicyName"`
+	PolicyDocument Document `json:"PolicyDocument"`
+}
+
+type roleDetail struct {
+	Arn                      string          `json:"Arn"`
+	RoleName                 string          `json:"RoleName"`
+	AssumeRolePolicyDocument *Document       `json:"AssumeRolePolicyDocument"`
+	RolePolicyList           []namedDocument `json:"RolePolicyList"`
+	AttachedManagedPolicies  []struct {
+		PolicyArn string `json:"PolicyArn"`
+	} `json:"AttachedManagedPolicies"`
+}
+
+func (r roleDetail) toRole() *Role {
+	role := &Role{
+		ARN:      r.Arn,
+		Name:     r.RoleName,
+		Trust:    nil,
+		Inline:   make([]*Policy, 0, len(r.RolePolicyList)),
+		Attached: make([]string, 0, len(r.AttachedManagedPolicies)),
+	}
+
+	if r.AssumeRolePolicyDocument != nil {
+		role.Trust = r.AssumeRolePolicyDocument.Policy
+	}
+
+	for _, inline := range r.RolePolicyList {
+		role.Inline = append(role.Inline, inline.PolicyDocument.Policy)
+	}
+
+	for _, attached := range r.AttachedManagedPolicies {
+		role.Attached = append(role.Attached, attached.PolicyArn)
+	}
+
+	return role
+}
+
+type policyDetail struct {
+	Arn               string `json:"Arn"`
+	DefaultVersionID  string `json:"DefaultVersionId"`
+	PolicyVersionList []struct {
+		Document         Document `json:"Document"`
+		VersionID        string   `json:"VersionId"`
+		IsDefaultVersion bool     `json:"IsDefaultVersion"`
+	} `json:"PolicyVersionList"`
+}
+
+// document is the union of the IAM CLI outputs Load understands.
+type document struct {
+	// aws iam get-account-authorization-details
+	RoleDetailList []roleDetail   `json:"RoleDetailList"`
+	Policies       []policyDetail `json:"Policies"`
+	// aws iam get-role
+	Role *roleDetail `json:"Role"`
+	// aws iam get-role-policy
+	RoleName       string    `json:"RoleName"`
+	PolicyDocument *Document `json:"PolicyDocument"`
+}
+
+// Load adds the roles and policies found in a single JSON document: the output of
+// get-account-authorization-details, get-role or get-role-policy.
+func (c *Catalog) Load(input io.Reader) error {
+	var doc document
+
+	err := json.NewDecoder(input).Decode(&doc)
+	if err != nil {
+		return fmt.Errorf("failed to decode IAM document: %w", err)
+	}
+
+	found := false
+
+	for _, detail := range doc.RoleDetailList {
+		c.AddRole(detail.toRole())
+
+		found = true
+	}
+
+	for _, policy := range doc.Policies {
+		for _, version := range policy.PolicyVersionList {
+			if version.IsDefaultVersion || version.VersionID == policy.DefaultVersionID {
+				c.AddManaged(policy.Arn, version.Document.Policy)
+
+				found = true
+			}
+		}
+	}
+
+	if doc.Role != nil && doc.Role.Arn != "" {
+		c.AddRole(doc.Role.toRole())
+
+		found = true
+	}
+
+	if doc.RoleName != "" && doc.PolicyDocument != nil {
+		c.inline[doc.RoleName] = append(c.inline[doc.RoleName], doc.PolicyDocument.Policy)
+
+		found = true
+	}
+
+	if !found {
+		return ErrUnknownDocument
+	}
+
+	return nil
+}
+
+// LoadDir reads every .json file under dir into a new catalog.
+func LoadDir(dir string) (*Catalog, error) {
+	catalog := NewCatalog()
+
+	err := filepath.WalkDir(dir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if entry.IsDir() || !strings.HasSuffix(path, ".json") {
+			return nil
+		}
+
+		file, err := os.Open(path) //nolint:gosec
+		if err != nil {
+			return fmt.Errorf("failed to open %s: %w", path, err)
+		}
+
+		defer func() { _ = file.Close() }()
+
+		err = catalog.Load(file)
+		if err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to load IAM documents: %w", err)
+	}
+
+	return catalog, nil
+}
+
+// EdgeCheck is the verdict for one hop of a ring.
+type EdgeCheck struct {
+	Source string
+	Target string
+	// Trust is the target trust policy's decision for the source session.
+	Trust Result
+	// Identity is the combined decision of the source's permission policies, when one is needed.
+	Identity Result
+	// IdentityRequired is set when the trust policy delegates to the account, so the source also needs an
+	// identity policy granting sts:AssumeRole on the target.
+	IdentityRequired bool
+	Pass             bool
+	Reason           string
+}
+
+// CheckEdge reports whether a session of source with the given name may call sts:AssumeRole on target.
+//
+//nolint:cyclop,funlen
+func (c *Catalog) CheckEdge(source, target, sessionName string) EdgeCheck {
+	check := EdgeCheck{ //nolint:exhaustruct
+		Source: source,
+		Target: target,
+	}
+
+	sourceARN, err := arn.Parse(source)
+	if err != nil {
+		check.Reason = err.Error()
+
+		return check
+	}
+
+	session, err := arn.AssumedRole(source, sessionName)
+	if err != nil {
+		check.Reason = err.Error()
+
+		return check
+	}
+
+	targetRole, ok := c.Role(target)
+	if !ok {
+		check.Reason = "target role not found in policy documents"
+
+		return check
+	}
+
+	req := Request{
+		PrincipalARN: source,
+		SessionARN:   session,
+		Account:      sourceARN.Account,
+		Action:       AssumeRole,
+		Resource:     target,
+	}
+
+	check.Trust = targetRole.Trust.EvaluateTrust(req)
+
+	switch check.Trust.Decision {
+	case Denied:
+		check.Reason = "trust policy denies source (" + check.Trust.Statement + ")"
+
+		return check
+	case NotApplicable:
+		check.Reason = "trust policy does not allow source"
+		if len(check.Trust.Unsupported) > 0 {
+			check.Reason += "; unsupported conditions: " + strings.Join(check.Trust.Unsupported, ", ")
+		}
+
+		return check
+	case Allowed:
+	}
+
+	targetARN, err := arn.Parse(target)
+	if err != nil {
+		check.Reason = err.Error()
+
+		return check
+	}
+
+	// A trust policy naming the role grants access on its own within an account; trusting the account,
+	// or any account other than the caller's, also needs the caller's permission policies to allow it.
+	check.IdentityRequired = !check.Trust.Explicit || targetARN.Account != sourceARN.Account
+
+	sourceRole, found := c.Role(source)
+	check.Identity = Result{Decision: NotApplicable, Statement: "", Explicit: false, Unsupported: nil}
+
+	if found {
+		check.Identity = c.evaluateIdentity(sourceRole, req)
+	}
+
+	if check.Identity.Decision == Denied {
+		check.Reason = "source permissions deny sts:AssumeRole (" + check.Identity.Statement + ")"
+
+		return check
+	}
+
+	if check.IdentityRequired && check.Identity.Decision != Allowed {
+		check.Reason = "source permissions do not allow sts:AssumeRole on target"
+		if !found {
+			check.Reason = "source role not found in policy documents"
+		}
+
+		return check
+	}
+
+	check.Pass = true
+	check.Reason = "trusted by " + check.Trust.Statement
+	if check.IdentityRequired {
+		check.Reason += ", permitted by " + check.Identity.Statement
+	}
+
+	return check
+}
+
+func (c *Catalog) evaluateIdentity(role *Role, req Request) Result {
+	combined := Result{Decision: NotApplicable, Statement: "", Explicit: false, Unsupported: nil}
+
+	for _, policy := range c.Permissions(role) {
+		result := policy.EvaluateIdentity(req)
+		combined.Unsupported = append(combined.Unsupported, result.Unsupported...)
+
+		switch result.Decision {
+		case Denied:
+			result.Unsupported = combined.Unsupported
+
+			return result
+		case Allowed:
+			if combined.Decision != Allowed {
+				combined.Decision = Allowed
+				combined.Statement = result.Statement
+			}
+		case NotApplicable:
+		}
+	}
+
+	return combined
+}
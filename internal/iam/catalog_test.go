@@ -0,0 +1,158 @@
+// Copyright 2025 variHQ OÜ
+// SPDX-License-Identifier: BSD-3-Clause
+
+package iam_test
+
+import (
+	"errors"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/wakeful/trick/internal/iam"
+)
+
+const (
+	roleA = "arn:aws:iam::111111111111:role/role-a"
+	roleB = "arn:aws:iam::111111111111:role/role-b"
+	roleC = "arn:aws:iam::222222222222:role/path/role-c"
+)
+
+// authorizationDetails mimics get-account-authorization-details, including the URL-encoded documents the API
+// returns.
+func authorizationDetails() string {
+	trustB := url.PathEscape(`{"Version": "2012-10-17", "Statement": [{"Effect": "Allow", "Action": "sts:AssumeRole",
+		"Principal": {"AWS": "arn:aws:iam::111111111111:role/role-a"}}]}`)
+
+	return `{
+  "RoleDetailList": [
+    {
+      "Arn": "` + roleA + `",
+      "RoleName": "role-a",
+      "AssumeRolePolicyDocument": {"Version": "2012-10-17", "Statement": [{"Effect": "Allow", "Action": "sts:AssumeRole",
+        "Principal": {"AWS": "arn:aws:iam::222222222222:root"}}]},
+      "AttachedManagedPolicies": [{"PolicyArn": "arn:aws:iam::111111111111:policy/hop"}]
+    },
+    {
+      "Arn": "` + roleB + `",
+      "RoleName": "role-b",
+      "AssumeRolePolicyDocument": "` + trustB + `",
+      "RolePolicyList": []
+    }
+  ],
+  "Policies": [
+    {
+      "Arn": "arn:aws:iam::111111111111:policy/hop",
+      "DefaultVersionId": "v2",
+      "PolicyVersionList": [
+        {"VersionId": "v1", "IsDefaultVersion": false, "Document": {"Statement": []}},
+        {"VersionId": "v2", "IsDefaultVersion": true, "Document": {"Statement": [{"Effect": "Allow",
+          "Action": "sts:AssumeRole", "Resource": "arn:aws:iam::222222222222:role/*"}]}}
+      ]
+    }
+  ]
+}`
+}
+
+const getRoleC = `{"Role": {
+  "Arn": "arn:aws:iam::222222222222:role/path/role-c",
+  "RoleName": "role-c",
+  "AssumeRolePolicyDocument": {"Statement": [{"Effect": "Allow", "Action": "sts:AssumeRole",
+    "Principal": {"AWS": "arn:aws:iam::111111111111:root"},
+    "Condition": {"ArnEquals": {"aws:PrincipalArn": "arn:aws:iam::111111111111:role/role-b"}}}]}
+}}`
+
+const roleCPolicy = `{"RoleName": "role-c", "PolicyName": "hop", "PolicyDocument": {"Statement": [
+  {"Effect": "Allow", "Action": "sts:AssumeRole", "Resource": "*"}]}}`
+
+func loadCatalog(t *testing.T) *iam.Catalog {
+	t.Helper()
+
+	dir := t.TempDir()
+
+	for name, content := range map[string]string{
+		"account-111.json":     authorizationDetails(),
+		"nested/role-c.json":   getRoleC,
+		"nested/role-c-p.json": roleCPolicy,
+		"notes.txt":            "ignored",
+	} {
+		path := filepath.Join(dir, name)
+
+		err := os.MkdirAll(filepath.Dir(path), 0o750)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		err = os.WriteFile(path, []byte(content), 0o600)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	catalog, err := iam.LoadDir(dir)
+	if err != nil {
+		t.Fatalf("LoadDir() error = %v", err)
+	}
+
+	return catalog
+}
+
+func TestCatalog_CheckEdge(t *testing.T) {
+	t.Parallel()
+
+	catalog := loadCatalog(t)
+
+	tests := []struct {
+		name       string
+		source     string
+		target     string
+		want       bool
+		wantReason string
+	}{
+		{name: "same account role principal", source: roleA, target: roleB, want: true},
+		{name: "cross account with identity policy", source: roleB, target: roleC, want: false,
+			wantReason: "source permissions do not allow"},
+		{name: "wrap-around cross account", source: roleC, target: roleA, want: true},
+		{name: "trust names another role", source: roleA, target: roleC, want: false,
+			wantReason: "trust policy does not allow"},
+		{name: "unknown target", source: roleA, target: "arn:aws:iam::111111111111:role/missing", want: false,
+			wantReason: "target role not found"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got := catalog.CheckEdge(tt.source, tt.target, "trick")
+			if got.Pass != tt.want {
+				t.Errorf("CheckEdge() pass = %v, want %v (%s)", got.Pass, tt.want, got.Reason)
+			}
+
+			if !strings.Contains(got.Reason, tt.wantReason) {
+				t.Errorf("CheckEdge() reason = %q, want %q", got.Reason, tt.wantReason)
+			}
+		})
+	}
+}
+
+func TestCatalog_Load(t *testing.T) {
+	t.Parallel()
+
+	catalog := iam.NewCatalog()
+
+	err := catalog.Load(strings.NewReader(`{"Users": []}`))
+	if !errors.Is(err, iam.ErrUnknownDocument) {
+		t.Errorf("Load() error = %v, want %v", err, iam.ErrUnknownDocument)
+	}
+
+	err = catalog.Load(strings.NewReader(getRoleC))
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	if _, ok := catalog.Role("arn:aws:iam::222222222222:role/role-c"); !ok {
+		t.Error("Role() did not find role-c without its path")
+	}
+}
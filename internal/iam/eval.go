@@ -0,0 +1,270 @@
+// Copyright 2025 variHQ OÜ
+// SPDX-License-Identifier: BSD-3-Clause
+
+package iam
+
+import (
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Request is the context of an AssumeRole call as seen by policy evaluation.
+type Request struct {
+	// PrincipalARN is the calling role ARN, the value of aws:PrincipalArn.
+	PrincipalARN string
+	// SessionARN is the assumed-role session ARN of the caller.
+	SessionARN string
+	// Account is the caller's account, the value of aws:PrincipalAccount.
+	Account string
+	// Action is the API action, e.g. sts:AssumeRole.
+	Action string
+	// Resource is the ARN of the role being assumed.
+	Resource string
+}
+
+// Decision is the outcome of evaluating a policy against a request.
+type Decision int
+
+const (
+	// NotApplicable means no statement matched, which is an implicit deny.
+	NotApplicable Decision = iota
+	// Allowed means an Allow statement matched and no Deny statement did.
+	Allowed
+	// Denied means a Deny statement matched.
+	Denied
+)
+
+// String returns a short label for the decision.
+func (d Decision) String() string {
+	switch d {
+	case Allowed:
+		return "allow"
+	case Denied:
+		return "deny"
+	case NotApplicable:
+		return "implicit deny"
+	default:
+		return "unknown"
+	}
+}
+
+// Result explains a Decision.
+type Result struct {
+	Decision Decision
+	// Statement names the deciding statement by Sid or position.
+	Statement string
+	// Explicit is set when an allowing trust statement names the caller's ARN rather than its account.
+	Explicit bool
+	// Unsupported lists condition operators or keys that could not be evaluated offline. They are assumed to fail
+	// in Allow statements and to hold in Deny statements, so they never make a hop look possible.
+	Unsupported []string
+}
+
+type matchMode int
+
+const (
+	matchTrust matchMode = iota
+	matchIdentity
+)
+
+// EvaluateTrust evaluates p as a role trust policy: Principal and Action must match, Resource is implied.
+func (p *Policy) EvaluateTrust(req Request) Result {
+	return p.evaluate(req, matchTrust)
+}
+
+// EvaluateIdentity evaluates p as a permission policy attached to the caller: Action and Resource must match.
+func (p *Policy) EvaluateIdentity(req Request) Result {
+	return p.evaluate(req, matchIdentity)
+}
+
+func (p *Policy) evaluate(req Request, mode matchMode) Result {
+	result := Result{Decision: NotApplicable, Statement: "", Explicit: false, Unsupported: nil}
+
+	if p == nil {
+		return result
+	}
+
+	for idx, statement := range p.Statement {
+		name := statement.Sid
+		if name == "" {
+			name = "#" + strconv.Itoa(idx)
+		}
+
+		explicit := false
+
+		if mode == matchTrust {
+			var ok bool
+
+			ok, explicit = statement.Principal.matches(req)
+			if !ok {
+				continue
+			}
+		}
+
+		if !statement.matchesAction(req.Action) {
+			continue
+		}
+
+		if mode == matchIdentity && !statement.matchesResource(req.Resource) {
+			continue
+		}
+
+		met, unsupported := statement.Condition.matches(req, strings.EqualFold(statement.Effect, Deny))
+		result.Unsupported = append(result.Unsupported, unsupported...)
+
+		if !met {
+			continue
+		}
+
+		if strings.EqualFold(statement.Effect, Deny) {
+			result.Decision = Denied
+			result.Statement = name
+			result.Explicit = false
+
+			return result
+		}
+
+		if strings.EqualFold(statement.Effect, Allow) && (result.Decision != Allowed || explicit) {
+			result.Decision = Allowed
+			result.Statement = name
+			result.Explicit = explicit
+		}
+	}
+
+	return result
+}
+
+// matches reports whether the principal covers the caller, and whether it does so by naming its ARN.
+func (p *Principal) matches(req Request) (bool, bool) {
+	if p == nil {
+		return false, false
+	}
+
+	if p.Any {
+		return true, false
+	}
+
+	for _, entry := range p.AWS {
+		switch {
+		case entry == "*":
+			return true, false
+		case entry == req.PrincipalARN || entry == req.SessionARN:
+			return true, true
+		case entry == req.Account || entry == accountRoot(req.PrincipalARN):
+			return true, false
+		}
+	}
+
+	return false, false
+}
+
+func accountRoot(principalARN string) string {
+	const fields = 6
+
+	parts := strings.SplitN(principalARN, ":", fields)
+	if len(parts) != fields {
+		return ""
+	}
+
+	return "arn:" + parts[1] + ":iam::" + parts[4] + ":root"
+}
+
+func (s Statement) matchesAction(action string) bool {
+	if len(s.NotAction) > 0 {
+		return !anyWildcard(s.NotAction, action, true)
+	}
+
+	return anyWildcard(s.Action, action, true)
+}
+
+func (s Statement) matchesResource(resource string) bool {
+	if len(s.NotResource) > 0 {
+		return !anyWildcard(s.NotResource, resource, false)
+	}
+
+	return anyWildcard(s.Resource, resource, false)
+}
+
+// contextValue returns the request value for a global condition key.
+func contextValue(req Request, key string) (string, bool) {
+	switch strings.ToLower(key) {
+	case "aws:principalarn":
+		return req.PrincipalARN, req.PrincipalARN != ""
+	case "aws:principalaccount":
+		return req.Account, req.Account != ""
+	case "aws:principaltype":
+		return "AssumedRole", true
+	default:
+		return "", false
+	}
+}
+
+// matches evaluates every condition block; all of them have to hold. A pair that cannot be evaluated offline counts
+// as unknown.
+func (c Condition) matches(req Request, unknown bool) (bool, []string) {
+	unsupported := make([]string, 0)
+	met := true
+
+	for operator, keys := range c {
+		for key, values := range keys {
+			ok, known := evalCondition(operator, key, values, req)
+			if !known {
+				unsupported = append(unsupported, operator+":"+key)
+				ok = unknown
+			}
+
+			met = met && ok
+		}
+	}
+
+	slices.Sort(unsupported)
+
+	return met, unsupported
+}
+
+// evalCondition evaluates a single operator/key pair and reports whether it could be evaluated.
+//
+//nolint:cyclop
+func evalCondition(operator, key string, values []string, req Request) (bool, bool) {
+	base := operator
+	if idx := strings.Index(base, ":"); idx >= 0 {
+		base = base[idx+1:]
+	}
+
+	ifExists := strings.HasSuffix(base, "IfExists")
+	base = strings.TrimSuffix(base, "IfExists")
+
+	value, present := contextValue(req, key)
+
+	if base == "Null" {
+		wantMissing := slices.Contains(values, "true")
+
+		return wantMissing != present, true
+	}
+
+	if !present {
+		if strings.HasPrefix(strings.ToLower(key), "aws:principal") {
+			return ifExists, true
+		}
+
+		return false, false
+	}
+
+	switch base {
+	case "StringEquals", "ArnEquals":
+		return slices.Contains(values, value), true
+	case "StringNotEquals", "ArnNotEquals":
+		return !slices.Contains(values, value), true
+	case "StringEqualsIgnoreCase":
+		return slices.ContainsFunc(values, func(v string) bool { return strings.EqualFold(v, value) }), true
+	case "StringNotEqualsIgnoreCase":
+		return !slices.ContainsFunc(values, func(v string) bool { return strings.EqualFold(v, value) }), true
+	case "StringLike", "ArnLike":
+		return anyWildcard(values, value, false), true
+	case "StringNotLike", "ArnNotLike":
+		return !anyWildcard(values, value, false), true
+	default:
+		return false, false
+	}
+}
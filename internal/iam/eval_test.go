@@ -0,0 +1,193 @@
+// Copyright 2025 variHQ OÜ
+// SPDX-License-Identifier: BSD-3-Clause
+
+package iam_test
+
+import (
+	"encoding/json"
+	"net/url"
+	"testing"
+
+	"github.com/wakeful/trick/internal/iam"
+)
+
+func mustPolicy(t *testing.T, source string) *iam.Policy {
+	t.Helper()
+
+	var policy iam.Policy
+
+	err := json.Unmarshal([]byte(source), &policy)
+	if err != nil {
+		t.Fatalf("invalid policy: %v", err)
+	}
+
+	return &policy
+}
+
+func TestPolicy_EvaluateTrust(t *testing.T) {
+	t.Parallel()
+
+	req := iam.Request{
+		PrincipalARN: "arn:aws:iam::111111111111:role/role-a",
+		SessionARN:   "arn:aws:sts::111111111111:assumed-role/role-a/trick",
+		Account:      "111111111111",
+		Action:       iam.AssumeRole,
+		Resource:     "arn:aws:iam::111111111111:role/role-b",
+	}
+
+	tests := []struct {
+		name         string
+		policy       string
+		want         iam.Decision
+		wantExplicit bool
+	}{
+		{
+			name: "role principal",
+			policy: `{"Statement": {"Effect": "Allow", "Action": "sts:AssumeRole",
+				"Principal": {"AWS": "arn:aws:iam::111111111111:role/role-a"}}}`,
+			want:         iam.Allowed,
+			wantExplicit: true,
+		},
+		{
+			name: "other role",
+			policy: `{"Statement": [{"Effect": "Allow", "Action": "sts:AssumeRole",
+				"Principal": {"AWS": ["arn:aws:iam::111111111111:role/role-c"]}}]}`,
+			want: iam.NotApplicable,
+		},
+		{
+			name: "account root with principal arn condition",
+			policy: `{"Statement": [{"Effect": "Allow", "Action": "sts:*",
+				"Principal": {"AWS": "arn:aws:iam::111111111111:root"},
+				"Condition": {"ArnLike": {"aws:PrincipalArn": "arn:aws:iam::111111111111:role/role-*"}}}]}`,
+			want: iam.Allowed,
+		},
+		{
+			name: "condition not met",
+			policy: `{"Statement": [{"Effect": "Allow", "Action": "sts:AssumeRole",
+				"Principal": {"AWS": "111111111111"},
+				"Condition": {"StringEquals": {"aws:PrincipalArn": "arn:aws:iam::111111111111:role/role-c"}}}]}`,
+			want: iam.NotApplicable,
+		},
+		{
+			name: "wildcard principal limited by account",
+			policy: `{"Statement": [{"Effect": "Allow", "Action": "sts:AssumeRole", "Principal": "*",
+				"Condition": {"StringEquals": {"aws:PrincipalAccount": "111111111111"}}}]}`,
+			want: iam.Allowed,
+		},
+		{
+			name: "explicit deny wins",
+			policy: `{"Statement": [
+				{"Effect": "Allow", "Action": "sts:AssumeRole", "Principal": {"AWS": "arn:aws:iam::111111111111:role/role-a"}},
+				{"Sid": "NoA", "Effect": "Deny", "Action": "sts:AssumeRole", "Principal": "*",
+					"Condition": {"StringNotLike": {"aws:PrincipalArn": "*role-b"}}}]}`,
+			want: iam.Denied,
+		},
+		{
+			name: "unsupported condition key",
+			policy: `{"Statement": [{"Effect": "Allow", "Action": "sts:AssumeRole", "Principal": "*",
+				"Condition": {"StringEquals": {"sts:ExternalId": "secret"}}}]}`,
+			want: iam.NotApplicable,
+		},
+		{
+			name: "unsupported condition key on a deny",
+			policy: `{"Statement": [
+				{"Effect": "Allow", "Action": "sts:AssumeRole", "Principal": {"AWS": "arn:aws:iam::111111111111:role/role-a"}},
+				{"Effect": "Deny", "Action": "sts:AssumeRole", "Principal": "*",
+					"Condition": {"Bool": {"aws:MultiFactorAuthPresent": "false"}}}]}`,
+			want: iam.Denied,
+		},
+		{
+			name: "service principal",
+			policy: `{"Statement": [{"Effect": "Allow", "Action": "sts:AssumeRole",
+				"Principal": {"Service": "ec2.amazonaws.com"}}]}`,
+			want: iam.NotApplicable,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got := mustPolicy(t, tt.policy).EvaluateTrust(req)
+			if got.Decision != tt.want {
+				t.Errorf("EvaluateTrust() = %v, want %v", got.Decision, tt.want)
+			}
+
+			if got.Explicit != tt.wantExplicit {
+				t.Errorf("EvaluateTrust() explicit = %v, want %v", got.Explicit, tt.wantExplicit)
+			}
+		})
+	}
+}
+
+func TestPolicy_EvaluateIdentity(t *testing.T) {
+	t.Parallel()
+
+	req := iam.Request{
+		PrincipalARN: "arn:aws:iam::111111111111:role/role-a",
+		SessionARN:   "arn:aws:sts::111111111111:assumed-role/role-a/trick",
+		Account:      "111111111111",
+		Action:       iam.AssumeRole,
+		Resource:     "arn:aws:iam::222222222222:role/role-b",
+	}
+
+	tests := []struct {
+		name   string
+		policy string
+		want   iam.Decision
+	}{
+		{
+			name:   "resource wildcard",
+			policy: `{"Statement": [{"Effect": "Allow", "Action": "STS:AssumeRole", "Resource": "arn:aws:iam::*:role/role-?"}]}`,
+			want:   iam.Allowed,
+		},
+		{
+			name:   "other resource",
+			policy: `{"Statement": [{"Effect": "Allow", "Action": "sts:AssumeRole", "Resource": "arn:aws:iam::*:role/other"}]}`,
+			want:   iam.NotApplicable,
+		},
+		{
+			name:   "not action",
+			policy: `{"Statement": [{"Effect": "Deny", "NotAction": "s3:*", "Resource": "*"}]}`,
+			want:   iam.Denied,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			if got := mustPolicy(t, tt.policy).EvaluateIdentity(req); got.Decision != tt.want {
+				t.Errorf("EvaluateIdentity() = %v, want %v", got.Decision, tt.want)
+			}
+		})
+	}
+}
+
+func TestDocument_UnmarshalJSON(t *testing.T) {
+	t.Parallel()
+
+	const principal = "arn:aws:iam::111111111111:role/ops+admin"
+
+	encoded, err := json.Marshal(url.PathEscape(`{"Version": "2012-10-17", "Statement": [{"Effect": "Allow",
+		"Action": "sts:AssumeRole", "Principal": {"AWS": "` + principal + `"}}]}`))
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+
+	var document iam.Document
+
+	err = json.Unmarshal(encoded, &document)
+	if err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+
+	got := document.EvaluateTrust(iam.Request{ //nolint:exhaustruct
+		PrincipalARN: principal,
+		Account:      "111111111111",
+		Action:       iam.AssumeRole,
+	})
+	if got.Decision != iam.Allowed {
+		t.Errorf("EvaluateTrust() = %v, want %v for a role name with a +", got.Decision, iam.Allowed)
+	}
+}
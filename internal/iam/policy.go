@@ -0,0 +1,249 @@
+// Copyright 2025 variHQ OÜ
+// SPDX-License-Identifier: BSD-3-Clause
+
+package iam
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"slices"
+	"strings"
+)
+
+// Version is the current IAM policy language version.
+const Version = "2012-10-17"
+
+const (
+	// Allow is the Effect of a statement that grants access.
+	Allow = "Allow"
+	// Deny is the Effect of a statement that refuses access.
+	Deny = "Deny"
+)
+
+// StringList is a policy value that may be written as a single string or as an array of strings.
+type StringList []string
+
+// UnmarshalJSON accepts both "value" and ["value", ...].
+func (s *StringList) UnmarshalJSON(data []byte) error {
+	if bytes.HasPrefix(bytes.TrimSpace(data), []byte("[")) {
+		var list []string
+
+		err := json.Unmarshal(data, &list)
+		if err != nil {
+			return fmt.Errorf("invalid string list: %w", err)
+		}
+
+		*s = list
+
+		return nil
+	}
+
+	var single string
+
+	err := json.Unmarshal(data, &single)
+	if err != nil {
+		return fmt.Errorf("invalid string list: %w", err)
+	}
+
+	*s = StringList{single}
+
+	return nil
+}
+
+// MarshalJSON writes a single value as a plain string, as AWS does.
+func (s StringList) MarshalJSON() ([]byte, error) {
+	if len(s) == 1 {
+		return json.Marshal(s[0]) //nolint:wrapcheck
+	}
+
+	return json.Marshal([]string(s)) //nolint:wrapcheck
+}
+
+// Principal is the Principal element of a statement; "*" sets Any.
+type Principal struct {
+	Any       bool       `json:"-"`
+	AWS       StringList `json:"AWS,omitempty"`
+	Service   StringList `json:"Service,omitempty"`
+	Federated StringList `json:"Federated,omitempty"`
+}
+
+// UnmarshalJSON accepts both "*" and the object form.
+func (p *Principal) UnmarshalJSON(data []byte) error {
+	var wildcard string
+	if json.Unmarshal(data, &wildcard) == nil {
+		p.Any = wildcard == "*"
+
+		return nil
+	}
+
+	type plain Principal
+
+	var decoded plain
+
+	err := json.Unmarshal(data, &decoded)
+	if err != nil {
+		return fmt.Errorf("invalid principal: %w", err)
+	}
+
+	*p = Principal(decoded)
+
+	return nil
+}
+
+// MarshalJSON writes "*" for Any and the object form otherwise.
+func (p Principal) MarshalJSON() ([]byte, error) {
+	if p.Any {
+		return []byte(`"*"`), nil
+	}
+
+	type plain Principal
+
+	return json.Marshal(plain(p)) //nolint:wrapcheck
+}
+
+// Condition maps an operator such as StringLike to the context keys and values it tests.
+type Condition map[string]map[string]StringList
+
+// Statement is a single policy statement.
+type Statement struct {
+	Sid         string     `json:"Sid,omitempty"`
+	Effect      string     `json:"Effect"`
+	Principal   *Principal `json:"Principal,omitempty"`
+	Action      StringList `json:"Action,omitempty"`
+	NotAction   StringList `json:"NotAction,omitempty"`
+	Resource    StringList `json:"Resource,omitempty"`
+	NotResource StringList `json:"NotResource,omitempty"`
+	Condition   Condition  `json:"Condition,omitempty"`
+}
+
+// Statements is the Statement element, which may be a single object or an array.
+type Statements []Statement
+
+// UnmarshalJSON accepts both a single statement and an array of statements.
+func (s *Statements) UnmarshalJSON(data []byte) error {
+	if bytes.HasPrefix(bytes.TrimSpace(data), []byte("[")) {
+		var list []Statement
+
+		err := json.Unmarshal(data, &list)
+		if err != nil {
+			return fmt.Errorf("invalid statements: %w", err)
+		}
+
+		*s = list
+
+		return nil
+	}
+
+	var single Statement
+
+	err := json.Unmarshal(data, &single)
+	if err != nil {
+		return fmt.Errorf("invalid statement: %w", err)
+	}
+
+	*s = Statements{single}
+
+	return nil
+}
+
+// Policy is an IAM policy document, used for both trust and permission policies.
+type Policy struct {
+	Version   string     `json:"Version"`
+	ID        string     `json:"Id,omitempty"`
+	Statement Statements `json:"Statement"`
+}
+
+// Document is a policy embedded in API output, either as an object or as a URL-encoded JSON string.
+type Document struct {
+	*Policy
+}
+
+// UnmarshalJSON decodes both the object form and the URL-encoded string form returned by the IAM API. The string is
+// percent-decoded only, since role names and paths may contain a literal +.
+func (d *Document) UnmarshalJSON(data []byte) error {
+	raw := data
+
+	var encoded string
+	if json.Unmarshal(data, &encoded) == nil {
+		decoded, err := url.PathUnescape(encoded)
+		if err != nil {
+			return fmt.Errorf("invalid policy encoding: %w", err)
+		}
+
+		raw = []byte(decoded)
+	}
+
+	var policy Policy
+
+	err := json.Unmarshal(raw, &policy)
+	if err != nil {
+		return fmt.Errorf("invalid policy document: %w", err)
+	}
+
+	d.Policy = &policy
+
+	return nil
+}
+
+// MarshalJSON writes the embedded policy as an object.
+func (d Document) MarshalJSON() ([]byte, error) {
+	return json.Marshal(d.Policy) //nolint:wrapcheck
+}
+
+// Marshal renders the policy as indented JSON.
+func (p *Policy) Marshal() (string, error) {
+	out, err := json.MarshalIndent(p, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to encode policy: %w", err)
+	}
+
+	return string(out), nil
+}
+
+// matchWildcard reports whether value matches pattern, where * matches any run of characters and
+// ? a single character.
+func matchWildcard(pattern, value string, ignoreCase bool) bool {
+	if ignoreCase {
+		pattern = strings.ToLower(pattern)
+		value = strings.ToLower(value)
+	}
+
+	if !strings.ContainsAny(pattern, "*?") {
+		return pattern == value
+	}
+
+	p, v := []rune(pattern), []rune(value)
+	star, match := -1, 0
+	pi, vi := 0, 0
+
+	for vi < len(v) {
+		switch {
+		case pi < len(p) && (p[pi] == '?' || p[pi] == v[vi]):
+			pi++
+			vi++
+		case pi < len(p) && p[pi] == '*':
+			star, match = pi, vi
+			pi++
+		case star >= 0:
+			pi = star + 1
+			match++
+			vi = match
+		default:
+			return false
+		}
+	}
+
+	for pi < len(p) && p[pi] == '*' {
+		pi++
+	}
+
+	return pi == len(p)
+}
+
+func anyWildcard(patterns []string, value string, ignoreCase bool) bool {
+	return slices.ContainsFunc(patterns, func(pattern string) bool {
+		return matchWildcard(pattern, value, ignoreCase)
+	})
+}
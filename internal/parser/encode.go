@@ -0,0 +1,130 @@
+// Copyright 2025 variHQ OÜ
+// SPDX-License-Identifier: BSD-3-Clause
+
+package parser
+
+import (
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// Encode renders the config as HCL in the layout of example.config.hcl. Optional attributes are only written
+// when they differ from their zero value.
+func (c *Config) Encode() []byte {
+	file := hclwrite.NewEmptyFile()
+	body := file.Body()
+
+	if c.SelectProfile != "" {
+		body.SetAttributeTraversal("select_profile", hcl.Traversal{
+			hcl.TraverseRoot{Name: "profile", SrcRange: hcl.Range{}},
+			hcl.TraverseAttr{Name: c.SelectProfile, SrcRange: hcl.Range{}},
+		})
+	}
+
+	for _, profile := range c.Profiles {
+		body.AppendNewline()
+		profile.encode(body.AppendNewBlock("profile", []string{profile.Name}).Body())
+	}
+
+	return hclwrite.Format(file.Bytes())
+}
+
+func (p *Profile) encode(body *hclwrite.Body) {
+	if p.Region != "" {
+		body.SetAttributeValue("region", cty.StringVal(p.Region))
+	}
+
+	if p.Chain != nil {
+		if p.Region != "" {
+			body.AppendNewline()
+		}
+
+		p.Chain.encode(body.AppendNewBlock("chain", nil).Body())
+	}
+
+	if p.Guardrails != nil {
+		body.AppendNewline()
+		p.Guardrails.encode(body.AppendNewBlock("guardrails", nil).Body())
+	}
+
+	if p.Server != nil {
+		body.AppendNewline()
+		p.Server.encode(body.AppendNewBlock("server", nil).Body())
+	}
+}
+
+func (c *Chain) encode(body *hclwrite.Body) {
+	if c.TTL != 0 {
+		body.SetAttributeValue("ttl", cty.NumberIntVal(c.TTL))
+	}
+
+	for idx, role := range c.UseRoles {
+		if idx > 0 || c.TTL != 0 {
+			body.AppendNewline()
+		}
+
+		use := body.AppendNewBlock("use", nil).Body()
+		use.SetAttributeValue("arn", cty.StringVal(role.ARN))
+
+		if role.Skip {
+			use.SetAttributeValue("skip", cty.True)
+		}
+
+		if role.Entry {
+			use.SetAttributeValue("entry", cty.True)
+		}
+	}
+}
+
+func (g *Guardrails) encode(body *hclwrite.Body) {
+	if g.NotAfter != "" {
+		body.SetAttributeValue("not_after", cty.StringVal(g.NotAfter))
+	}
+
+	if len(g.AllowedAccounts) > 0 {
+		accounts := make([]cty.Value, 0, len(g.AllowedAccounts))
+		for _, account := range g.AllowedAccounts {
+			accounts = append(accounts, cty.StringVal(account))
+		}
+
+		body.SetAttributeValue("allowed_accounts", cty.ListVal(accounts))
+	}
+
+	if g.MaxAssumesPerDay != 0 {
+		body.SetAttributeValue("max_assumes_per_day", cty.NumberIntVal(int64(g.MaxAssumesPerDay)))
+	}
+
+	if g.OnExpire != "" {
+		body.SetAttributeValue("on_expire", cty.StringVal(g.OnExpire))
+	}
+}
+
+func (s *Server) encode(body *hclwrite.Body) {
+	for _, attr := range []struct{ name, value string }{
+		{"listen", s.Listen},
+		{"socket", s.Socket},
+		{"token", s.Token},
+		{"basic_auth", s.BasicAuth},
+		{"tls_cert", s.TLSCert},
+		{"tls_key", s.TLSKey},
+	} {
+		if attr.value != "" {
+			body.SetAttributeValue(attr.name, cty.StringVal(attr.value))
+		}
+	}
+
+	if s.TLSSelfSigned {
+		body.SetAttributeValue("tls_self_signed", cty.True)
+	}
+
+	for _, attr := range []struct{ name, value string }{
+		{"read_timeout", s.ReadTimeout},
+		{"write_timeout", s.WriteTimeout},
+		{"idle_timeout", s.IdleTimeout},
+	} {
+		if attr.value != "" {
+			body.SetAttributeValue(attr.name, cty.StringVal(attr.value))
+		}
+	}
+}
@@ -0,0 +1,88 @@
+// Copyright 2025 variHQ OÜ
+// SPDX-License-Identifier: BSD-3-Clause
+
+package parser_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/wakeful/trick/internal/parser"
+)
+
+func TestConfig_Encode(t *testing.T) {
+	t.Parallel()
+
+	want, err := parser.ParseFile("./example.config.hcl")
+	if err != nil {
+		t.Fatalf("ParseFile() error = %v", err)
+	}
+
+	want.Profiles[0].Chain.UseRoles[1].Entry = true
+	want.Profiles[0].Guardrails = &parser.Guardrails{
+		NotAfter:         "2025-12-31",
+		AllowedAccounts:  []string{"42"},
+		MaxAssumesPerDay: 100,
+		OnExpire:         parser.OnExpireStopAndWipe,
+	}
+	want.Profiles[0].Server = &parser.Server{
+		Listen:        "0.0.0.0:8742",
+		BasicAuth:     "red:team",
+		TLSSelfSigned: true,
+		WriteTimeout:  "2m",
+	}
+
+	encoded := want.Encode()
+
+	got, err := parser.Parse(encoded)
+	if err != nil {
+		t.Fatalf("Parse() error = %v\n%s", err, encoded)
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Parse(Encode()) = %+v, want %+v\n%s", got, want, encoded)
+	}
+}
+
+func TestConfig_Encode_layout(t *testing.T) {
+	t.Parallel()
+
+	config := &parser.Config{
+		SelectProfile: "ring",
+		Profiles: []*parser.Profile{{
+			Name:   "ring",
+			Region: "eu-west-1",
+			Chain: &parser.Chain{
+				TTL: 12,
+				UseRoles: []*parser.UseRoles{
+					{ARN: "arn::42::role-a"},
+					{ARN: "arn::42::role-b", Skip: true},
+				},
+			},
+		}},
+	}
+
+	want := `select_profile = profile.ring
+
+profile "ring" {
+  region = "eu-west-1"
+
+  chain {
+    ttl = 12
+
+    use {
+      arn = "arn::42::role-a"
+    }
+
+    use {
+      arn  = "arn::42::role-b"
+      skip = true
+    }
+  }
+}
+`
+
+	if got := string(config.Encode()); got != want {
+		t.Errorf("Encode() =\n%s\nwant\n%s", got, want)
+	}
+}
@@ -0,0 +1,143 @@
+// Copyright 2025 variHQ OÜ
+// SPDX-License-Identifier: BSD-3-Clause
+
+package parser
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// FormatOptions tune Format beyond the canonical layout.
+type FormatOptions struct {
+	// DropDefaults removes attributes that are set to the value they default to, such as skip = false.
+	DropDefaults bool
+}
+
+// defaults lists the attributes setDefault and the decoder would fill in, per block type.
+func defaults() map[string]map[string]cty.Value {
+	return map[string]map[string]cty.Value{
+		"profile":    {"region": cty.StringVal("eu-west-1")},
+		"chain":      {"ttl": cty.NumberIntVal(defaultTLL)},
+		"use":        {"skip": cty.False, "entry": cty.False},
+		"guardrails": {"on_expire": cty.StringVal(OnExpireStop), "max_assumes_per_day": cty.NumberIntVal(0)},
+		"server":     {"tls_self_signed": cty.False},
+	}
+}
+
+// Format rewrites config file content in canonical form: hclwrite layout, attributes of use blocks in alphabetical
+// order and, when asked, without attributes set to their default. Comments are kept.
+func Format(content []byte, filename string, opts FormatOptions) ([]byte, error) {
+	file, diag := hclwrite.ParseConfig(content, filename, hcl.InitialPos)
+	if diag.HasErrors() {
+		return nil, fmt.Errorf("failed to parse config: %w", diag)
+	}
+
+	for _, profile := range file.Body().Blocks() {
+		if profile.Type() != "profile" {
+			continue
+		}
+
+		formatBlock(profile, opts)
+
+		for _, block := range profile.Body().Blocks() {
+			formatBlock(block, opts)
+
+			if block.Type() != "chain" {
+				continue
+			}
+
+			for _, use := range block.Body().Blocks() {
+				if use.Type() == "use" {
+					formatBlock(use, opts)
+					sortAttributes(use.Body())
+				}
+			}
+		}
+	}
+
+	return hclwrite.Format(file.Bytes()), nil
+}
+
+func formatBlock(block *hclwrite.Block, opts FormatOptions) {
+	if !opts.DropDefaults {
+		return
+	}
+
+	body := block.Body()
+
+	for name, value := range defaults()[block.Type()] {
+		attr := body.GetAttribute(name)
+		if attr == nil {
+			continue
+		}
+
+		expr, diag := hclsyntax.ParseExpression(attr.Expr().BuildTokens(nil).Bytes(), "", hcl.InitialPos)
+		if diag.HasErrors() {
+			continue
+		}
+
+		got, diag := expr.Value(nil)
+		if diag.HasErrors() || !got.Type().Equals(value.Type()) || !got.Equals(value).True() {
+			continue
+		}
+
+		body.RemoveAttribute(name)
+	}
+}
+
+// sortAttributes orders the attributes of a body made of attributes only; bodies holding blank lines, nested blocks
+// or free-standing comments are left alone so nothing is lost.
+func sortAttributes(body *hclwrite.Body) {
+	attributes := body.Attributes()
+	names := make([]string, 0, len(attributes))
+	size := 0
+
+	for name, attr := range attributes {
+		names = append(names, name)
+		size += len(attr.BuildTokens(nil))
+	}
+
+	// The body opens with the newline that follows the brace.
+	tokens := body.BuildTokens(nil)
+	if len(body.Blocks()) > 0 || len(tokens) == 0 || tokens[0].Type != hclsyntax.TokenNewline || size != len(tokens)-1 {
+		return
+	}
+
+	slices.Sort(names)
+	body.Clear()
+	body.AppendNewline()
+
+	for _, name := range names {
+		body.AppendUnstructuredTokens(attributes[name].BuildTokens(nil))
+	}
+}
+
+// FromFlags is the inverse of ToFlags: it builds a config with a single selected profile from command line values,
+// writing the roles passed with -use as skip = true, just as ToFlags reads them back.
+func FromFlags(name, region string, ttl int64, roles, useRoles []string) *Config {
+	chain := &Chain{TTL: ttl, UseRoles: make([]*UseRoles, 0, len(roles))}
+
+	for _, role := range roles {
+		chain.UseRoles = append(chain.UseRoles, &UseRoles{
+			ARN:   role,
+			Skip:  slices.Contains(useRoles, role),
+			Entry: false,
+		})
+	}
+
+	return &Config{
+		SelectProfile: name,
+		Profiles: []*Profile{{
+			Name:       name,
+			Region:     region,
+			Chain:      chain,
+			Guardrails: nil,
+		}},
+	}
+}
@@ -0,0 +1,139 @@
+// Copyright 2025 variHQ OÜ
+// SPDX-License-Identifier: BSD-3-Clause
+
+package parser_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/wakeful/trick/internal/parser"
+)
+
+const unformatted = `select_profile=profile.lab
+# engagement ring
+profile "lab" {
+    region = "eu-west-1"
+  chain {
+ttl = 12
+    use {
+      skip = false # Defaults to false
+      arn = "arn::42::role-a"
+    }
+    use {
+      entry = true
+      arn="arn::42::role-b"
+      skip=true
+    }
+  }
+  guardrails {
+    on_expire = "stop"
+    not_after = "2025-12-31"
+  }
+}
+`
+
+func TestFormat(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		opts parser.FormatOptions
+		want string
+	}{
+		{
+			name: "canonical layout",
+			opts: parser.FormatOptions{DropDefaults: false},
+			want: `select_profile = profile.lab
+# engagement ring
+profile "lab" {
+  region = "eu-west-1"
+  chain {
+    ttl = 12
+    use {
+      arn  = "arn::42::role-a"
+      skip = false # Defaults to false
+    }
+    use {
+      arn   = "arn::42::role-b"
+      entry = true
+      skip  = true
+    }
+  }
+  guardrails {
+    on_expire = "stop"
+    not_after = "2025-12-31"
+  }
+}
+`,
+		},
+		{
+			name: "drop defaults",
+			opts: parser.FormatOptions{DropDefaults: true},
+			want: `select_profile = profile.lab
+# engagement ring
+profile "lab" {
+  chain {
+    use {
+      arn = "arn::42::role-a"
+    }
+    use {
+      arn   = "arn::42::role-b"
+      entry = true
+      skip  = true
+    }
+  }
+  guardrails {
+    not_after = "2025-12-31"
+  }
+}
+`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := parser.Format([]byte(unformatted), "config.hcl", tt.opts)
+			if err != nil {
+				t.Fatalf("Format() error = %v", err)
+			}
+
+			if string(got) != tt.want {
+				t.Errorf("Format() =\n%s\nwant\n%s", got, tt.want)
+			}
+
+			again, err := parser.Format(got, "config.hcl", tt.opts)
+			if err != nil || string(again) != string(got) {
+				t.Errorf("Format() is not idempotent:\n%s", again)
+			}
+
+			_, err = parser.Parse(got)
+			if err != nil {
+				t.Errorf("Parse(Format()) error = %v", err)
+			}
+		})
+	}
+}
+
+func TestFromFlags(t *testing.T) {
+	t.Parallel()
+
+	roles := []string{"arn::42::role-a", "arn::42::role-b", "arn::42::role-c"}
+	usable := []string{"arn::42::role-b"}
+
+	config, err := parser.Parse(parser.FromFlags("cli", "eu-west-1", 15, roles, usable).Encode())
+	if err != nil {
+		t.Fatalf("Parse(FromFlags()) error = %v", err)
+	}
+
+	ttl, gotRoles, gotUsable, err := config.ToFlags()
+	if err != nil {
+		t.Fatalf("ToFlags() error = %v", err)
+	}
+
+	if ttl != 15 || !reflect.DeepEqual(gotRoles, roles) || !reflect.DeepEqual(gotUsable, usable) {
+		t.Errorf("ToFlags(FromFlags()) = %d, %v, %v", ttl, gotRoles, gotUsable)
+	}
+}
@@ -7,6 +7,9 @@ import (
 	"fmt"
 	"log/slog"
 	"os"
+	"path/filepath"
+	"strings"
+	"time"
 
 	"github.com/hashicorp/hcl/v2"
 	"github.com/hashicorp/hcl/v2/hclparse"
@@ -14,22 +17,212 @@ import (
 	"github.com/zclconf/go-cty/cty"
 )
 
+// ParseFile reads, defaults and validates the config file at path.
 func ParseFile(path string) (*Config, error) {
+	conf, err := ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	err = conf.Complete()
+	if err != nil {
+		return nil, err
+	}
+
+	return conf, nil
+}
+
+// ReadFile decodes the config file at path, picking the syntax from its extension: .json and .yaml/.yml files are
+// decoded as by ParseJSON and ParseYAML, anything else as HCL. Unlike ParseFile it leaves defaults unset and skips
+// validation, so callers can tell what the file itself sets before calling Complete.
+func ReadFile(path string) (*Config, error) {
 	content, err := os.ReadFile(path) //nolint:gosec
 	if err != nil {
 		return nil, fmt.Errorf("failed to read config file: %w", err)
 	}
 
-	conf, err := decode(content)
+	decoder := decodeHCL
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		decoder = decodeJSON
+	case ".yaml", ".yml":
+		decoder = decodeYAML
+	}
+
+	conf, err := decoder(content)
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse config file: %w", err)
 	}
 
-	setDefault(conf)
+	return conf, nil
+}
+
+// Complete fills in the defaults of every setting the config leaves unset and validates the result.
+func (c *Config) Complete() error {
+	setDefault(c)
+
+	return validate(c)
+}
+
+// Parse decodes, defaults and validates HCL config file content.
+func Parse(content []byte) (*Config, error) {
+	return parse(content, decodeHCL)
+}
+
+// ParseJSON decodes, defaults and validates JSON config file content, written either in HCL's JSON syntax (a
+// "profile" object keyed by name) or as plain JSON (a "profiles" list).
+func ParseJSON(content []byte) (*Config, error) {
+	return parse(content, decodeJSON)
+}
+
+// ParseYAML decodes, defaults and validates YAML config file content, laid out like plain JSON.
+func ParseYAML(content []byte) (*Config, error) {
+	return parse(content, decodeYAML)
+}
+
+func parse(content []byte, decoder func([]byte) (*Config, error)) (*Config, error) {
+	conf, err := decoder(content)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse config file: %w", err)
+	}
+
+	err = conf.Complete()
+	if err != nil {
+		return nil, err
+	}
 
 	return conf, nil
 }
 
+func decodeHCL(content []byte) (*Config, error) {
+	return decode(content, "config.hcl")
+}
+
+// Selected returns the profile named by select_profile.
+func (c *Config) Selected() (*Profile, error) {
+	if c.SelectProfile == "" {
+		return nil, ErrProfileNotSelected
+	}
+
+	for _, profile := range c.Profiles {
+		if profile.Name == c.SelectProfile {
+			return profile, nil
+		}
+	}
+
+	return nil, fmt.Errorf("%w: %q", ErrProfileNotFound, c.SelectProfile)
+}
+
+// Deadline parses not_after, accepting either an RFC 3339 timestamp or a date meaning midnight UTC.
+// The zero time is returned when no kill date is configured.
+func (g *Guardrails) Deadline() (time.Time, error) {
+	if g == nil || g.NotAfter == "" {
+		return time.Time{}, nil
+	}
+
+	deadline, err := time.Parse(time.RFC3339, g.NotAfter)
+	if err == nil {
+		return deadline, nil
+	}
+
+	deadline, err = time.Parse(time.DateOnly, g.NotAfter)
+	if err == nil {
+		return deadline, nil
+	}
+
+	return time.Time{}, fmt.Errorf("%w: %q", ErrInvalidNotAfter, g.NotAfter)
+}
+
+// Timeouts parses read_timeout, write_timeout and idle_timeout; a timeout that is not set is returned as zero.
+func (s *Server) Timeouts() (time.Duration, time.Duration, time.Duration, error) {
+	if s == nil {
+		return 0, 0, 0, nil
+	}
+
+	timeouts := make([]time.Duration, 0, 3) //nolint:mnd
+
+	for _, value := range []string{s.ReadTimeout, s.WriteTimeout, s.IdleTimeout} {
+		if value == "" {
+			timeouts = append(timeouts, 0)
+
+			continue
+		}
+
+		timeout, err := time.ParseDuration(value)
+		if err != nil || timeout <= 0 {
+			return 0, 0, 0, fmt.Errorf("%w: %q", ErrInvalidTimeout, value)
+		}
+
+		timeouts = append(timeouts, timeout)
+	}
+
+	return timeouts[0], timeouts[1], timeouts[2], nil
+}
+
+func (s *Server) validate() error {
+	if s == nil {
+		return nil
+	}
+
+	_, _, _, err := s.Timeouts()
+	if err != nil {
+		return err
+	}
+
+	if user, _, found := strings.Cut(s.BasicAuth, ":"); s.BasicAuth != "" && (!found || user == "") {
+		return ErrInvalidBasicAuth
+	}
+
+	return nil
+}
+
+// EntryPoints returns the roles marked with entry, or the first role when none is marked.
+func (c *Chain) EntryPoints() []*UseRoles {
+	entries := make([]*UseRoles, 0)
+
+	for _, role := range c.UseRoles {
+		if role.Entry {
+			entries = append(entries, role)
+		}
+	}
+
+	if len(entries) == 0 && len(c.UseRoles) > 0 {
+		entries = append(entries, c.UseRoles[0])
+	}
+
+	return entries
+}
+
+func validate(config *Config) error {
+	for _, profile := range config.Profiles {
+		err := profile.Server.validate()
+		if err != nil {
+			return fmt.Errorf("profile %q: %w", profile.Name, err)
+		}
+
+		guard := profile.Guardrails
+		if guard == nil {
+			continue
+		}
+
+		_, err = guard.Deadline()
+		if err != nil {
+			return fmt.Errorf("profile %q: %w", profile.Name, err)
+		}
+
+		if guard.OnExpire != OnExpireStop && guard.OnExpire != OnExpireStopAndWipe {
+			return fmt.Errorf("profile %q: %w", profile.Name, ErrInvalidOnExpire)
+		}
+
+		if guard.MaxAssumesPerDay < 0 {
+			return fmt.Errorf("profile %q: %w", profile.Name, ErrInvalidBudget)
+		}
+	}
+
+	return nil
+}
+
 func (c *Config) ToFlags() (int64, []string, []string, error) {
 	if c.SelectProfile == "" {
 		return 0, nil, nil, ErrProfileNotSelected
@@ -85,6 +278,10 @@ func setDefault(config *Config) {
 			profile.Region = "eu-west-1"
 		}
 
+		if profile.Guardrails != nil && profile.Guardrails.OnExpire == "" {
+			profile.Guardrails.OnExpire = OnExpireStop
+		}
+
 		if profile.Chain == nil {
 			continue
 		}
@@ -100,10 +297,16 @@ func setDefault(config *Config) {
 	}
 }
 
-func decode(fileContent []byte) (*Config, error) {
+// decode reads native HCL, or HCL's JSON syntax when filename ends in .json.
+func decode(fileContent []byte, filename string) (*Config, error) {
 	parser := hclparse.NewParser()
 
-	fileHCL, diag := parser.ParseHCL(fileContent, "co.hcl")
+	parseFile := parser.ParseHCL
+	if filepath.Ext(filename) == ".json" {
+		parseFile = parser.ParseJSON
+	}
+
+	fileHCL, diag := parseFile(fileContent, filename)
 	if diag.HasErrors() {
 		return nil, fmt.Errorf("failed to parse config: %w", diag)
 	}
@@ -144,7 +347,7 @@ func decode(fileContent []byte) (*Config, error) {
 
 	config := &Config{} //nolint:exhaustruct
 
-	errDecode := hclsimple.Decode("config.hcl", fileContent, ctx, config)
+	errDecode := hclsimple.Decode(filename, fileContent, ctx, config)
 	if errDecode != nil {
 		return nil, fmt.Errorf("failed to decode config: %w", errDecode)
 	}
@@ -4,6 +4,9 @@
 package parser_test
 
 import (
+	"errors"
+	"os"
+	"path/filepath"
 	"reflect"
 	"testing"
 
@@ -228,3 +231,218 @@ func TestConfig_ToFlags(t *testing.T) {
 		})
 	}
 }
+
+func TestParseFile_Guardrails(t *testing.T) {
+	t.Parallel()
+
+	const chain = `
+  chain {
+    use {
+      arn = "arn:aws:iam::111111111111:role/role-a"
+    }
+
+    use {
+      arn = "arn:aws:iam::111111111111:role/role-b"
+    }
+  }
+`
+
+	tests := []struct {
+		name       string
+		guardrails string
+		want       *parser.Guardrails
+		wantErr    error
+	}{
+		{
+			name: "defaults on_expire to stop",
+			guardrails: `
+  guardrails {
+    not_after           = "2025-03-01T18:00:00Z"
+    allowed_accounts    = ["111111111111"]
+    max_assumes_per_day = 200
+  }
+`,
+			want: &parser.Guardrails{
+				NotAfter:         "2025-03-01T18:00:00Z",
+				AllowedAccounts:  []string{"111111111111"},
+				MaxAssumesPerDay: 200,
+				OnExpire:         parser.OnExpireStop,
+			},
+		},
+		{
+			name: "accepts a date and stop_and_wipe",
+			guardrails: `
+  guardrails {
+    not_after = "2025-03-01"
+    on_expire = "stop_and_wipe"
+  }
+`,
+			want: &parser.Guardrails{
+				NotAfter: "2025-03-01",
+				OnExpire: parser.OnExpireStopAndWipe,
+			},
+		},
+		{
+			name:       "rejects an invalid kill date",
+			guardrails: "guardrails {\n not_after = \"next friday\"\n}\n",
+			wantErr:    parser.ErrInvalidNotAfter,
+		},
+		{
+			name:       "rejects an unknown on_expire action",
+			guardrails: "guardrails {\n on_expire = \"explode\"\n}\n",
+			wantErr:    parser.ErrInvalidOnExpire,
+		},
+		{
+			name:       "rejects a negative budget",
+			guardrails: "guardrails {\n max_assumes_per_day = -1\n}\n",
+			wantErr:    parser.ErrInvalidBudget,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			path := filepath.Join(t.TempDir(), "config.hcl")
+			content := "select_profile = profile.engagement\n\nprofile \"engagement\" {\n" +
+				chain + tt.guardrails + "}\n"
+
+			err := os.WriteFile(path, []byte(content), 0o600)
+			if err != nil {
+				t.Fatalf("failed to write config: %v", err)
+			}
+
+			got, err := parser.ParseFile(path)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf("ParseFile() error = %v, want %v", err, tt.wantErr)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("ParseFile() error = %v", err)
+			}
+
+			profile, err := got.Selected()
+			if err != nil {
+				t.Fatalf("Selected() error = %v", err)
+			}
+
+			if !reflect.DeepEqual(profile.Guardrails, tt.want) {
+				t.Errorf("ParseFile() guardrails = %+v, want %+v", profile.Guardrails, tt.want)
+			}
+		})
+	}
+}
+
+func TestParse_Server(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		server  string
+		want    *parser.Server
+		wantErr error
+	}{
+		{
+			name: "listen with basic auth and timeouts",
+			server: `
+  server {
+    listen        = "0.0.0.0:8742"
+    basic_auth    = "red:team"
+    read_timeout  = "5s"
+    write_timeout = "2m"
+  }
+`,
+			want: &parser.Server{
+				Listen:       "0.0.0.0:8742",
+				BasicAuth:    "red:team",
+				ReadTimeout:  "5s",
+				WriteTimeout: "2m",
+			},
+		},
+		{
+			name:    "rejects an invalid timeout",
+			server:  "server {\n idle_timeout = \"forever\"\n}\n",
+			wantErr: parser.ErrInvalidTimeout,
+		},
+		{
+			name:    "rejects a negative timeout",
+			server:  "server {\n read_timeout = \"-1s\"\n}\n",
+			wantErr: parser.ErrInvalidTimeout,
+		},
+		{
+			name:    "rejects basic auth without a password separator",
+			server:  "server {\n basic_auth = \"red\"\n}\n",
+			wantErr: parser.ErrInvalidBasicAuth,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := parser.Parse([]byte("select_profile = profile.engagement\n\nprofile \"engagement\" {\n" +
+				tt.server + "}\n"))
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Parse() error = %v, want %v", err, tt.wantErr)
+			}
+
+			if err != nil {
+				return
+			}
+
+			if !reflect.DeepEqual(got.Profiles[0].Server, tt.want) {
+				t.Errorf("Parse() server = %+v, want %+v", got.Profiles[0].Server, tt.want)
+			}
+		})
+	}
+}
+
+func TestConfig_Selected(t *testing.T) {
+	t.Parallel()
+
+	config := &parser.Config{
+		SelectProfile: "missing",
+		Profiles:      []*parser.Profile{{Name: "simple"}},
+	}
+
+	_, err := config.Selected()
+	if !errors.Is(err, parser.ErrProfileNotFound) {
+		t.Errorf("Selected() error = %v, want %v", err, parser.ErrProfileNotFound)
+	}
+
+	config.SelectProfile = ""
+
+	_, err = config.Selected()
+	if !errors.Is(err, parser.ErrProfileNotSelected) {
+		t.Errorf("Selected() error = %v, want %v", err, parser.ErrProfileNotSelected)
+	}
+}
+
+func TestChain_EntryPoints(t *testing.T) {
+	t.Parallel()
+
+	chain := &parser.Chain{
+		UseRoles: []*parser.UseRoles{
+			{ARN: "arn::42::role-a"},
+			{ARN: "arn::42::role-b", Entry: true},
+			{ARN: "arn::42::role-c", Entry: true},
+		},
+	}
+
+	got := chain.EntryPoints()
+	if len(got) != 2 || got[0].ARN != "arn::42::role-b" || got[1].ARN != "arn::42::role-c" {
+		t.Errorf("EntryPoints() = %v, want role-b and role-c", got)
+	}
+
+	chain.UseRoles[1].Entry = false
+	chain.UseRoles[2].Entry = false
+
+	got = chain.EntryPoints()
+	if len(got) != 1 || got[0].ARN != "arn::42::role-a" {
+		t.Errorf("EntryPoints() = %v, want the first role", got)
+	}
+}
@@ -0,0 +1,94 @@
+// Copyright 2025 variHQ OÜ
+// SPDX-License-Identifier: BSD-3-Clause
+
+package parser
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	"go.yaml.in/yaml/v3"
+)
+
+// decodeJSON tells HCL's JSON syntax from plain JSON by its top-level "profile" object.
+func decodeJSON(content []byte) (*Config, error) {
+	var top map[string]json.RawMessage
+
+	err := json.Unmarshal(content, &top)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse config: %w", err)
+	}
+
+	if _, found := top["profile"]; found {
+		return decode(content, "config.json")
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(content))
+	decoder.DisallowUnknownFields()
+
+	config := &Config{} //nolint:exhaustruct
+
+	err = decoder.Decode(config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode config: %w", err)
+	}
+
+	err = checkRequired(config)
+	if err != nil {
+		return nil, err
+	}
+
+	return config, nil
+}
+
+func decodeYAML(content []byte) (*Config, error) {
+	decoder := yaml.NewDecoder(bytes.NewReader(content))
+	decoder.KnownFields(true)
+
+	config := &Config{} //nolint:exhaustruct
+
+	err := decoder.Decode(config)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("failed to decode config: %w", err)
+	}
+
+	err = checkRequired(config)
+	if err != nil {
+		return nil, err
+	}
+
+	return config, nil
+}
+
+// checkRequired enforces the attributes the HCL schema marks as required, which plain JSON and YAML leave zero.
+func checkRequired(config *Config) error {
+	if config.SelectProfile == "" {
+		return fmt.Errorf("failed to decode config: %w: select_profile", ErrMissingAttribute)
+	}
+
+	for idx, profile := range config.Profiles {
+		if profile == nil || profile.Name == "" {
+			return fmt.Errorf("failed to decode config: %w: profiles[%d].name", ErrMissingAttribute, idx)
+		}
+
+		if profile.Chain == nil {
+			continue
+		}
+
+		for pos, role := range profile.Chain.UseRoles {
+			if role == nil || role.ARN == "" {
+				return fmt.Errorf(
+					"failed to decode config: %w: profile %q: use[%d].arn",
+					ErrMissingAttribute,
+					profile.Name,
+					pos,
+				)
+			}
+		}
+	}
+
+	return nil
+}
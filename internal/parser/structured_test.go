@@ -0,0 +1,241 @@
+// Copyright 2025 variHQ OÜ
+// SPDX-License-Identifier: BSD-3-Clause
+
+package parser_test
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/wakeful/trick/internal/parser"
+)
+
+const structuredHCL = `select_profile = profile.lab
+
+profile "lab" {
+  chain {
+    ttl = 15
+
+    use {
+      arn   = "arn::42::role-a"
+      entry = true
+    }
+
+    use {
+      arn  = "arn::42::role-b"
+      skip = true
+    }
+  }
+
+  guardrails {
+    not_after        = "2025-12-31"
+    allowed_accounts = ["42"]
+  }
+}
+`
+
+func TestParseFile_Formats(t *testing.T) {
+	t.Parallel()
+
+	want, err := parser.Parse([]byte(structuredHCL))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		file    string
+		content string
+	}{
+		{
+			name: "plain json",
+			file: "config.json",
+			content: `{
+  "select_profile": "lab",
+  "profiles": [{
+    "name": "lab",
+    "chain": {"ttl": 15, "use": [{"arn": "arn::42::role-a", "entry": true}, {"arn": "arn::42::role-b", "skip": true}]},
+    "guardrails": {"not_after": "2025-12-31", "allowed_accounts": ["42"]}
+  }]
+}`,
+		},
+		{
+			name: "hcl json syntax",
+			file: "config.json",
+			content: `{
+  "select_profile": "${profile.lab}",
+  "profile": {"lab": {
+    "chain": {"ttl": 15, "use": [{"arn": "arn::42::role-a", "entry": true}, {"arn": "arn::42::role-b", "skip": true}]},
+    "guardrails": {"not_after": "2025-12-31", "allowed_accounts": ["42"]}
+  }}
+}`,
+		},
+		{
+			name: "yaml",
+			file: "config.YML",
+			content: `select_profile: lab
+profiles:
+  - name: lab
+    chain:
+      ttl: 15
+      use:
+        - arn: arn::42::role-a
+          entry: true
+        - arn: arn::42::role-b
+          skip: true
+    guardrails:
+      not_after: "2025-12-31"
+      allowed_accounts: ["42"]
+`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			path := filepath.Join(t.TempDir(), tt.file)
+
+			err := os.WriteFile(path, []byte(tt.content), 0o600)
+			if err != nil {
+				t.Fatalf("failed to write config: %v", err)
+			}
+
+			got, err := parser.ParseFile(path)
+			if err != nil {
+				t.Fatalf("ParseFile() error = %v", err)
+			}
+
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("ParseFile() = %+v, want %+v", got, want)
+			}
+		})
+	}
+}
+
+func TestParse_FormatErrors(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		parse   func([]byte) (*parser.Config, error)
+		content string
+		wantErr error
+	}{
+		{
+			name:    "json on_expire",
+			parse:   parser.ParseJSON,
+			content: `{"select_profile": "lab", "profiles": [{"name": "lab", "guardrails": {"on_expire": "wipe"}}]}`,
+			wantErr: parser.ErrInvalidOnExpire,
+		},
+		{
+			name:    "yaml not_after",
+			parse:   parser.ParseYAML,
+			content: "select_profile: lab\nprofiles:\n  - name: lab\n    guardrails:\n      not_after: soon\n",
+			wantErr: parser.ErrInvalidNotAfter,
+		},
+		{
+			name:    "hcl json budget",
+			parse:   parser.ParseJSON,
+			content: `{"select_profile": "lab", "profile": {"lab": {"guardrails": {"max_assumes_per_day": -1}}}}`,
+			wantErr: parser.ErrInvalidBudget,
+		},
+		{
+			name:    "json missing arn",
+			parse:   parser.ParseJSON,
+			content: `{"select_profile": "lab", "profiles": [{"name": "lab", "chain": {"use": [{"skip": true}]}}]}`,
+			wantErr: parser.ErrMissingAttribute,
+		},
+		{
+			name:    "yaml missing select_profile",
+			parse:   parser.ParseYAML,
+			content: "profiles: []\n",
+			wantErr: parser.ErrMissingAttribute,
+		},
+		{
+			name:    "yaml unknown field",
+			parse:   parser.ParseYAML,
+			content: "select_profile: lab\nprofiles:\n  - name: lab\n    regoin: eu-west-1\n",
+			wantErr: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := tt.parse([]byte(tt.content))
+			if err == nil {
+				t.Fatal("expected an error")
+			}
+
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Errorf("error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// TestSchema keeps config.schema.json in step with the json tags of the config types.
+func TestSchema(t *testing.T) {
+	t.Parallel()
+
+	content, err := os.ReadFile("config.schema.json")
+	if err != nil {
+		t.Fatalf("failed to read schema: %v", err)
+	}
+
+	type object struct {
+		Properties map[string]json.RawMessage `json:"properties"`
+	}
+
+	var schema struct {
+		object
+
+		Defs map[string]object `json:"$defs"`
+	}
+
+	err = json.Unmarshal(content, &schema)
+	if err != nil {
+		t.Fatalf("failed to decode schema: %v", err)
+	}
+
+	tests := []struct {
+		value  any
+		schema object
+	}{
+		{parser.Config{}, schema.object},
+		{parser.Profile{}, schema.Defs["profile"]},
+		{parser.Chain{}, schema.Defs["chain"]},
+		{parser.UseRoles{}, schema.Defs["use"]},
+		{parser.Guardrails{}, schema.Defs["guardrails"]},
+		{parser.Server{}, schema.Defs["server"]},
+	}
+
+	for _, tt := range tests {
+		kind := reflect.TypeOf(tt.value)
+		fields := make([]string, 0, kind.NumField())
+
+		for idx := range kind.NumField() {
+			fields = append(fields, strings.Split(kind.Field(idx).Tag.Get("json"), ",")[0])
+		}
+
+		properties := make([]string, 0, len(tt.schema.Properties))
+		for name := range tt.schema.Properties {
+			properties = append(properties, name)
+		}
+
+		slices.Sort(fields)
+		slices.Sort(properties)
+
+		if !slices.Equal(fields, properties) {
+			t.Errorf("%s: schema properties = %v, want %v", kind.Name(), properties, fields)
+		}
+	}
+}
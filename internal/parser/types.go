@@ -5,31 +5,75 @@ package parser
 
 import "errors"
 
+// Config is the decoded config file. The json and yaml tags describe the plain JSON and YAML layout, where profiles
+// are a list carrying their name and select_profile holds the bare profile name.
 type Config struct {
-	SelectProfile string     `hcl:"select_profile"`
-	Profiles      []*Profile `hcl:"profile,block"`
+	SelectProfile string     `hcl:"select_profile" json:"select_profile" yaml:"select_profile"`
+	Profiles      []*Profile `hcl:"profile,block"  json:"profiles"       yaml:"profiles"`
 }
 
 type Profile struct {
-	Name   string `hcl:"name,label"`
-	Region string `hcl:"region,optional"`
-	Chain  *Chain `hcl:"chain,block"`
+	Name       string      `hcl:"name,label"       json:"name"       yaml:"name"`
+	Region     string      `hcl:"region,optional"  json:"region"     yaml:"region"`
+	Chain      *Chain      `hcl:"chain,block"      json:"chain"      yaml:"chain"`
+	Guardrails *Guardrails `hcl:"guardrails,block" json:"guardrails" yaml:"guardrails"`
+	Server     *Server     `hcl:"server,block"     json:"server"     yaml:"server"`
 }
 
 type Chain struct {
-	TTL      int64       `hcl:"ttl,optional"`
-	UseRoles []*UseRoles `hcl:"use,block"`
+	TTL      int64       `hcl:"ttl,optional" json:"ttl" yaml:"ttl"`
+	UseRoles []*UseRoles `hcl:"use,block"    json:"use" yaml:"use"`
 }
 
 type UseRoles struct {
-	ARN  string `hcl:"arn"`
-	Skip bool   `hcl:"skip,optional"`
+	ARN  string `hcl:"arn"           json:"arn"  yaml:"arn"`
+	Skip bool   `hcl:"skip,optional" json:"skip" yaml:"skip"`
+	// Entry marks a role the chain can be entered from with the account's own credentials.
+	Entry bool `hcl:"entry,optional" json:"entry" yaml:"entry"`
 }
 
+// Guardrails are the rules-of-engagement limits enforced while the chain is running.
+type Guardrails struct {
+	NotAfter         string   `hcl:"not_after,optional"           json:"not_after"           yaml:"not_after"`
+	AllowedAccounts  []string `hcl:"allowed_accounts,optional"    json:"allowed_accounts"    yaml:"allowed_accounts"`
+	MaxAssumesPerDay int      `hcl:"max_assumes_per_day,optional" json:"max_assumes_per_day" yaml:"max_assumes_per_day"`
+	OnExpire         string   `hcl:"on_expire,optional"           json:"on_expire"           yaml:"on_expire"`
+}
+
+// Server configures the UI server started with -ui. Timeouts are Go durations such as 30s.
+type Server struct {
+	Listen string `hcl:"listen,optional" json:"listen" yaml:"listen"`
+	// Socket serves the UI on a Unix socket instead of Listen.
+	Socket string `hcl:"socket,optional" json:"socket" yaml:"socket"`
+	Token  string `hcl:"token,optional"  json:"token"  yaml:"token"`
+	// BasicAuth holds the credentials browsers are asked for, as user:password.
+	BasicAuth     string `hcl:"basic_auth,optional"      json:"basic_auth"      yaml:"basic_auth"`
+	TLSCert       string `hcl:"tls_cert,optional"        json:"tls_cert"        yaml:"tls_cert"`
+	TLSKey        string `hcl:"tls_key,optional"         json:"tls_key"         yaml:"tls_key"`
+	TLSSelfSigned bool   `hcl:"tls_self_signed,optional" json:"tls_self_signed" yaml:"tls_self_signed"`
+	ReadTimeout   string `hcl:"read_timeout,optional"    json:"read_timeout"    yaml:"read_timeout"`
+	WriteTimeout  string `hcl:"write_timeout,optional"   json:"write_timeout"   yaml:"write_timeout"`
+	IdleTimeout   string `hcl:"idle_timeout,optional"    json:"idle_timeout"    yaml:"idle_timeout"`
+}
+
+const (
+	// OnExpireStop stops the run loop once not_after has passed.
+	OnExpireStop = "stop"
+	// OnExpireStopAndWipe stops the run loop and deletes the output profile once not_after has passed.
+	OnExpireStopAndWipe = "stop_and_wipe"
+)
+
 const defaultTLL = 12
 
 var (
 	ErrParseHCL           = errors.New("ParseHCL return a nil")
 	ErrSchemaConfig       = errors.New("unable to configure schema for config file")
 	ErrProfileNotSelected = errors.New("select_profile is required")
+	ErrProfileNotFound    = errors.New("selected profile is not defined")
+	ErrInvalidNotAfter    = errors.New("guardrails.not_after must be an RFC 3339 timestamp or a date")
+	ErrInvalidOnExpire    = errors.New("guardrails.on_expire must be stop or stop_and_wipe")
+	ErrInvalidBudget      = errors.New("guardrails.max_assumes_per_day must not be negative")
+	ErrMissingAttribute   = errors.New("missing required attribute")
+	ErrInvalidTimeout     = errors.New("server timeouts must be positive durations such as 30s")
+	ErrInvalidBasicAuth   = errors.New("basic auth must be user:password")
 )
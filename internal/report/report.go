@@ -0,0 +1,336 @@
+// Copyright 2025 variHQ OÜ
+// SPDX-License-Identifier: BSD-3-Clause
+
+package report
+
+import (
+	_ "embed"
+	"errors"
+	"fmt"
+	htmltemplate "html/template"
+	"slices"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/wakeful/trick/internal/audit"
+	"github.com/wakeful/trick/internal/ui"
+)
+
+// ErrNoChain is returned when the audit log holds no start entry describing the chain.
+var ErrNoChain = errors.New("audit log has no start entry")
+
+// Hop is a single successful AssumeRole call in the timeline.
+type Hop struct {
+	Time   time.Time
+	Role   string
+	Usable bool
+}
+
+// Dwell is the time the output profile held credentials of a usable role.
+type Dwell struct {
+	Role     string
+	Duration time.Duration
+	Writes   int
+}
+
+// Failure is a failed hop or write, together with the first successful write after it.
+type Failure struct {
+	Time        time.Time
+	Event       string
+	Role        string
+	Error       string
+	RecoveredAt *time.Time
+}
+
+// Gap is a period in which the output profile held no valid credentials.
+type Gap struct {
+	From time.Time
+	To   time.Time
+}
+
+// Duration returns the length of the gap.
+func (g Gap) Duration() time.Duration {
+	return g.To.Sub(g.From)
+}
+
+// Report summarises an engagement recorded in the audit log.
+type Report struct {
+	Start       time.Time
+	End         time.Time
+	Roles       []string
+	UsableRoles []string
+	Refresh     int64
+	Hops        []Hop
+	Dwell       []Dwell
+	Failures    []Failure
+	Covered     time.Duration
+	Gaps        []Gap
+	Diagram     string
+}
+
+// Duration returns the length of the engagement.
+func (r *Report) Duration() time.Duration {
+	return r.End.Sub(r.Start)
+}
+
+// Coverage returns the share of the engagement with valid credentials, in percent.
+func (r *Report) Coverage() float64 {
+	total := r.Duration()
+	if total <= 0 {
+		return 0
+	}
+
+	const percent = 100
+
+	return float64(r.Covered) / float64(total) * percent
+}
+
+type window struct {
+	from time.Time
+	to   time.Time
+	role string
+}
+
+// Build turns audit entries into a Report.
+//
+//nolint:cyclop,funlen
+func Build(entries []audit.Entry) (*Report, error) {
+	sorted := slices.Clone(entries)
+	slices.SortStableFunc(sorted, func(a, b audit.Entry) int { return a.Time.Compare(b.Time) })
+
+	startIdx := slices.IndexFunc(sorted, func(e audit.Entry) bool { return e.Event == audit.EventStart })
+	if startIdx < 0 {
+		return nil, ErrNoChain
+	}
+
+	start := sorted[startIdx]
+
+	usable := make(map[string]struct{}, len(start.UsableRoles))
+	for _, role := range start.UsableRoles {
+		usable[role] = struct{}{}
+	}
+
+	report := &Report{ //nolint:exhaustruct
+		Start:       sorted[0].Time,
+		End:         sorted[len(sorted)-1].Time,
+		Roles:       start.Roles,
+		UsableRoles: start.UsableRoles,
+		Refresh:     start.Refresh,
+		Diagram:     ui.Diagram(start.Roles, usable, start.Refresh),
+	}
+
+	windows := make([]window, 0)
+	dwell := make(map[string]*Dwell)
+
+	var open *window
+
+	closeWindow := func(at time.Time) {
+		if open == nil {
+			return
+		}
+
+		if at.Before(open.to) {
+			open.to = at
+		}
+
+		windows = append(windows, *open)
+		open = nil
+	}
+
+	for idx, entry := range sorted {
+		switch entry.Event {
+		case audit.EventStart, audit.EventStop:
+			closeWindow(entry.Time)
+		case audit.EventAssume:
+			report.Hops = append(report.Hops, Hop{
+				Time:   entry.Time,
+				Role:   entry.Role,
+				Usable: entry.Usable,
+			})
+		case audit.EventAssumeFailed, audit.EventWriteFailed, audit.EventGuardrailTripped:
+			report.Failures = append(report.Failures, Failure{
+				Time:        entry.Time,
+				Event:       entry.Event,
+				Role:        entry.Role,
+				Error:       entry.Error,
+				RecoveredAt: recoveredAt(sorted[idx+1:]),
+			})
+		case audit.EventCredentialsWritten:
+			closeWindow(entry.Time)
+
+			until := report.End
+			if entry.Expiration != nil {
+				until = *entry.Expiration
+			}
+
+			open = &window{from: entry.Time, to: until, role: entry.Role}
+
+			if _, ok := dwell[entry.Role]; !ok {
+				dwell[entry.Role] = &Dwell{Role: entry.Role, Duration: 0, Writes: 0}
+			}
+
+			dwell[entry.Role].Writes++
+		}
+	}
+
+	closeWindow(report.End)
+
+	for _, win := range windows {
+		dwell[win.role].Duration += win.to.Sub(win.from)
+	}
+
+	for _, role := range orderedRoles(report.Roles, dwell) {
+		report.Dwell = append(report.Dwell, *dwell[role])
+	}
+
+	report.Covered, report.Gaps = coverage(report.Start, report.End, windows)
+
+	return report, nil
+}
+
+func recoveredAt(rest []audit.Entry) *time.Time {
+	for _, entry := range rest {
+		if entry.Event == audit.EventCredentialsWritten {
+			at := entry.Time
+
+			return &at
+		}
+	}
+
+	return nil
+}
+
+// orderedRoles lists the roles with dwell time in chain order, followed by unknown roles sorted by name.
+func orderedRoles(chain []string, dwell map[string]*Dwell) []string {
+	out := make([]string, 0, len(dwell))
+	seen := make(map[string]struct{}, len(dwell))
+
+	for _, role := range chain {
+		if _, ok := dwell[role]; ok {
+			if _, dup := seen[role]; !dup {
+				out = append(out, role)
+				seen[role] = struct{}{}
+			}
+		}
+	}
+
+	extra := make([]string, 0)
+
+	for role := range dwell {
+		if _, ok := seen[role]; !ok {
+			extra = append(extra, role)
+		}
+	}
+
+	slices.Sort(extra)
+
+	return append(out, extra...)
+}
+
+// coverage merges the credential windows and returns the covered time and the gaps between start and end.
+func coverage(start, end time.Time, windows []window) (time.Duration, []Gap) {
+	sorted := slices.Clone(windows)
+	slices.SortFunc(sorted, func(a, b window) int { return a.from.Compare(b.from) })
+
+	var (
+		covered time.Duration
+		gaps    []Gap
+	)
+
+	cursor := start
+
+	for _, win := range sorted {
+		from, to := win.from, win.to
+		if to.After(end) {
+			to = end
+		}
+
+		if !to.After(cursor) {
+			continue
+		}
+
+		if from.After(cursor) {
+			gaps = append(gaps, Gap{From: cursor, To: from})
+			cursor = from
+		}
+
+		covered += to.Sub(cursor)
+		cursor = to
+	}
+
+	if end.After(cursor) {
+		gaps = append(gaps, Gap{From: cursor, To: end})
+	}
+
+	return covered, gaps
+}
+
+//go:embed templates/report.md.tmpl
+var markdownTemplate string
+
+//go:embed templates/report.html.tmpl
+var htmlTemplate string
+
+// Markdown renders the report as a Markdown document.
+func (r *Report) Markdown() (string, error) {
+	tmpl, err := template.New("report").Funcs(funcs()).Parse(markdownTemplate)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse markdown template: %w", err)
+	}
+
+	var out strings.Builder
+
+	err = tmpl.Execute(&out, r)
+	if err != nil {
+		return "", fmt.Errorf("failed to execute markdown template: %w", err)
+	}
+
+	return out.String(), nil
+}
+
+// HTML renders the report as a self-contained HTML page with Mermaid.js inlined.
+func (r *Report) HTML() (string, error) {
+	script, err := ui.MermaidSource()
+	if err != nil {
+		return "", fmt.Errorf("failed to load mermaid: %w", err)
+	}
+
+	tmpl, err := htmltemplate.New("report").Funcs(funcs()).Parse(htmlTemplate)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse html template: %w", err)
+	}
+
+	var out strings.Builder
+
+	err = tmpl.Execute(&out, struct {
+		*Report
+
+		Mermaid htmltemplate.JS
+	}{
+		Report:  r,
+		Mermaid: htmltemplate.JS(script), //nolint:gosec
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to execute html template: %w", err)
+	}
+
+	return out.String(), nil
+}
+
+func funcs() map[string]any {
+	return map[string]any{
+		"ts": func(t time.Time) string { return t.UTC().Format(time.RFC3339) },
+		"dur": func(d time.Duration) string {
+			return d.Round(time.Second).String()
+		},
+		"since": func(from time.Time, to *time.Time) string {
+			if to == nil {
+				return "not recovered"
+			}
+
+			return to.Sub(from).Round(time.Second).String()
+		},
+		"pct": func(v float64) string { return fmt.Sprintf("%.1f%%", v) },
+	}
+}
@@ -0,0 +1,139 @@
+// Copyright 2025 variHQ OÜ
+// SPDX-License-Identifier: BSD-3-Clause
+
+package report_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/wakeful/trick/internal/audit"
+	"github.com/wakeful/trick/internal/report"
+)
+
+func engagement() []audit.Entry {
+	base := time.Date(2025, 1, 1, 10, 0, 0, 0, time.UTC)
+	at := func(minutes int) time.Time { return base.Add(time.Duration(minutes) * time.Minute) }
+	exp := func(minutes int) *time.Time {
+		t := at(minutes)
+
+		return &t
+	}
+
+	return []audit.Entry{
+		{
+			Time:        at(0),
+			Event:       audit.EventStart,
+			Roles:       []string{"arn::42::role-a", "arn::42::role-b", "arn::42::role-c"},
+			UsableRoles: []string{"arn::42::role-a", "arn::42::role-c"},
+			Refresh:     12,
+		},
+		{Time: at(0), Event: audit.EventAssume, Role: "arn::42::role-a", Usable: true},
+		{Time: at(0), Event: audit.EventCredentialsWritten, Role: "arn::42::role-a", Expiration: exp(15)},
+		{Time: at(12), Event: audit.EventAssume, Role: "arn::42::role-b"},
+		{Time: at(12), Event: audit.EventAssume, Role: "arn::42::role-c", Usable: true},
+		{Time: at(12), Event: audit.EventCredentialsWritten, Role: "arn::42::role-c", Expiration: exp(27)},
+		{Time: at(24), Event: audit.EventAssumeFailed, Role: "arn::42::role-a", Error: "AccessDenied"},
+		{Time: at(36), Event: audit.EventAssume, Role: "arn::42::role-a", Usable: true},
+		{Time: at(36), Event: audit.EventCredentialsWritten, Role: "arn::42::role-a", Expiration: exp(51)},
+		{Time: at(40), Event: audit.EventStop},
+	}
+}
+
+func TestBuild(t *testing.T) {
+	t.Parallel()
+
+	got, err := report.Build(engagement())
+	if err != nil {
+		t.Fatalf("Build() error = %v", err)
+	}
+
+	if got.Duration() != 40*time.Minute {
+		t.Errorf("Build() duration = %v, want 40m", got.Duration())
+	}
+
+	if len(got.Hops) != 4 {
+		t.Errorf("Build() hops = %d, want 4", len(got.Hops))
+	}
+
+	wantDwell := map[string]time.Duration{
+		"arn::42::role-a": 16 * time.Minute,
+		"arn::42::role-c": 15 * time.Minute,
+	}
+
+	for _, dwell := range got.Dwell {
+		if dwell.Duration != wantDwell[dwell.Role] {
+			t.Errorf("Build() dwell %s = %v, want %v", dwell.Role, dwell.Duration, wantDwell[dwell.Role])
+		}
+	}
+
+	if got.Dwell[0].Role != "arn::42::role-a" || got.Dwell[0].Writes != 2 {
+		t.Errorf("Build() first dwell = %+v", got.Dwell[0])
+	}
+
+	if len(got.Failures) != 1 || got.Failures[0].RecoveredAt == nil {
+		t.Fatalf("Build() failures = %+v", got.Failures)
+	}
+
+	if got.Failures[0].RecoveredAt.Sub(got.Failures[0].Time) != 12*time.Minute {
+		t.Errorf("Build() recovery = %v", got.Failures[0].RecoveredAt)
+	}
+
+	if len(got.Gaps) != 1 || got.Gaps[0].Duration() != 9*time.Minute {
+		t.Errorf("Build() gaps = %+v, want a single 9m gap", got.Gaps)
+	}
+
+	if got.Covered != 31*time.Minute {
+		t.Errorf("Build() covered = %v, want 31m", got.Covered)
+	}
+}
+
+func TestBuild_NoStart(t *testing.T) {
+	t.Parallel()
+
+	_, err := report.Build([]audit.Entry{{Event: audit.EventStop}})
+	if !errors.Is(err, report.ErrNoChain) {
+		t.Errorf("Build() error = %v, want %v", err, report.ErrNoChain)
+	}
+}
+
+func TestReport_Render(t *testing.T) {
+	t.Parallel()
+
+	rep, err := report.Build(engagement())
+	if err != nil {
+		t.Fatalf("Build() error = %v", err)
+	}
+
+	markdown, err := rep.Markdown()
+	if err != nil {
+		t.Fatalf("Markdown() error = %v", err)
+	}
+
+	for _, want := range []string{
+		"# trick engagement report",
+		"```mermaid\nstateDiagram",
+		"r0 --> r1: wait 12min and jump",
+		"| 2025-01-01T10:24:00Z | assume-failed | `arn::42::role-a` | AccessDenied | 12m0s |",
+		"| 2025-01-01T10:27:00Z | 2025-01-01T10:36:00Z | 9m0s |",
+	} {
+		if !strings.Contains(markdown, want) {
+			t.Errorf("Markdown() missing %q", want)
+		}
+	}
+
+	page, err := rep.HTML()
+	if err != nil {
+		t.Fatalf("HTML() error = %v", err)
+	}
+
+	if strings.Contains(page, "/static/mermaid.js") {
+		t.Error("HTML() should inline mermaid instead of linking it")
+	}
+
+	if !strings.Contains(page, "mermaid.initialize") || !strings.Contains(page, "stateDiagram") {
+		t.Error("HTML() missing diagram")
+	}
+}
@@ -0,0 +1,134 @@
+// Copyright 2025 variHQ OÜ
+// SPDX-License-Identifier: BSD-3-Clause
+
+package state
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+)
+
+const (
+	// KindProfile is a section in a shared AWS credentials or config file.
+	KindProfile = "profile"
+	// KindFile is a regular file.
+	KindFile = "file"
+	// KindAuditLog is the audit log, which cleanup can keep for reporting.
+	KindAuditLog = "audit-log"
+	// KindSocket is a Unix domain socket.
+	KindSocket = "socket"
+)
+
+const (
+	dirMode  = 0o700
+	fileMode = 0o600
+)
+
+// Artifact is something trick created on disk and has to remove at the end of an engagement.
+type Artifact struct {
+	Kind    string `json:"kind"`
+	Path    string `json:"path"`
+	Section string `json:"section,omitempty"`
+}
+
+// Manifest lists the artifacts trick created, persisted as JSON next to other state.
+type Manifest struct {
+	mu        sync.Mutex
+	path      string
+	Artifacts []Artifact `json:"artifacts"`
+}
+
+// DefaultPath returns the manifest location inside the user cache directory.
+func DefaultPath() (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", fmt.Errorf("unable to locate cache directory: %w", err)
+	}
+
+	return filepath.Join(dir, "trick", "state.json"), nil
+}
+
+// DefaultSocketPath returns the location of the control socket inside the user cache directory.
+func DefaultSocketPath() (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", fmt.Errorf("unable to locate cache directory: %w", err)
+	}
+
+	return filepath.Join(dir, "trick", "trick.sock"), nil
+}
+
+// Load reads the manifest at path; a missing file yields an empty manifest.
+func Load(path string) (*Manifest, error) {
+	manifest := &Manifest{mu: sync.Mutex{}, path: path, Artifacts: nil}
+
+	content, err := os.ReadFile(path) //nolint:gosec
+	if errors.Is(err, fs.ErrNotExist) {
+		return manifest, nil
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to read state: %w", err)
+	}
+
+	err = json.Unmarshal(content, manifest)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode state: %w", err)
+	}
+
+	return manifest, nil
+}
+
+// Path returns the file the manifest is stored in.
+func (m *Manifest) Path() string {
+	return m.path
+}
+
+// Add records artifact and saves the manifest; artifacts already listed are ignored.
+func (m *Manifest) Add(artifact Artifact) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if slices.Contains(m.Artifacts, artifact) {
+		return nil
+	}
+
+	m.Artifacts = append(m.Artifacts, artifact)
+
+	return m.save()
+}
+
+// Remove forgets artifact and saves the manifest.
+func (m *Manifest) Remove(artifact Artifact) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.Artifacts = slices.DeleteFunc(m.Artifacts, func(a Artifact) bool { return a == artifact })
+
+	return m.save()
+}
+
+func (m *Manifest) save() error {
+	err := os.MkdirAll(filepath.Dir(m.path), dirMode)
+	if err != nil {
+		return fmt.Errorf("failed to create state directory: %w", err)
+	}
+
+	content, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode state: %w", err)
+	}
+
+	err = os.WriteFile(m.path, content, fileMode)
+	if err != nil {
+		return fmt.Errorf("failed to write state: %w", err)
+	}
+
+	return nil
+}
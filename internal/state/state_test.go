@@ -0,0 +1,52 @@
+// Copyright 2025 variHQ OÜ
+// SPDX-License-Identifier: BSD-3-Clause
+
+package state_test
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/wakeful/trick/internal/state"
+)
+
+func TestManifest(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "trick", "state.json")
+
+	manifest, err := state.Load(path)
+	if err != nil {
+		t.Fatalf("Load() on a missing file error = %v", err)
+	}
+
+	profile := state.Artifact{Kind: state.KindProfile, Path: "/tmp/credentials", Section: "trick"}
+	auditLog := state.Artifact{Kind: state.KindFile, Path: "/tmp/audit.jsonl"}
+
+	for _, artifact := range []state.Artifact{profile, auditLog, profile} {
+		err = manifest.Add(artifact)
+		if err != nil {
+			t.Fatalf("Add() error = %v", err)
+		}
+	}
+
+	reloaded, err := state.Load(path)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	if !reflect.DeepEqual(reloaded.Artifacts, []state.Artifact{profile, auditLog}) {
+		t.Errorf("Load() artifacts = %+v", reloaded.Artifacts)
+	}
+
+	err = reloaded.Remove(profile)
+	if err != nil {
+		t.Fatalf("Remove() error = %v", err)
+	}
+
+	reloaded, _ = state.Load(path)
+	if !reflect.DeepEqual(reloaded.Artifacts, []state.Artifact{auditLog}) {
+		t.Errorf("Remove() artifacts = %+v", reloaded.Artifacts)
+	}
+}
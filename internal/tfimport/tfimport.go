@@ -0,0 +1,263 @@
+// Copyright 2025 variHQ OÜ
+// SPDX-License-Identifier: BSD-3-Clause
+
+package tfimport
+
+import (
+	"cmp"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/wakeful/trick/internal/arn"
+	"github.com/wakeful/trick/internal/iam"
+)
+
+var (
+	// ErrNoRoles is returned when no output matches the key pattern.
+	ErrNoRoles = errors.New("no role ARN outputs match the key pattern")
+	// ErrMissingTrust is returned when the state has no trust policy for an output role.
+	ErrMissingTrust = errors.New("role has no trust policy in state")
+	// ErrNotARing is returned when the trust relationships do not form a single ring over every role.
+	ErrNotARing = errors.New("can_assume relationships do not form a ring")
+)
+
+// DefaultKeyPattern matches the outputs of example/main.tf and orders them by the role suffix.
+const DefaultKeyPattern = `^role_arn_(.+)$`
+
+// Output is one value of `terraform output -json`.
+type Output struct {
+	Sensitive bool            `json:"sensitive"`
+	Type      json.RawMessage `json:"type"`
+	Value     json.RawMessage `json:"value"`
+}
+
+// ReadOutputs decodes the output of `terraform output -json`.
+func ReadOutputs(input io.Reader) (map[string]Output, error) {
+	var outputs map[string]Output
+
+	err := json.NewDecoder(input).Decode(&outputs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode terraform outputs: %w", err)
+	}
+
+	return outputs, nil
+}
+
+// Role is a ring member found in the outputs.
+type Role struct {
+	// Key is the output name, e.g. role_arn_a.
+	Key string
+	// SortKey is the first capture group of the key pattern, or the whole key without one.
+	SortKey string
+	ARN     string
+	// Entry is set when the role's trust policy lets the account enter the ring through it.
+	Entry bool
+}
+
+// SelectRoles returns the string outputs whose name matches pattern, ordered by SortKey; numeric keys are
+// compared as numbers so role_arn_10 follows role_arn_9.
+func SelectRoles(outputs map[string]Output, pattern *regexp.Regexp) ([]Role, error) {
+	roles := make([]Role, 0)
+
+	for key, output := range outputs {
+		match := pattern.FindStringSubmatch(key)
+		if match == nil {
+			continue
+		}
+
+		var value string
+
+		err := json.Unmarshal(output.Value, &value)
+		if err != nil {
+			continue
+		}
+
+		sortKey := match[0]
+		if len(match) > 1 {
+			sortKey = match[1]
+		}
+
+		roles = append(roles, Role{Key: key, SortKey: sortKey, ARN: value, Entry: false})
+	}
+
+	if len(roles) == 0 {
+		return nil, fmt.Errorf("%w: %s", ErrNoRoles, pattern)
+	}
+
+	slices.SortFunc(roles, func(a, b Role) int {
+		left, errA := strconv.Atoi(a.SortKey)
+		right, errB := strconv.Atoi(b.SortKey)
+
+		if errA == nil && errB == nil {
+			return cmp.Compare(left, right)
+		}
+
+		return strings.Compare(a.SortKey, b.SortKey)
+	})
+
+	return roles, nil
+}
+
+type stateResource struct {
+	Mode      string `json:"mode"`
+	Type      string `json:"type"`
+	Instances []struct {
+		Attributes roleAttributes `json:"attributes"`
+	} `json:"instances"`
+	Values *roleAttributes `json:"values"`
+}
+
+type roleAttributes struct {
+	ARN              string `json:"arn"`
+	AssumeRolePolicy string `json:"assume_role_policy"`
+}
+
+type stateModule struct {
+	Resources    []stateResource `json:"resources"`
+	ChildModules []stateModule   `json:"child_modules"`
+}
+
+// ReadState returns the trust policy of every aws_iam_role in a terraform.tfstate file or in the output of
+// `terraform show -json`, keyed by role ARN without path.
+func ReadState(input io.Reader) (map[string]*iam.Policy, error) {
+	var state struct {
+		Resources []stateResource `json:"resources"`
+		Values    struct {
+			RootModule stateModule `json:"root_module"`
+		} `json:"values"`
+	}
+
+	err := json.NewDecoder(input).Decode(&state)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode terraform state: %w", err)
+	}
+
+	attributes := make([]roleAttributes, 0)
+
+	for _, resource := range state.Resources {
+		if resource.Mode == "managed" && resource.Type == "aws_iam_role" {
+			for _, instance := range resource.Instances {
+				attributes = append(attributes, instance.Attributes)
+			}
+		}
+	}
+
+	modules := []stateModule{state.Values.RootModule}
+	for len(modules) > 0 {
+		module := modules[0]
+		modules = append(modules[1:], module.ChildModules...)
+
+		for _, resource := range module.Resources {
+			if resource.Mode == "managed" && resource.Type == "aws_iam_role" && resource.Values != nil {
+				attributes = append(attributes, *resource.Values)
+			}
+		}
+	}
+
+	trust := make(map[string]*iam.Policy, len(attributes))
+
+	for _, attr := range attributes {
+		if attr.ARN == "" || attr.AssumeRolePolicy == "" {
+			continue
+		}
+
+		var policy iam.Policy
+
+		err := json.Unmarshal([]byte(attr.AssumeRolePolicy), &policy)
+		if err != nil {
+			return nil, fmt.Errorf("role %s: invalid assume_role_policy: %w", attr.ARN, err)
+		}
+
+		trust[arn.WithoutPath(attr.ARN)] = &policy
+	}
+
+	return trust, nil
+}
+
+// request is the AssumeRole call a session of role makes, as trust policy evaluation sees it.
+func request(roleARN string) iam.Request {
+	parsed, _ := arn.Parse(roleARN)
+	session, _ := arn.AssumedRole(roleARN, arn.SessionName)
+
+	return iam.Request{
+		PrincipalARN: roleARN,
+		SessionARN:   session,
+		Account:      parsed.Account,
+		Action:       iam.AssumeRole,
+		Resource:     "",
+	}
+}
+
+// OrderByTrust reorders roles so every role can assume the next one, following the can_assume relationships
+// encoded in the trust policies. A statement that also admits an unrelated role of the same account, such as
+// the chain_start root trust, marks an entry point and is not used for ordering.
+//
+//nolint:cyclop
+func OrderByTrust(roles []Role, trust map[string]*iam.Policy) ([]Role, error) {
+	successors := make(map[string][]int, len(roles))
+	entries := make([]bool, len(roles))
+
+	for target, role := range roles {
+		policy, found := trust[arn.WithoutPath(role.ARN)]
+		if !found {
+			return nil, fmt.Errorf("%w: %s", ErrMissingTrust, role.ARN)
+		}
+
+		parsed, err := arn.Parse(role.ARN)
+		if err != nil {
+			return nil, fmt.Errorf("role %s: %w", role.Key, err)
+		}
+
+		probe := request("arn:" + parsed.Partition + ":iam::" + parsed.Account + ":role/trick-import-probe")
+
+		for _, statement := range policy.Statement {
+			single := &iam.Policy{Version: policy.Version, ID: "", Statement: iam.Statements{statement}}
+
+			if single.EvaluateTrust(probe).Decision == iam.Allowed {
+				entries[target] = true
+
+				continue
+			}
+
+			for source, candidate := range roles {
+				if source != target && single.EvaluateTrust(request(candidate.ARN)).Decision == iam.Allowed {
+					successors[candidate.ARN] = append(successors[candidate.ARN], target)
+				}
+			}
+		}
+	}
+
+	start := max(slices.Index(entries, true), 0)
+	ordered := make([]Role, 0, len(roles))
+	visited := make(map[int]bool, len(roles))
+
+	for current := start; !visited[current]; {
+		visited[current] = true
+
+		role := roles[current]
+		role.Entry = entries[current]
+		ordered = append(ordered, role)
+
+		next := slices.Compact(slices.Sorted(slices.Values(successors[role.ARN])))
+		if len(next) != 1 {
+			return nil, fmt.Errorf("%w: %s can assume %d roles", ErrNotARing, role.Key, len(next))
+		}
+
+		current = next[0]
+		if visited[current] && current != start {
+			return nil, fmt.Errorf("%w: %s leads back to %s", ErrNotARing, role.Key, roles[current].Key)
+		}
+	}
+
+	if len(ordered) != len(roles) {
+		return nil, fmt.Errorf("%w: ring covers %d of %d roles", ErrNotARing, len(ordered), len(roles))
+	}
+
+	return ordered, nil
+}
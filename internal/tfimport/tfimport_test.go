@@ -0,0 +1,176 @@
+// Copyright 2025 variHQ OÜ
+// SPDX-License-Identifier: BSD-3-Clause
+
+package tfimport_test
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/wakeful/trick/internal/tfimport"
+)
+
+const outputs = `{
+  "role_arn_a": {"sensitive": false, "type": "string", "value": "arn:aws:iam::123456789012:role/trick-role-a"},
+  "role_arn_c": {"sensitive": false, "type": "string", "value": "arn:aws:iam::123456789012:role/trick-role-c"},
+  "role_arn_b": {"sensitive": false, "type": "string", "value": "arn:aws:iam::123456789012:role/trick-role-b"},
+  "vpc_id":     {"sensitive": false, "type": "string", "value": "vpc-0123"}
+}`
+
+// trustPolicy mirrors example/modules/role: the predecessor is trusted by ARN and chain_start adds the root.
+func trustPolicy(from string, start bool) string {
+	statements := []string{fmt.Sprintf(`{"Action": "sts:AssumeRole", "Effect": "Allow", "Principal": {"AWS": "*"},
+		"Condition": {"StringEquals": {"aws:PrincipalAccount": "123456789012"},
+		"StringLike": {"aws:PrincipalArn": "arn:aws:iam::123456789012:role/trick-role-%s"}}}`, from)}
+
+	if start {
+		statements = append([]string{`{"Action": "sts:AssumeRole", "Effect": "Allow",
+			"Principal": {"AWS": "arn:aws:iam::123456789012:root"}, "Condition": {}}`}, statements...)
+	}
+
+	return `{"Version": "2012-10-17", "Statement": [` + strings.Join(statements, ",") + `]}`
+}
+
+// showJSON mimics `terraform show -json` for the ring b -> a -> c -> b with a as the entry point.
+func showJSON(t *testing.T) string {
+	t.Helper()
+
+	module := func(name, from string, start bool) map[string]any {
+		return map[string]any{
+			"address": "module.role_" + name,
+			"resources": []map[string]any{
+				{"mode": "data", "type": "aws_caller_identity", "values": map[string]any{}},
+				{"mode": "managed", "type": "aws_iam_role", "values": map[string]any{
+					"arn":                "arn:aws:iam::123456789012:role/trick-role-" + name,
+					"assume_role_policy": trustPolicy(from, start),
+				}},
+			},
+		}
+	}
+
+	out, err := json.Marshal(map[string]any{
+		"values": map[string]any{
+			"root_module": map[string]any{
+				"child_modules": []map[string]any{
+					module("a", "b", true),
+					module("b", "c", false),
+					module("c", "a", false),
+				},
+			},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return string(out)
+}
+
+func keys(roles []tfimport.Role) string {
+	names := make([]string, 0, len(roles))
+	for _, role := range roles {
+		names = append(names, role.SortKey)
+	}
+
+	return strings.Join(names, ",")
+}
+
+func TestSelectRoles(t *testing.T) {
+	t.Parallel()
+
+	decoded, err := tfimport.ReadOutputs(strings.NewReader(outputs))
+	if err != nil {
+		t.Fatalf("ReadOutputs() error = %v", err)
+	}
+
+	roles, err := tfimport.SelectRoles(decoded, regexp.MustCompile(tfimport.DefaultKeyPattern))
+	if err != nil {
+		t.Fatalf("SelectRoles() error = %v", err)
+	}
+
+	if got := keys(roles); got != "a,b,c" {
+		t.Errorf("SelectRoles() = %s, want a,b,c", got)
+	}
+
+	_, err = tfimport.SelectRoles(decoded, regexp.MustCompile(`^arn_(\d+)$`))
+	if !errors.Is(err, tfimport.ErrNoRoles) {
+		t.Errorf("SelectRoles() error = %v, want %v", err, tfimport.ErrNoRoles)
+	}
+
+	numeric := map[string]tfimport.Output{
+		"hop_10": {Value: json.RawMessage(`"arn:aws:iam::1:role/x"`)},
+		"hop_9":  {Value: json.RawMessage(`"arn:aws:iam::1:role/y"`)},
+	}
+
+	roles, err = tfimport.SelectRoles(numeric, regexp.MustCompile(`^hop_(\d+)$`))
+	if err != nil {
+		t.Fatalf("SelectRoles() error = %v", err)
+	}
+
+	if got := keys(roles); got != "9,10" {
+		t.Errorf("SelectRoles() = %s, want 9,10", got)
+	}
+}
+
+func TestOrderByTrust(t *testing.T) {
+	t.Parallel()
+
+	decoded, err := tfimport.ReadOutputs(strings.NewReader(outputs))
+	if err != nil {
+		t.Fatalf("ReadOutputs() error = %v", err)
+	}
+
+	roles, err := tfimport.SelectRoles(decoded, regexp.MustCompile(tfimport.DefaultKeyPattern))
+	if err != nil {
+		t.Fatalf("SelectRoles() error = %v", err)
+	}
+
+	trust, err := tfimport.ReadState(strings.NewReader(showJSON(t)))
+	if err != nil {
+		t.Fatalf("ReadState() error = %v", err)
+	}
+
+	ordered, err := tfimport.OrderByTrust(roles, trust)
+	if err != nil {
+		t.Fatalf("OrderByTrust() error = %v", err)
+	}
+
+	if got := keys(ordered); got != "a,c,b" {
+		t.Errorf("OrderByTrust() = %s, want a,c,b", got)
+	}
+
+	if !ordered[0].Entry || ordered[1].Entry || ordered[2].Entry {
+		t.Errorf("OrderByTrust() entries = %+v, want only a", ordered)
+	}
+
+	_, err = tfimport.OrderByTrust(roles[:2], trust)
+	if !errors.Is(err, tfimport.ErrNotARing) {
+		t.Errorf("OrderByTrust() error = %v, want %v", err, tfimport.ErrNotARing)
+	}
+}
+
+func TestReadState_tfstate(t *testing.T) {
+	t.Parallel()
+
+	policy, err := json.Marshal(trustPolicy("b", false))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	state := `{"version": 4, "resources": [{"module": "module.role_a", "mode": "managed", "type": "aws_iam_role",
+		"name": "this", "instances": [{"attributes": {"arn": "arn:aws:iam::123456789012:role/trick-role-a",
+		"assume_role_policy": ` + string(policy) + `}}]}]}`
+
+	trust, err := tfimport.ReadState(strings.NewReader(state))
+	if err != nil {
+		t.Fatalf("ReadState() error = %v", err)
+	}
+
+	if _, ok := trust["arn:aws:iam::123456789012:role/trick-role-a"]; !ok || len(trust) != 1 {
+		t.Errorf("ReadState() = %v, want trick-role-a", trust)
+	}
+}
@@ -4,14 +4,20 @@
 package ui
 
 import (
+	"bytes"
+	"compress/gzip"
 	_ "embed"
 	"fmt"
 	"html/template"
-	"strconv"
+	"io"
 	"strings"
+
+	"github.com/wakeful/trick/internal/arn"
+	"github.com/wakeful/trick/internal/broadcast"
 )
 
-// flagsToDiagram creates a Mermaid.js stateDiagram from the provided parameters.
+// flagsToDiagram creates a Mermaid.js stateDiagram from the provided parameters. The node of each role is named by
+// broadcast.NodeID after its hop, so pages can find it by ID; roles sharing a name are labelled with their account.
 func flagsToDiagram(
 	roles []string,
 	usableRoles map[string]struct{},
@@ -25,29 +31,19 @@ func flagsToDiagram(
 
 	builder.WriteString("stateDiagram\n")
 
-	extractRoleName := func(arn string) string {
-		if strings.Contains(arn, "/") {
-			parts := strings.Split(arn, "/")
-
-			return parts[len(parts)-1]
-		}
-
-		parts := strings.Split(arn, ":")
-
-		return parts[len(parts)-1]
-	}
-
-	for pos, role := range roles {
-		roleName := extractRoleName(role)
+	labels := roleLabels(roles)
 
-		builder.WriteString("    r")
-		builder.WriteString(strconv.Itoa(pos))
+	for pos := range roles {
+		builder.WriteString("    ")
+		builder.WriteString(broadcast.NodeID(pos))
 		builder.WriteString(": ")
-		builder.WriteString(roleName)
+		builder.WriteString(labels[pos])
 		builder.WriteString("\n")
 	}
 
-	builder.WriteString("    [*] --> r0\n")
+	builder.WriteString("    [*] --> ")
+	builder.WriteString(broadcast.NodeID(0))
+	builder.WriteString("\n")
 
 	for pos, role := range roles {
 		nextIdx := (pos + 1) % len(roles)
@@ -55,13 +51,13 @@ func flagsToDiagram(
 
 		transitionMsg := fmt.Sprintf("wait %dmin and jump", refreshMinutes)
 		if !isUsable && len(usableRoles) > 0 {
-			transitionMsg = "lacks permission so we jump to " + extractRoleName(roles[nextIdx])
+			transitionMsg = "lacks permission so we jump to " + labels[nextIdx]
 		}
 
-		builder.WriteString("    r")
-		builder.WriteString(strconv.Itoa(pos))
-		builder.WriteString(" --> r")
-		builder.WriteString(strconv.Itoa(nextIdx))
+		builder.WriteString("    ")
+		builder.WriteString(broadcast.NodeID(pos))
+		builder.WriteString(" --> ")
+		builder.WriteString(broadcast.NodeID(nextIdx))
 		builder.WriteString(": ")
 		builder.WriteString(transitionMsg)
 		builder.WriteString("\n")
@@ -70,19 +66,122 @@ func flagsToDiagram(
 	return builder.String()
 }
 
+// roleLabels returns the name of every role, followed by its account when another role has the same name.
+func roleLabels(roles []string) []string {
+	counts := make(map[string]int)
+	for _, role := range roles {
+		counts[arn.Name(role)]++
+	}
+
+	labels := make([]string, 0, len(roles))
+
+	for _, role := range roles {
+		label := arn.Name(role)
+
+		if parsed, err := arn.Parse(role); err == nil && counts[label] > 1 && parsed.Account != "" {
+			label += " (" + parsed.Account + ")"
+		}
+
+		labels = append(labels, label)
+	}
+
+	return labels
+}
+
 //go:embed templates/diagram.html
 var DiagramTemplate string
 
 //go:embed static/mermaid.min.js.gz
 var MermaidScript []byte
 
-// RenderDiagramHTML pre-renders the diagram HTML once at startup for optimal performance.
+// Diagram returns the Mermaid.js stateDiagram definition used by the UI for the provided chain.
+func Diagram(roles []string, usableRoles map[string]struct{}, refreshMinutes int64) string {
+	return flagsToDiagram(roles, usableRoles, refreshMinutes)
+}
+
+// Styles of a hop in LiveDiagram.
+const (
+	// HopDisabled is a hop the chain only transits for now.
+	HopDisabled = "disabled"
+	// HopUnhealthy is a hop whose last AssumeRole call failed.
+	HopUnhealthy = "unhealthy"
+	// HopPinned is the only usable hop the chain comes back to.
+	HopPinned = "pinned"
+)
+
+// hopStyles maps each hop style to its Mermaid class definition.
+var hopStyles = [][2]string{
+	{HopDisabled, "fill:#e9ecef,stroke:#adb5bd,color:#6c757d,stroke-dasharray:5 5"},
+	{HopUnhealthy, "fill:#f8d7da,stroke:#dc3545,color:#842029,stroke-width:2px"},
+	{HopPinned, "fill:#fff3cd,stroke:#fd7e14,stroke-width:3px"},
+}
+
+// LiveDiagram returns the diagram served to the UI while the chain runs: Diagram drawn by the v2 state renderer, with
+// every role in styles drawn in the hop style it maps to.
+func LiveDiagram(
+	roles []string,
+	usableRoles map[string]struct{},
+	refreshMinutes int64,
+	styles map[string]string,
+) string {
+	diagram := flagsToDiagram(roles, usableRoles, refreshMinutes)
+	if diagram == "" {
+		return ""
+	}
+
+	var builder strings.Builder
+
+	builder.WriteString(strings.Replace(diagram, "stateDiagram\n", "stateDiagram-v2\n", 1))
+
+	for _, style := range hopStyles {
+		builder.WriteString("    classDef ")
+		builder.WriteString(style[0])
+		builder.WriteString(" ")
+		builder.WriteString(style[1])
+		builder.WriteString("\n")
+	}
+
+	for pos, role := range roles {
+		style, found := styles[role]
+		if !found {
+			continue
+		}
+
+		builder.WriteString("    class ")
+		builder.WriteString(broadcast.NodeID(pos))
+		builder.WriteString(" ")
+		builder.WriteString(style)
+		builder.WriteString("\n")
+	}
+
+	return builder.String()
+}
+
+// MermaidSource returns the decompressed Mermaid.js bundle for pages that inline it.
+func MermaidSource() ([]byte, error) {
+	reader, err := gzip.NewReader(bytes.NewReader(MermaidScript))
+	if err != nil {
+		return nil, fmt.Errorf("failed to open mermaid bundle: %w", err)
+	}
+
+	defer func() { _ = reader.Close() }()
+
+	source, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read mermaid bundle: %w", err)
+	}
+
+	return source, nil
+}
+
+// RenderDiagramHTML pre-renders the diagram HTML once at startup for optimal performance. The page draws this diagram
+// first and then follows the live one served by the API.
 func RenderDiagramHTML(
 	roles []string,
 	usableRoles map[string]struct{},
 	refreshMinutes int64,
 ) (string, error) {
-	diagram := flagsToDiagram(roles, usableRoles, refreshMinutes)
+	diagram := LiveDiagram(roles, usableRoles, refreshMinutes, nil)
 
 	tmpl, err := template.New("diagram").Parse(DiagramTemplate)
 	if err != nil {
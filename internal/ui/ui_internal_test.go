@@ -81,6 +81,22 @@ func Test_flagsToDiagram(t *testing.T) {
 				"r1 --> r0: wait 15min and jump",
 			},
 		},
+		{
+			name: "same name in two accounts",
+			roles: []string{
+				"arn:aws:iam::111111111111:role/trick-role-a",
+				"arn:aws:iam::222222222222:role/trick-role-a",
+				"arn:aws:iam::111111111111:role/trick-role-ab",
+			},
+			usableRoles:    map[string]struct{}{},
+			refreshMinutes: 12,
+			wantContains: []string{
+				"r0: trick-role-a (111111111111)\n",
+				"r1: trick-role-a (222222222222)\n",
+				"r2: trick-role-ab\n",
+				"r2 --> r0: wait 12min and jump",
+			},
+		},
 		{
 			name:           "empty roles returns empty string",
 			roles:          []string{},
@@ -42,6 +42,8 @@ func TestRenderDiagramHTML(t *testing.T) {
 				"RoleA",
 				"RoleB",
 				"RoleC",
+				`id="status-expiration"`,
+				`fetch("/api/status")`,
 			},
 		},
 	}
@@ -82,3 +84,40 @@ func TestRenderDiagramHTML(t *testing.T) {
 		})
 	}
 }
+
+func TestLiveDiagram(t *testing.T) {
+	t.Parallel()
+
+	roles := []string{
+		"arn:aws:iam::123456789012:role/RoleA",
+		"arn:aws:iam::123456789012:role/RoleB",
+		"arn:aws:iam::123456789012:role/RoleC",
+	}
+
+	got := ui.LiveDiagram(roles, map[string]struct{}{roles[0]: {}}, 10, map[string]string{
+		roles[1]: ui.HopDisabled,
+		roles[2]: ui.HopUnhealthy,
+	})
+
+	for _, want := range []string{
+		"stateDiagram-v2\n",
+		"r0 --> r1: wait 10min and jump",
+		"classDef disabled ",
+		"classDef unhealthy ",
+		"classDef pinned ",
+		"class r1 disabled\n",
+		"class r2 unhealthy\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("LiveDiagram() missing %q:\n%s", want, got)
+		}
+	}
+
+	if strings.Contains(got, "class r0") {
+		t.Errorf("LiveDiagram() styles r0 without a style:\n%s", got)
+	}
+
+	if ui.LiveDiagram(nil, nil, 10, nil) != "" {
+		t.Error("LiveDiagram() without roles should be empty")
+	}
+}
@@ -0,0 +1,110 @@
+// Copyright 2025 variHQ OÜ
+// SPDX-License-Identifier: BSD-3-Clause
+
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"text/tabwriter"
+
+	"github.com/wakeful/trick/internal/arn"
+	"github.com/wakeful/trick/internal/detect"
+	"github.com/wakeful/trick/internal/iam"
+)
+
+var (
+	// ErrMissingPolicies indicates that lint-policies needs the -policies directory.
+	ErrMissingPolicies = errors.New("path to IAM policy documents is required")
+	// ErrLintFailed indicates that at least one edge of the ring would be refused.
+	ErrLintFailed = errors.New("one or more ring edges are not allowed")
+)
+
+// lintPoliciesCommand checks offline that every hop of the ring, including the wrap-around, is allowed by the
+// exported IAM trust and permission policies.
+//
+//nolint:cyclop,funlen
+func lintPoliciesCommand(_ context.Context, args []string, stdout io.Writer) error {
+	flags := flag.NewFlagSet("lint-policies", flag.ContinueOnError)
+	config := flags.String("config", "", "path to config file")
+	profileName := flags.String("profile", "", "profile to check (default select_profile)")
+	policies := flags.String(
+		"policies",
+		"",
+		"directory of IAM JSON documents, e.g. from aws iam get-account-authorization-details",
+	)
+
+	ok, err := parseFlags(flags, args)
+	if !ok {
+		return err
+	}
+
+	if *policies == "" {
+		return fmt.Errorf("%w: %w", ErrUsage, ErrMissingPolicies)
+	}
+
+	profile, err := loadProfile(*config, *profileName)
+	if err != nil {
+		return err
+	}
+
+	catalog, err := iam.LoadDir(*policies)
+	if err != nil {
+		return fmt.Errorf("failed to load policies: %w", err)
+	}
+
+	chain := detect.Chain{
+		Name:        profile.Name,
+		Roles:       make([]string, 0, len(profile.Chain.UseRoles)),
+		SessionName: sessionName,
+		TTL:         profile.Chain.TTL,
+	}
+
+	for _, role := range profile.Chain.UseRoles {
+		chain.Roles = append(chain.Roles, role.ARN)
+	}
+
+	edges, err := chain.Edges()
+	if err != nil {
+		return fmt.Errorf("invalid chain: %w", err)
+	}
+
+	const padding = 2
+
+	table := tabwriter.NewWriter(stdout, 0, 0, padding, ' ', 0)
+	_, _ = fmt.Fprintln(table, "HOP\tSOURCE\tTARGET\tRESULT\tREASON")
+
+	failed := 0
+
+	for _, edge := range edges {
+		check := catalog.CheckEdge(edge.Source, edge.Target, sessionName)
+
+		result := "PASS"
+		if !check.Pass {
+			result = "FAIL"
+			failed++
+		}
+
+		_, _ = fmt.Fprintf(table, "%d\t%s\t%s\t%s\t%s\n",
+			edge.Index,
+			arn.Name(edge.Source),
+			arn.Name(edge.Target),
+			result,
+			check.Reason,
+		)
+	}
+
+	err = table.Flush()
+	if err != nil {
+		return fmt.Errorf("failed to write lint results: %w", err)
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%w: %d of %d", ErrLintFailed, failed, len(edges))
+	}
+
+	return nil
+}
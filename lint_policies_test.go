@@ -0,0 +1,85 @@
+// Copyright 2025 variHQ OÜ
+// SPDX-License-Identifier: BSD-3-Clause
+
+package main
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// ringPolicies trusts a -> b and b -> c but leaves the wrap-around c -> a out.
+const ringPolicies = `{"RoleDetailList": [
+  {"Arn": "arn:aws:iam::123456789012:role/trick-role-a", "RoleName": "trick-role-a",
+   "AssumeRolePolicyDocument": {"Statement": [{"Effect": "Allow", "Action": "sts:AssumeRole",
+     "Principal": {"AWS": "arn:aws:iam::123456789012:root"}}]}},
+  {"Arn": "arn:aws:iam::123456789012:role/trick-role-b", "RoleName": "trick-role-b",
+   "AssumeRolePolicyDocument": {"Statement": [{"Effect": "Allow", "Action": "sts:AssumeRole",
+     "Principal": {"AWS": "arn:aws:iam::123456789012:role/trick-role-a"}}]}},
+  {"Arn": "arn:aws:iam::123456789012:role/trick-role-c", "RoleName": "trick-role-c",
+   "AssumeRolePolicyDocument": {"Statement": [{"Effect": "Allow", "Action": "sts:AssumeRole",
+     "Principal": {"AWS": "arn:aws:iam::123456789012:role/trick-role-b"}}]}}
+]}`
+
+func TestLintPoliciesCommand(t *testing.T) {
+	t.Parallel()
+
+	config := writeConfig(t, ringConfig)
+	policies := t.TempDir()
+
+	err := os.WriteFile(filepath.Join(policies, "account.json"), []byte(ringPolicies), 0o600)
+	if err != nil {
+		t.Fatalf("failed to write policies: %v", err)
+	}
+
+	tests := []struct {
+		name         string
+		args         []string
+		wantErr      error
+		wantContains string
+	}{
+		{
+			name:    "missing policies",
+			args:    []string{"-config", config},
+			wantErr: ErrMissingPolicies,
+		},
+		{
+			name:    "missing config",
+			args:    []string{"-policies", policies},
+			wantErr: ErrMissingConfig,
+		},
+		{
+			name:         "passing hop",
+			args:         []string{"-config", config, "-policies", policies},
+			wantErr:      ErrLintFailed,
+			wantContains: "trick-role-a  trick-role-b  PASS",
+		},
+		{
+			name:         "wrap-around hop needs an identity policy",
+			args:         []string{"-config", config, "-policies", policies},
+			wantErr:      ErrLintFailed,
+			wantContains: "trick-role-c  trick-role-a  FAIL",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var stdout bytes.Buffer
+
+			err := lintPoliciesCommand(t.Context(), tt.args, &stdout)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("lint-policies error = %v, want %v", err, tt.wantErr)
+			}
+
+			if !strings.Contains(stdout.String(), tt.wantContains) {
+				t.Errorf("lint-policies output missing %q:\n%s", tt.wantContains, stdout.String())
+			}
+		})
+	}
+}
@@ -6,14 +6,21 @@ package main
 
 import (
 	"context"
+	"errors"
 	"flag"
+	"fmt"
+	"io"
 	"log/slog"
+	"net"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
 	"time"
 
-	"github.com/wakeful/trick/internal/parser"
+	"github.com/wakeful/trick/internal/arn"
+	"github.com/wakeful/trick/internal/audit"
+	"github.com/wakeful/trick/internal/state"
 	"github.com/wakeful/trick/internal/ui"
 )
 
@@ -22,80 +29,94 @@ const (
 	cleanupWaitDuration = 100 * time.Millisecond
 	defaultProfileName  = "trick-jump-credentials"
 	defaultRefreshTime  = 12
+	// sessionName is the RoleSessionName used for every AssumeRole call.
+	sessionName = arn.SessionName
 )
 
 var version = "dev"
 
-//nolint:funlen
 func main() {
-	config := flag.String("config", "", "path to config file")
-	refresh := flag.Int64("refresh", defaultRefreshTime, "refresh IAM every n minutes")
-	region := flag.String("region", "eu-west-1", "AWS region used for IAM communication")
-	showVersion := flag.Bool("version", false, "show version")
-	verbose := flag.Bool("verbose", false, "verbose log output")
-	withUI := flag.Bool("ui", false, "starts role visualization on port 8742")
-
-	var (
-		roleVars    StringSlice
-		useRoleVars StringSlice
-	)
-
-	flag.Var(
-		&roleVars,
-		"role",
-		"AWS role ARN to assume (can be specified multiple times, at least 2 required)",
-	)
-	flag.Var(
-		&useRoleVars,
-		"use",
-		"AWS role ARN with meaningful permissions to prioritize (must exist in -role list)",
-	)
-
-	flag.Parse()
-
-	slog.SetDefault(getLogger(os.Stderr, verbose))
+	slog.SetDefault(getLogger(os.Stderr, nil))
 
-	if *showVersion {
-		slog.Info(
-			"trick",
-			slog.String("repo", "https://github.com/wakeful/trick"),
-			slog.String("version", version),
-		)
+	err := execute(context.Background(), os.Args[1:], os.Stdout)
+	if err != nil {
+		slog.Error("command failed", errorAttrs(err)...)
+		os.Exit(exitCode(err))
+	}
+}
 
-		return
+// execute runs the subcommand named by args[0]; flags without a subcommand are an alias for `trick run`.
+func execute(ctx context.Context, args []string, stdout io.Writer) error {
+	if len(args) == 0 || strings.HasPrefix(args[0], "-") {
+		return runCommand(ctx, args, stdout)
 	}
 
-	if *config != "" {
-		slog.Debug("loading config file", slog.String("path", *config))
+	found, err := dispatch(ctx, args, stdout)
+	if !found {
+		return fmt.Errorf("%w: %q, see trick help", ErrUnknownCommand, args[0])
+	}
 
-		cfgFile, err := parser.ParseFile(*config)
-		if err != nil {
-			slog.Error("failed to parse config file", slog.String("error", err.Error()))
+	return err
+}
 
-			return
-		}
+// runCommand keeps the ring of roles rotating until it is interrupted or a guardrail trips.
+//
+//nolint:cyclop,funlen
+func runCommand(ctx context.Context, args []string, stdout io.Writer) error {
+	flags := flag.NewFlagSet("run", flag.ContinueOnError)
+	showVersion := flags.Bool("version", false, "show version")
+	declareRunFlags(flags)
+	flags.Usage = func() {
+		_, _ = fmt.Fprintln(flags.Output(), "Usage: trick [run] [flags]")
+		_, _ = fmt.Fprintln(flags.Output(), "\nAssume the roles of the ring in turn and keep the output profile fresh.")
+		_, _ = fmt.Fprintln(flags.Output(), "Run `trick help` for the other commands.\n\nFlags:")
+		flags.PrintDefaults()
+	}
 
-		*refresh, roleVars, useRoleVars, err = cfgFile.ToFlags()
-		if err != nil {
-			slog.Error("failed to convert config to flags", slog.String("error", err.Error()))
+	ok, err := parseFlags(flags, args)
+	if !ok {
+		return err
+	}
 
-			return
-		}
+	if *showVersion {
+		return versionCommand(ctx, nil, stdout)
 	}
 
-	ctx, cancel := context.WithCancel(context.Background())
+	resolved, err := resolveSettings(flags, os.Getenv)
+	if err != nil {
+		return withClass(ClassConfig, err)
+	}
+
+	slog.SetDefault(getLogger(os.Stderr, &resolved.verbose.value))
+
+	refresh := resolved.refresh.value
+	roleVars := resolved.roles.value
+	useRoleVars := resolved.useRoles.value
+
+	guardrails, err := NewGuardrails(resolved.guardrails)
+	if err != nil {
+		return withClass(ClassConfig, fmt.Errorf("failed to load guardrails: %w", err))
+	}
+
+	server, err := resolved.serverConfig()
+	if err != nil && resolved.ui.value {
+		return withClass(ClassConfig, fmt.Errorf("invalid UI server settings: %w", err))
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
 
 	signalCtx, stop := signal.NotifyContext(ctx, syscall.SIGINT, syscall.SIGTERM, syscall.SIGHUP)
 	defer stop()
 
 	slog.Info("starting app")
 
-	ticker := time.NewTicker(time.Minute * time.Duration(*refresh))
+	ticker := time.NewTicker(time.Minute * time.Duration(refresh))
 
-	if *refresh < 1 {
+	if refresh < 1 {
 		slog.Warn("refresh interval too low, setting to 1 minute")
 
-		*refresh = 1
+		refresh = 1
 		ticker = time.NewTicker(time.Minute)
 	}
 
@@ -107,27 +128,124 @@ func main() {
 		cancel()
 	}()
 
-	app, err := NewApp(ctx, *region, roleVars, useRoleVars)
+	manifest, err := loadManifest(resolved.state.value)
 	if err != nil {
-		slog.Error("failed to initialize app", slog.String("error", err.Error()))
+		return withClass(ClassBootstrap, err)
+	}
 
-		return
+	artifacts, err := outputProfileArtifacts(defaultProfileName)
+	if err != nil {
+		return withClass(ClassBootstrap, fmt.Errorf("failed to locate aws profile: %w", err))
 	}
 
-	if *withUI {
-		preRenderedHTML, err := ui.RenderDiagramHTML(roleVars, app.usableRoles, *refresh)
+	var auditLog *audit.Logger
+
+	if resolved.audit.value != "" {
+		const auditFileMode = 0o600
+
+		auditFile, err := os.OpenFile(
+			resolved.audit.value,
+			os.O_APPEND|os.O_CREATE|os.O_WRONLY,
+			auditFileMode,
+		)
 		if err != nil {
-			slog.Error("failed to render diagram HTML", slog.String("error", err.Error()))
+			return withClass(ClassBootstrap, fmt.Errorf("failed to open audit log: %w", err))
+		}
+
+		defer func() { _ = auditFile.Close() }()
 
-			return
+		artifacts = append(artifacts, state.Artifact{ //nolint:exhaustruct
+			Kind: state.KindAuditLog,
+			Path: absPath(resolved.audit.value),
+		})
+
+		auditLog = audit.NewLogger(auditFile)
+		auditLog.Record(audit.Entry{ //nolint:exhaustruct
+			Event:       audit.EventStart,
+			Chain:       "main",
+			Roles:       roleVars,
+			UsableRoles: useRoleVars,
+			Refresh:     refresh,
+		})
+
+		defer auditLog.Record(audit.Entry{Event: audit.EventStop, Chain: "main"}) //nolint:exhaustruct
+	}
+
+	app, err := NewApp(ctx, resolved.region.value, roleVars, useRoleVars, guardrails)
+	if err != nil {
+		if errors.Is(err, ErrGuardrailTripped) {
+			auditLog.Record(audit.Entry{ //nolint:exhaustruct
+				Event:     audit.EventGuardrailTripped,
+				Chain:     "main",
+				Guardrail: guardrailName(err),
+				Class:     string(ClassGuardrail),
+				Error:     err.Error(),
+			})
+		}
+
+		return withClass(ClassBootstrap, fmt.Errorf("failed to initialize app: %w", err))
+	}
+
+	app.audit = auditLog
+	app.refresh = time.Minute * time.Duration(refresh)
+	app.entries = resolved.entries
+
+	app.loadSettings = func() (*settings, error) { return resolveSettings(flags, os.Getenv) }
+
+	controlSocket, err := socketPath(resolved.socket.value)
+	if err != nil {
+		return withClass(ClassBootstrap, err)
+	}
+
+	listener, err := listenSocket(ctx, controlSocket)
+	if err != nil {
+		slog.Warn("control socket disabled", slog.String("error", err.Error()))
+	} else {
+		artifacts = append(artifacts, state.Artifact{ //nolint:exhaustruct
+			Kind: state.KindSocket,
+			Path: absPath(controlSocket),
+		})
+
+		go serveControlSocket(ctx, app, listener)
+	}
+
+	var uiListener net.Listener
+
+	if resolved.ui.value {
+		uiListener, err = server.listener(ctx)
+		if err != nil {
+			return withClass(ClassBootstrap, err)
 		}
 
-		go startSSEServer(ctx, app.broadcaster, preRenderedHTML)
+		if server.socket != "" {
+			artifacts = append(artifacts, state.Artifact{ //nolint:exhaustruct
+				Kind: state.KindSocket,
+				Path: absPath(server.socket),
+			})
+		}
 	}
 
-	app.run(ctx, ticker)
+	recordArtifacts(manifest, artifacts...)
+
+	if resolved.ui.value {
+		preRenderedHTML, err := ui.RenderDiagramHTML(roleVars, app.usableRoles, refresh)
+		if err != nil {
+			return withClass(ClassBootstrap, fmt.Errorf("failed to render diagram HTML: %w", err))
+		}
+
+		go startSSEServer(ctx, app, preRenderedHTML, server, uiListener)
+	}
+
+	errRun := app.run(ctx, ticker)
 
 	slog.Info("cleaning up resources...")
 	time.Sleep(cleanupWaitDuration)
+
+	if errRun != nil {
+		return errRun
+	}
+
 	slog.Info("application terminated gracefully")
+
+	return nil
 }
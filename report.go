@@ -0,0 +1,90 @@
+// Copyright 2025 variHQ OÜ
+// SPDX-License-Identifier: BSD-3-Clause
+
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/wakeful/trick/internal/audit"
+	"github.com/wakeful/trick/internal/report"
+)
+
+var (
+	// ErrMissingAuditLog indicates that a command needs the -audit path.
+	ErrMissingAuditLog = errors.New("path to the audit log is required")
+	// ErrUnknownFormat indicates that the requested output format is not supported.
+	ErrUnknownFormat = errors.New("unknown output format")
+)
+
+// reportCommand renders the engagement report from the audit log as Markdown or self-contained HTML.
+func reportCommand(_ context.Context, args []string, stdout io.Writer) error {
+	flags := flag.NewFlagSet("report", flag.ContinueOnError)
+	auditPath := flags.String("audit", "", "path to the audit log written by trick -audit")
+	format := flags.String("format", "markdown", "output format: markdown or html")
+	output := flags.String("output", "", "write the report to this file instead of stdout")
+
+	ok, err := parseFlags(flags, args)
+	if !ok {
+		return err
+	}
+
+	if *auditPath == "" {
+		return fmt.Errorf("%w: %w", ErrUsage, ErrMissingAuditLog)
+	}
+
+	logFile, err := os.Open(*auditPath)
+	if err != nil {
+		return fmt.Errorf("failed to open audit log: %w", err)
+	}
+
+	defer func() { _ = logFile.Close() }()
+
+	entries, err := audit.Read(logFile)
+	if err != nil {
+		return fmt.Errorf("failed to read audit log: %w", err)
+	}
+
+	rep, err := report.Build(entries)
+	if err != nil {
+		return fmt.Errorf("failed to build report: %w", err)
+	}
+
+	var rendered string
+
+	switch *format {
+	case "markdown", "md":
+		rendered, err = rep.Markdown()
+	case "html":
+		rendered, err = rep.HTML()
+	default:
+		return fmt.Errorf("%w: %q", ErrUnknownFormat, *format)
+	}
+
+	if err != nil {
+		return fmt.Errorf("failed to render report: %w", err)
+	}
+
+	if *output == "" {
+		_, err = io.WriteString(stdout, rendered)
+		if err != nil {
+			return fmt.Errorf("failed to write report: %w", err)
+		}
+
+		return nil
+	}
+
+	const reportFileMode = 0o600
+
+	err = os.WriteFile(*output, []byte(rendered), reportFileMode)
+	if err != nil {
+		return fmt.Errorf("failed to write report: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,79 @@
+// Copyright 2025 variHQ OÜ
+// SPDX-License-Identifier: BSD-3-Clause
+
+package main
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestReportCommand(t *testing.T) {
+	t.Parallel()
+
+	auditPath := filepath.Join(t.TempDir(), "audit.jsonl")
+	auditLog := strings.Join([]string{
+		`{"time":"2025-01-01T10:00:00Z","event":"start","roles":["arn::42::role-a","arn::42::role-b"],"refresh":12}`,
+		`{"time":"2025-01-01T10:00:01Z","event":"assume","role":"arn::42::role-a","usable":true}`,
+		`{"time":"2025-01-01T10:00:02Z","event":"credentials-written","role":"arn::42::role-a"}`,
+		`{"time":"2025-01-01T10:10:00Z","event":"stop"}`,
+	}, "\n")
+
+	err := os.WriteFile(auditPath, []byte(auditLog), 0o600)
+	if err != nil {
+		t.Fatalf("failed to write audit log: %v", err)
+	}
+
+	tests := []struct {
+		name         string
+		args         []string
+		wantErr      error
+		wantContains string
+	}{
+		{
+			name:    "missing audit log",
+			args:    []string{},
+			wantErr: ErrMissingAuditLog,
+		},
+		{
+			name:    "unknown format",
+			args:    []string{"-audit", auditPath, "-format", "pdf"},
+			wantErr: ErrUnknownFormat,
+		},
+		{
+			name:         "markdown",
+			args:         []string{"-audit", auditPath},
+			wantContains: "# trick engagement report",
+		},
+		{
+			name:         "html",
+			args:         []string{"-audit", auditPath, "-format", "html"},
+			wantContains: "<!doctype html>",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var stdout bytes.Buffer
+
+			found, err := dispatch(t.Context(), append([]string{"report"}, tt.args...), &stdout)
+			if !found {
+				t.Fatal("dispatch() did not find the report command")
+			}
+
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("report error = %v, want %v", err, tt.wantErr)
+			}
+
+			if !strings.Contains(stdout.String(), tt.wantContains) {
+				t.Errorf("report output missing %q", tt.wantContains)
+			}
+		})
+	}
+}
@@ -11,47 +11,229 @@ import (
 	"time"
 
 	"github.com/aws/aws-sdk-go-v2/service/sts/types"
+	"github.com/wakeful/trick/internal/audit"
+	"github.com/wakeful/trick/internal/broadcast"
 )
 
-func (a *App) run(ctx context.Context, ticker *time.Ticker) {
-	err := a.tick(ctx)
-	if err != nil {
-		slog.Error("initial tick failed", slog.String("error", err.Error()))
+// retryDelay is how long run waits before retrying a tick that STS throttled.
+const retryDelay = 30 * time.Second
 
-		return
-	}
+// run hops on every tick until ctx is done, returning the classified error of the first tick that fails.
+// Throttled ticks are retried after retryDelay instead, for as long as the previous credentials remain usable.
+// Control requests are served between ticks, so only this goroutine ever touches the ring and the STS client.
+func (a *App) run(ctx context.Context, ticker *time.Ticker) error {
+	retry := time.NewTimer(0)
+	defer retry.Stop()
 
 	for {
 		select {
 		case <-ticker.C:
-			err := a.tick(ctx)
+		case <-retry.C:
+		case req := <-a.control:
+			err := a.serveControl(ctx, req, ticker, retry)
 			if err != nil {
-				slog.Error("tick failed", slog.String("error", err.Error()))
-
-				return
+				return err
 			}
 
-			slog.Info("credentials refresh")
-
+			continue
 		case <-ctx.Done():
 			ticker.Stop()
 
-			return
+			return nil
+		}
+
+		if a.paused {
+			// A pause holds the current role, but the kill date is still enforced on every tick.
+			err := a.checkGuardrails()
+			if err != nil {
+				a.publishBroken(err)
+
+				return err
+			}
+
+			slog.Debug("rotation paused, skipping tick")
+
+			continue
+		}
+
+		err := a.step(ctx, retry)
+		if err != nil && !retryable(err) {
+			return err
 		}
 	}
 }
 
+// retryable reports whether a failed tick is retried after retryDelay instead of stopping the run.
+func retryable(err error) bool {
+	return errorClass(err) == ClassThrottled
+}
+
+// step runs a tick, reports its outcome and returns its error. A retryable failure is scheduled on retry.
+//
+//nolint:funlen
+func (a *App) step(ctx context.Context, retry *time.Timer) error {
+	initial := !a.started
+	a.started = true
+
+	err := a.tick(ctx)
+	if err != nil {
+		if initial {
+			slog.Error("initial tick failed", errorAttrs(err)...)
+		} else {
+			slog.Error("tick failed", errorAttrs(err)...)
+		}
+
+		if a.brokenAt.IsZero() {
+			a.brokenAt = time.Now()
+		}
+
+		a.publishBroken(err)
+
+		retrying := retryable(err)
+		a.setStatus(func(status *liveStatus) {
+			status.State = stateBroken
+			status.LastError = &statusError{
+				Time:    time.Now().UTC(),
+				Class:   string(errorClass(err)),
+				Message: err.Error(),
+			}
+			status.NextJump = nil
+
+			if retrying {
+				next := time.Now().Add(retryDelay).UTC()
+				status.NextJump = &next
+			}
+		})
+
+		if retrying {
+			a.warnExpiry(retryDelay)
+			retry.Reset(retryDelay)
+		}
+
+		return err
+	}
+
+	retry.Stop()
+
+	a.setStatus(func(status *liveStatus) {
+		status.State = a.state()
+		status.NextJump = nil
+
+		if a.refresh > 0 && !a.paused {
+			next := time.Now().Add(a.refresh).UTC()
+			status.NextJump = &next
+		}
+	})
+
+	if !a.brokenAt.IsZero() {
+		slog.Info("chain recovered", slog.Duration("broken_for", time.Since(a.brokenAt)))
+
+		a.broadcaster.Publish(broadcast.Message{ //nolint:exhaustruct
+			Event:      broadcast.EventChainRecovered,
+			Chain:      "main",
+			Role:       a.current,
+			Hop:        a.hop(),
+			DurationMS: time.Since(a.brokenAt).Milliseconds(),
+		})
+
+		a.brokenAt = time.Time{}
+	}
+
+	if !initial {
+		slog.Info("credentials refresh")
+	}
+
+	return nil
+}
+
+// publishBroken tells subscribers that the tick failed with err while holding the current role.
+func (a *App) publishBroken(err error) {
+	a.broadcaster.Publish(broadcast.Message{ //nolint:exhaustruct
+		Event: broadcast.EventChainBroken,
+		Chain: "main",
+		Role:  a.current,
+		Hop:   a.hop(),
+		Class: string(errorClass(err)),
+		Error: err.Error(),
+	})
+}
+
+// warnExpiry publishes an expiry warning when the written credentials expire within next.
+func (a *App) warnExpiry(next time.Duration) {
+	if a.expiration == nil || next <= 0 || a.expiration.After(time.Now().Add(next)) {
+		return
+	}
+
+	slog.Warn("credentials expire before the next refresh", slog.Time("expiration", *a.expiration))
+
+	a.broadcaster.Publish(broadcast.Message{ //nolint:exhaustruct
+		Event:      broadcast.EventExpiryWarning,
+		Chain:      "main",
+		Role:       a.current,
+		Hop:        a.hop(),
+		Expiration: a.expiration,
+	})
+}
+
+// hop returns the ring position of the role assumed last.
+func (a *App) hop() int {
+	size := a.roles.Len()
+
+	return (a.position + size - 1) % size
+}
+
 func (a *App) tick(ctx context.Context) error {
+	err := a.checkGuardrails()
+	if err != nil {
+		return err
+	}
+
 	credentials, err := a.assumeNextInterestingRole(ctx)
 	if err != nil {
 		return fmt.Errorf("unable to assume role on tick: %w", err)
 	}
 
+	started := time.Now()
+
 	errWrite := a.profileWriter.writeAWSProfile(ctx, credentials, a.region)
 	if errWrite != nil {
-		return fmt.Errorf("unable to write AWS credentials: %w", errWrite)
+		a.audit.Record(audit.Entry{ //nolint:exhaustruct
+			Event: audit.EventWriteFailed,
+			Chain: "main",
+			Role:  a.current,
+			Class: string(ClassWriteFailure),
+			Error: errWrite.Error(),
+		})
+
+		return withClass(ClassWriteFailure, fmt.Errorf("unable to write AWS credentials: %w", errWrite))
 	}
 
+	a.audit.Record(audit.Entry{ //nolint:exhaustruct
+		Event:      audit.EventCredentialsWritten,
+		Chain:      "main",
+		Role:       a.current,
+		Expiration: credentials.Expiration,
+	})
+
+	a.broadcaster.Publish(broadcast.Message{ //nolint:exhaustruct
+		Event:      broadcast.EventCredentialsWritten,
+		Chain:      "main",
+		Role:       a.current,
+		Hop:        a.hop(),
+		Expiration: credentials.Expiration,
+		DurationMS: time.Since(started).Milliseconds(),
+	})
+
+	a.expiration = credentials.Expiration
+	a.setStatus(func(status *liveStatus) {
+		now := time.Now().UTC()
+
+		status.Expiration = credentials.Expiration
+		status.LastWrite = &now
+		a.credentials = credentials
+	})
+	a.warnExpiry(a.refresh)
+
 	return nil
 }
 
@@ -105,6 +105,7 @@ func TestApp_run(t *testing.T) {
 		profileWriteFunc func(_ string, _ ...string) ([]byte, error)
 		runDuration      time.Duration
 		useCancel        bool
+		wantClass        ErrorClass
 	}{
 		{
 			name:             "runs successfully",
@@ -117,6 +118,7 @@ func TestApp_run(t *testing.T) {
 			profileWriteFunc: func(_ string, _ ...string) ([]byte, error) { return nil, errors.New("failed to write profile") },
 			runDuration:      300 * time.Millisecond,
 			useCancel:        false,
+			wantClass:        ClassWriteFailure,
 		},
 	}
 	for _, tt := range tests {
@@ -159,7 +161,11 @@ func TestApp_run(t *testing.T) {
 			done := make(chan struct{})
 
 			go func() {
-				a.run(ctx, time.NewTicker(tt.runDuration))
+				err := a.run(ctx, time.NewTicker(tt.runDuration))
+				if errorClass(err) != tt.wantClass {
+					t.Errorf("run() error = %v, want class %q", err, tt.wantClass)
+				}
+
 				close(done)
 			}()
 
@@ -180,3 +186,55 @@ func TestApp_run(t *testing.T) {
 		})
 	}
 }
+
+func TestApp_warnExpiry(t *testing.T) {
+	t.Parallel()
+
+	soon := time.Now().Add(5 * time.Minute)
+	later := time.Now().Add(time.Hour)
+
+	tests := []struct {
+		name       string
+		expiration *time.Time
+		next       time.Duration
+		want       bool
+	}{
+		{name: "nothing written yet", expiration: nil, next: 12 * time.Minute, want: false},
+		{name: "expires before the next refresh", expiration: &soon, next: 12 * time.Minute, want: true},
+		{name: "outlives the next refresh", expiration: &later, next: 12 * time.Minute, want: false},
+		{name: "no refresh interval", expiration: &soon, next: 0, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			pool, _ := setRolePool([]string{
+				"arn:aws:iam::0987654321:role/role-a",
+				"arn:aws:iam::0987654321:role/role-b",
+			})
+
+			a := &App{ //nolint:exhaustruct
+				roles:       pool,
+				expiration:  tt.expiration,
+				broadcaster: broadcast.NewBroadcaster(),
+			}
+
+			events, unsubscribe := a.broadcaster.Subscribe()
+			defer unsubscribe()
+
+			a.warnExpiry(tt.next)
+
+			select {
+			case got := <-events:
+				if !tt.want || got.Event != broadcast.EventExpiryWarning {
+					t.Errorf("warnExpiry() published %+v, want warning %v", got, tt.want)
+				}
+			default:
+				if tt.want {
+					t.Error("warnExpiry() published nothing, want an expiry warning")
+				}
+			}
+		})
+	}
+}
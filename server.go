@@ -0,0 +1,319 @@
+// Copyright 2025 variHQ OÜ
+// SPDX-License-Identifier: BSD-3-Clause
+
+package main
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/big"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+var (
+	// ErrUnsafeListen indicates a UI server that would be reachable beyond loopback without authentication.
+	ErrUnsafeListen = errors.New("refusing to serve the UI beyond loopback without -token or -basic-auth")
+	// ErrInvalidTLS indicates an incomplete or contradictory TLS setup of the UI server.
+	ErrInvalidTLS = errors.New("-tls-cert and -tls-key must be given together and not with -tls-self-signed")
+)
+
+const (
+	defaultListen       = "127.0.0.1:8742"
+	defaultReadTimeout  = 10 * time.Second
+	defaultWriteTimeout = time.Minute
+	defaultIdleTimeout  = 2 * time.Minute
+	// selfSignedValidity is how long a certificate generated with -tls-self-signed is valid.
+	selfSignedValidity = 30 * 24 * time.Hour
+	// tokenCookie carries the bearer token for browsers that opened the UI with ?token=.
+	tokenCookie = "trick_token"
+)
+
+// serverAuth holds the credentials the UI server requires; with none set, it serves everyone.
+type serverAuth struct {
+	token    string
+	user     string
+	password string
+}
+
+// serverConfig is the resolved setup of the UI server.
+type serverConfig struct {
+	listen       string
+	socket       string
+	auth         serverAuth
+	tlsCert      string
+	tlsKey       string
+	selfSigned   bool
+	readTimeout  time.Duration
+	writeTimeout time.Duration
+	idleTimeout  time.Duration
+}
+
+// serverConfig validates the UI server settings. A TCP address other than loopback is refused unless the server
+// requires a token or basic auth.
+func (s *settings) serverConfig() (serverConfig, error) {
+	user, password, _ := strings.Cut(s.basicAuth.value, ":")
+
+	config := serverConfig{
+		listen: s.listen.value,
+		socket: s.uiSocket.value,
+		auth: serverAuth{
+			token:    s.token.value,
+			user:     user,
+			password: password,
+		},
+		tlsCert:      s.tlsCert.value,
+		tlsKey:       s.tlsKey.value,
+		selfSigned:   s.tlsSelfSigned.value,
+		readTimeout:  s.readTimeout.value,
+		writeTimeout: s.writeTimeout.value,
+		idleTimeout:  s.idleTimeout.value,
+	}
+
+	if (config.tlsCert == "") != (config.tlsKey == "") || (config.tlsCert != "" && config.selfSigned) {
+		return serverConfig{}, ErrInvalidTLS //nolint:exhaustruct
+	}
+
+	if config.socket == "" && !config.auth.enabled() && !isLoopback(config.listen) {
+		return serverConfig{}, fmt.Errorf("%w: %s", ErrUnsafeListen, config.listen) //nolint:exhaustruct
+	}
+
+	return config, nil
+}
+
+// isLoopback reports whether addr only accepts connections from the local host.
+func isLoopback(addr string) bool {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return false
+	}
+
+	if host == "localhost" {
+		return true
+	}
+
+	ip := net.ParseIP(host)
+
+	return ip != nil && ip.IsLoopback()
+}
+
+// url is where the UI server can be reached, for logs.
+func (c serverConfig) url() string {
+	if c.socket != "" {
+		return "unix://" + c.socket
+	}
+
+	if c.tlsCert != "" || c.selfSigned {
+		return "https://" + c.listen
+	}
+
+	return "http://" + c.listen
+}
+
+// listener opens the Unix socket or TCP address of the UI server, wrapped in TLS when configured.
+func (c serverConfig) listener(ctx context.Context) (net.Listener, error) {
+	var (
+		listener net.Listener
+		err      error
+	)
+
+	if c.socket != "" {
+		listener, err = listenSocket(ctx, c.socket)
+	} else {
+		var config net.ListenConfig
+
+		listener, err = config.Listen(ctx, "tcp", c.listen)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen for the UI: %w", err)
+	}
+
+	var certificate tls.Certificate
+
+	switch {
+	case c.tlsCert != "":
+		certificate, err = tls.LoadX509KeyPair(c.tlsCert, c.tlsKey)
+	case c.selfSigned:
+		certificate, err = selfSignedCertificate(c.listen)
+	default:
+		return listener, nil
+	}
+
+	if err != nil {
+		_ = listener.Close()
+
+		return nil, fmt.Errorf("failed to load UI certificate: %w", err)
+	}
+
+	return tls.NewListener(listener, &tls.Config{ //nolint:exhaustruct
+		Certificates: []tls.Certificate{certificate},
+		MinVersion:   tls.VersionTLS12,
+	}), nil
+}
+
+// selfSignedCertificate generates a certificate for localhost and the host of listen and logs its fingerprint, so
+// the browser warning can be checked against it.
+func selfSignedCertificate(listen string) (tls.Certificate, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return tls.Certificate{}, fmt.Errorf("failed to generate key: %w", err) //nolint:exhaustruct
+	}
+
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128)) //nolint:mnd
+	if err != nil {
+		return tls.Certificate{}, fmt.Errorf("failed to generate serial number: %w", err) //nolint:exhaustruct
+	}
+
+	now := time.Now()
+	template := x509.Certificate{ //nolint:exhaustruct
+		SerialNumber: serial,
+		Subject:      pkix.Name{CommonName: "trick"}, //nolint:exhaustruct
+		NotBefore:    now.Add(-time.Minute),
+		NotAfter:     now.Add(selfSignedValidity),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		DNSNames:     []string{"localhost"},
+		IPAddresses:  []net.IP{net.IPv4(127, 0, 0, 1), net.IPv6loopback}, //nolint:mnd
+	}
+
+	host, _, _ := net.SplitHostPort(listen)
+	if ip := net.ParseIP(host); ip != nil && !ip.IsUnspecified() {
+		template.IPAddresses = append(template.IPAddresses, ip)
+	} else if host != "" && ip == nil {
+		template.DNSNames = append(template.DNSNames, host)
+	} else if hostname, errHost := os.Hostname(); errHost == nil {
+		template.DNSNames = append(template.DNSNames, hostname)
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, &template, &template, &key.PublicKey, key)
+	if err != nil {
+		return tls.Certificate{}, fmt.Errorf("failed to create certificate: %w", err) //nolint:exhaustruct
+	}
+
+	slog.Info("generated self-signed UI certificate",
+		slog.String("sha256", certificateFingerprint(der)),
+		slog.Time("not_after", template.NotAfter),
+	)
+
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key}, nil //nolint:exhaustruct
+}
+
+// certificateFingerprint returns the hex SHA-256 of a DER certificate, as logged for self-signed certificates and
+// pinned by trick ctl -fingerprint.
+func certificateFingerprint(der []byte) string {
+	sum := sha256.Sum256(der)
+
+	return hex.EncodeToString(sum[:])
+}
+
+// enabled reports whether the server requires credentials.
+func (a serverAuth) enabled() bool {
+	return a.token != "" || a.user != ""
+}
+
+// authorized reports whether request carries the bearer token, as a header or cookie, or the basic auth credentials.
+func (a serverAuth) authorized(request *http.Request) bool {
+	if a.token != "" {
+		given, found := strings.CutPrefix(request.Header.Get("Authorization"), "Bearer ")
+		if found && equal(given, a.token) {
+			return true
+		}
+
+		cookie, err := request.Cookie(tokenCookie)
+		if err == nil && equal(cookie.Value, a.token) {
+			return true
+		}
+	}
+
+	if a.user != "" {
+		user, password, found := request.BasicAuth()
+		if found && equal(user, a.user) && equal(password, a.password) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func equal(given, want string) bool {
+	return subtle.ConstantTimeCompare([]byte(given), []byte(want)) == 1
+}
+
+// requireAuth lets only authorized requests through to next; without credentials configured, everything passes.
+// A browser opening a page with ?token= gets the token as a cookie and is redirected to the URL without it.
+func requireAuth(auth serverAuth, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		if !auth.enabled() || auth.authorized(request) {
+			next.ServeHTTP(writer, request)
+
+			return
+		}
+
+		query := request.URL.Query()
+		if auth.token != "" && request.Method == http.MethodGet && equal(query.Get("token"), auth.token) {
+			http.SetCookie(writer, &http.Cookie{ //nolint:exhaustruct
+				Name:     tokenCookie,
+				Value:    auth.token,
+				Path:     "/",
+				HttpOnly: true,
+				Secure:   request.TLS != nil,
+				SameSite: http.SameSiteStrictMode,
+			})
+
+			query.Del("token")
+
+			target := *request.URL
+			target.RawQuery = query.Encode()
+			http.Redirect(writer, request, target.RequestURI(), http.StatusSeeOther)
+
+			return
+		}
+
+		if auth.user != "" {
+			writer.Header().Set("WWW-Authenticate", `Basic realm="trick", charset="UTF-8"`)
+		} else {
+			writer.Header().Set("WWW-Authenticate", `Bearer realm="trick"`)
+		}
+
+		http.Error(writer, "invalid or missing credentials", http.StatusUnauthorized)
+	})
+}
+
+// requireControl disables next unless the server requires credentials. Browsers resend basic auth and the token
+// cookie on their own, so cross-origin requests are rejected as well; clients such as trick ctl send neither
+// Origin nor Sec-Fetch-Site and pass.
+func requireControl(auth serverAuth, next http.HandlerFunc) http.HandlerFunc {
+	crossOrigin := http.NewCrossOriginProtection()
+
+	return func(writer http.ResponseWriter, request *http.Request) {
+		if !auth.enabled() {
+			http.Error(writer, "control API is disabled, start trick with -token or -basic-auth", http.StatusForbidden)
+
+			return
+		}
+
+		err := crossOrigin.Check(request)
+		if err != nil {
+			http.Error(writer, err.Error(), http.StatusForbidden)
+
+			return
+		}
+
+		next(writer, request)
+	}
+}
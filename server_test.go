@@ -0,0 +1,241 @@
+// Copyright 2025 variHQ OÜ
+// SPDX-License-Identifier: BSD-3-Clause
+
+package main
+
+import (
+	"crypto/tls"
+	"errors"
+	"flag"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/wakeful/trick/internal/parser"
+)
+
+func TestSettings_serverConfig(t *testing.T) {
+	t.Parallel()
+
+	config := writeConfig(t, ringConfig+`
+profile "exposed" {
+  server {
+    listen        = "0.0.0.0:8742"
+    basic_auth    = "red:team"
+    write_timeout = "5m"
+  }
+}
+`)
+
+	tests := []struct {
+		name             string
+		args             []string
+		env              map[string]string
+		wantErr          error
+		wantListen       string
+		wantUser         string
+		wantWriteTimeout time.Duration
+	}{
+		{name: "defaults", wantListen: defaultListen, wantWriteTimeout: defaultWriteTimeout},
+		{
+			name:             "config file",
+			args:             []string{"-config", config, "-profile", "exposed"},
+			wantListen:       "0.0.0.0:8742",
+			wantUser:         "red",
+			wantWriteTimeout: 5 * time.Minute,
+		},
+		{
+			name:             "env with dashes",
+			env:              map[string]string{"TRICK_WRITE_TIMEOUT": "30s", "TRICK_LISTEN": "[::1]:9000"},
+			wantListen:       "[::1]:9000",
+			wantWriteTimeout: 30 * time.Second,
+		},
+		{
+			name:             "token allows any address",
+			args:             []string{"-listen", ":8742", "-token", "s3cret"},
+			wantListen:       ":8742",
+			wantWriteTimeout: defaultWriteTimeout,
+		},
+		{
+			name:             "unix socket without auth",
+			args:             []string{"-listen", "0.0.0.0:8742", "-ui-socket", "/tmp/trick-ui.sock"},
+			wantListen:       "0.0.0.0:8742",
+			wantWriteTimeout: defaultWriteTimeout,
+		},
+		{name: "exposed without auth", args: []string{"-listen", "0.0.0.0:8742"}, wantErr: ErrUnsafeListen},
+		{name: "hostname without auth", args: []string{"-listen", "example.com:8742"}, wantErr: ErrUnsafeListen},
+		{name: "certificate without key", args: []string{"-tls-cert", "cert.pem"}, wantErr: ErrInvalidTLS},
+		{
+			name:    "certificate and self-signed",
+			args:    []string{"-tls-cert", "cert.pem", "-tls-key", "key.pem", "-tls-self-signed"},
+			wantErr: ErrInvalidTLS,
+		},
+		{name: "invalid timeout", args: []string{"-idle-timeout", "0s"}, wantErr: parser.ErrInvalidTimeout},
+		{name: "invalid basic auth", env: map[string]string{"TRICK_BASIC_AUTH": "red"}, wantErr: ErrInvalidSetting},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			flags := flag.NewFlagSet("trick", flag.ContinueOnError)
+			flags.SetOutput(io.Discard)
+			declareRunFlags(flags)
+
+			err := flags.Parse(tt.args)
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+
+			resolved, err := resolveSettings(flags, func(key string) string { return tt.env[key] })
+			if err == nil {
+				var got serverConfig
+
+				got, err = resolved.serverConfig()
+				if err == nil {
+					if got.listen != tt.wantListen || got.auth.user != tt.wantUser ||
+						got.writeTimeout != tt.wantWriteTimeout {
+						t.Errorf("serverConfig() = %+v, want listen %q, user %q and write timeout %v",
+							got, tt.wantListen, tt.wantUser, tt.wantWriteTimeout)
+					}
+				}
+			}
+
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("serverConfig() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestRequireAuth(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name         string
+		auth         serverAuth
+		prepare      func(request *http.Request)
+		wantStatus   int
+		wantLocation string
+		wantCookie   bool
+	}{
+		{name: "no auth configured", wantStatus: http.StatusOK},
+		{name: "missing token", auth: serverAuth{token: "s3cret"}, wantStatus: http.StatusUnauthorized},
+		{
+			name:       "bearer token",
+			auth:       serverAuth{token: "s3cret"},
+			prepare:    func(request *http.Request) { request.Header.Set("Authorization", "Bearer s3cret") },
+			wantStatus: http.StatusOK,
+		},
+		{
+			name: "token cookie",
+			auth: serverAuth{token: "s3cret"},
+			prepare: func(request *http.Request) {
+				request.AddCookie(&http.Cookie{Name: tokenCookie, Value: "s3cret"})
+			},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name: "token query",
+			auth: serverAuth{token: "s3cret"},
+			prepare: func(request *http.Request) {
+				request.URL.RawQuery = "token=s3cret&chain=main"
+			},
+			wantStatus:   http.StatusSeeOther,
+			wantLocation: "/api/status?chain=main",
+			wantCookie:   true,
+		},
+		{
+			name:       "basic auth",
+			auth:       serverAuth{user: "red", password: "team"},
+			prepare:    func(request *http.Request) { request.SetBasicAuth("red", "team") },
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "wrong password",
+			auth:       serverAuth{user: "red", password: "team"},
+			prepare:    func(request *http.Request) { request.SetBasicAuth("red", "blue") },
+			wantStatus: http.StatusUnauthorized,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			request := httptest.NewRequestWithContext(t.Context(), http.MethodGet, "/api/status", nil)
+			if tt.prepare != nil {
+				tt.prepare(request)
+			}
+
+			recorder := httptest.NewRecorder()
+			requireAuth(tt.auth, http.HandlerFunc(func(writer http.ResponseWriter, _ *http.Request) {
+				writer.WriteHeader(http.StatusOK)
+			})).ServeHTTP(recorder, request)
+
+			if recorder.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", recorder.Code, tt.wantStatus)
+			}
+
+			if location := recorder.Header().Get("Location"); location != tt.wantLocation {
+				t.Errorf("Location = %q, want %q", location, tt.wantLocation)
+			}
+
+			if cookie := recorder.Header().Get("Set-Cookie"); (cookie != "") != tt.wantCookie {
+				t.Errorf("Set-Cookie = %q, want cookie %v", cookie, tt.wantCookie)
+			}
+		})
+	}
+}
+
+func TestServerConfig_listener(t *testing.T) {
+	t.Parallel()
+
+	config := serverConfig{listen: "127.0.0.1:0", selfSigned: true} //nolint:exhaustruct
+
+	listener, err := config.listener(t.Context())
+	if err != nil {
+		t.Fatalf("listener() error = %v", err)
+	}
+
+	server := &http.Server{ //nolint:exhaustruct
+		Handler: http.HandlerFunc(func(writer http.ResponseWriter, _ *http.Request) {
+			writer.WriteHeader(http.StatusNoContent)
+		}),
+		ReadHeaderTimeout: time.Second,
+	}
+
+	go func() { _ = server.Serve(listener) }()
+
+	defer func() { _ = server.Close() }()
+
+	client := &http.Client{ //nolint:exhaustruct
+		Timeout: 5 * time.Second,
+		Transport: &http.Transport{ //nolint:exhaustruct
+			TLSClientConfig: &tls.Config{InsecureSkipVerify: true}, //nolint:exhaustruct,gosec
+		},
+	}
+
+	request, err := http.NewRequestWithContext(t.Context(), http.MethodGet, "https://"+listener.Addr().String(), nil)
+	if err != nil {
+		t.Fatalf("NewRequestWithContext() error = %v", err)
+	}
+
+	response, err := client.Do(request)
+	if err != nil {
+		t.Fatalf("Do() error = %v", err)
+	}
+
+	_ = response.Body.Close()
+
+	if response.StatusCode != http.StatusNoContent {
+		t.Errorf("status = %d, want %d", response.StatusCode, http.StatusNoContent)
+	}
+
+	certificates := response.TLS.PeerCertificates
+	if len(certificates) == 0 || certificates[0].VerifyHostname("localhost") != nil {
+		t.Errorf("certificate does not cover localhost")
+	}
+}
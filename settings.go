@@ -0,0 +1,450 @@
+// Copyright 2025 variHQ OÜ
+// SPDX-License-Identifier: BSD-3-Clause
+
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/wakeful/trick/internal/parser"
+)
+
+// envPrefix prefixes the environment variable of every run setting, e.g. TRICK_REGION for -region.
+const envPrefix = "TRICK_"
+
+// ErrInvalidSetting indicates that an environment variable or flag holds a value of the wrong type.
+var ErrInvalidSetting = errors.New("invalid setting")
+
+// Sources a resolved setting can come from, in increasing precedence.
+const (
+	sourceDefault = "default"
+	sourceConfig  = "config file"
+	sourceEnv     = "env"
+	sourceFlag    = "flag"
+)
+
+// setting is a resolved value together with the layer it was taken from.
+type setting[T any] struct {
+	value  T
+	source string
+}
+
+func (s *setting[T]) set(value T, source string) {
+	s.value = value
+	s.source = source
+}
+
+// settings are the effective options of a plain trick run: defaults, then the config file, then TRICK_* environment
+// variables, then flags given on the command line.
+type settings struct {
+	audit    setting[string]
+	config   setting[string]
+	profile  setting[string]
+	refresh  setting[int64]
+	region   setting[string]
+	roles    setting[[]string]
+	useRoles setting[[]string]
+	socket   setting[string]
+	state    setting[string]
+	token    setting[string]
+	ui       setting[bool]
+	verbose  setting[bool]
+
+	// settings of the UI server, see serverConfig
+	listen        setting[string]
+	uiSocket      setting[string]
+	basicAuth     setting[string]
+	tlsCert       setting[string]
+	tlsKey        setting[string]
+	tlsSelfSigned setting[bool]
+	readTimeout   setting[time.Duration]
+	writeTimeout  setting[time.Duration]
+	idleTimeout   setting[time.Duration]
+
+	guardrails *parser.Guardrails
+	// entries are the roles marked entry in the config file; empty means the first role
+	entries []string
+}
+
+// declareRunFlags declares the flags of a plain trick run on flags; resolveSettings reads back the ones that were set.
+func declareRunFlags(flags *flag.FlagSet) {
+	flags.String("audit", "", "append an audit log of every hop to this file")
+	flags.String("basic-auth", "", "user:password the UI server asks browsers for")
+	flags.String("config", "", "path to config file")
+	flags.Duration("idle-timeout", defaultIdleTimeout, "how long the UI server keeps idle connections open")
+	flags.String("listen", defaultListen, "address of the UI server; beyond loopback it requires -token or -basic-auth")
+	flags.String("profile", "", "profile to run, overriding select_profile in the config file")
+	flags.Int64("refresh", defaultRefreshTime, "refresh IAM every n minutes")
+	flags.String("region", "eu-west-1", "AWS region used for IAM communication")
+	flags.String("socket", "", "path to the control socket used by trick ctl (default in the user cache directory)")
+	flags.Duration("read-timeout", defaultReadTimeout, "how long the UI server waits for a request")
+	flags.String("state", "", "path to the state manifest used by cleanup")
+	flags.String("tls-cert", "", "certificate file to serve the UI over HTTPS with, together with -tls-key")
+	flags.String("tls-key", "", "private key file of -tls-cert")
+	flags.Bool("tls-self-signed", false, "serve the UI over HTTPS with a certificate generated at startup")
+	flags.String(
+		"token",
+		"",
+		"bearer token the UI server requires; its control API is disabled without -token or -basic-auth",
+	)
+	flags.Bool("verbose", false, "verbose log output")
+	flags.Bool("ui", false, "starts role visualization on -listen")
+	flags.String("ui-socket", "", "serve the UI on this Unix socket instead of -listen")
+	flags.Duration("write-timeout", defaultWriteTimeout, "how long the UI server may take to answer a request")
+	flags.Var(
+		&StringSlice{},
+		"role",
+		"AWS role ARN to assume (can be specified multiple times, at least 2 required)",
+	)
+	flags.Var(
+		&StringSlice{},
+		"use",
+		"AWS role ARN with meaningful permissions to prioritize (must exist in -role list)",
+	)
+}
+
+// resolveSettings layers the parsed run flags over TRICK_* variables read with getenv, the config file and defaults.
+func resolveSettings(flags *flag.FlagSet, getenv func(string) string) (*settings, error) {
+	resolved := &settings{
+		audit:    setting[string]{value: "", source: sourceDefault},
+		config:   setting[string]{value: "", source: sourceDefault},
+		profile:  setting[string]{value: "", source: sourceDefault},
+		refresh:  setting[int64]{value: defaultRefreshTime, source: sourceDefault},
+		region:   setting[string]{value: "eu-west-1", source: sourceDefault},
+		roles:    setting[[]string]{value: nil, source: sourceDefault},
+		useRoles: setting[[]string]{value: nil, source: sourceDefault},
+		socket:   setting[string]{value: "", source: sourceDefault},
+		state:    setting[string]{value: "", source: sourceDefault},
+		token:    setting[string]{value: "", source: sourceDefault},
+		ui:       setting[bool]{value: false, source: sourceDefault},
+		verbose:  setting[bool]{value: false, source: sourceDefault},
+
+		listen:        setting[string]{value: defaultListen, source: sourceDefault},
+		uiSocket:      setting[string]{value: "", source: sourceDefault},
+		basicAuth:     setting[string]{value: "", source: sourceDefault},
+		tlsCert:       setting[string]{value: "", source: sourceDefault},
+		tlsKey:        setting[string]{value: "", source: sourceDefault},
+		tlsSelfSigned: setting[bool]{value: false, source: sourceDefault},
+		readTimeout:   setting[time.Duration]{value: defaultReadTimeout, source: sourceDefault},
+		writeTimeout:  setting[time.Duration]{value: defaultWriteTimeout, source: sourceDefault},
+		idleTimeout:   setting[time.Duration]{value: defaultIdleTimeout, source: sourceDefault},
+
+		guardrails: nil,
+		entries:    nil,
+	}
+
+	explicit := make(map[string]string)
+	flags.Visit(func(f *flag.Flag) {
+		if values, ok := f.Value.(*StringSlice); ok {
+			explicit[f.Name] = strings.Join(*values, ",")
+
+			return
+		}
+
+		explicit[f.Name] = f.Value.String()
+	})
+
+	// The config file and profile cannot come from the config file itself, so they are resolved first.
+	for _, name := range []string{"config", "profile"} {
+		err := resolved.apply(name, explicit, getenv)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if resolved.config.value != "" {
+		err := resolved.loadConfig()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	for _, name := range []string{
+		"audit", "refresh", "region", "role", "use", "socket", "state", "token", "ui", "verbose",
+		"listen", "ui-socket", "basic-auth", "tls-cert", "tls-key", "tls-self-signed",
+		"read-timeout", "write-timeout", "idle-timeout",
+	} {
+		err := resolved.apply(name, explicit, getenv)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return resolved, nil
+}
+
+func (s *settings) loadConfig() error {
+	cfgFile, err := parser.ReadFile(s.config.value)
+	if err != nil {
+		return fmt.Errorf("failed to parse config file: %w", err)
+	}
+
+	// Complete fills in a default region and ttl, which must not be reported as coming from the config file.
+	setsRegion := make(map[string]bool, len(cfgFile.Profiles))
+	setsTTL := make(map[string]bool, len(cfgFile.Profiles))
+
+	for _, profile := range cfgFile.Profiles {
+		setsRegion[profile.Name] = profile.Region != ""
+		setsTTL[profile.Name] = profile.Chain != nil && profile.Chain.TTL != 0
+	}
+
+	err = cfgFile.Complete()
+	if err != nil {
+		return fmt.Errorf("failed to parse config file: %w", err)
+	}
+
+	if s.profile.value != "" {
+		cfgFile.SelectProfile = s.profile.value
+	} else {
+		s.profile.set(cfgFile.SelectProfile, sourceConfig)
+	}
+
+	profile, err := cfgFile.Selected()
+	if err != nil {
+		return fmt.Errorf("failed to select profile: %w", err)
+	}
+
+	ttl, roles, useRoles, err := cfgFile.ToFlags()
+	if err != nil {
+		return fmt.Errorf("failed to convert config to flags: %w", err)
+	}
+
+	if setsTTL[profile.Name] {
+		s.refresh.set(ttl, sourceConfig)
+	}
+
+	if profile.Chain != nil {
+		s.roles.set(roles, sourceConfig)
+		s.useRoles.set(useRoles, sourceConfig)
+
+		for _, entry := range profile.Chain.EntryPoints() {
+			s.entries = append(s.entries, entry.ARN)
+		}
+	}
+
+	if setsRegion[profile.Name] {
+		s.region.set(profile.Region, sourceConfig)
+	}
+	s.guardrails = profile.Guardrails
+
+	return s.loadServer(profile.Server)
+}
+
+// loadServer takes the UI server settings the server block of the config file sets.
+func (s *settings) loadServer(server *parser.Server) error {
+	if server == nil {
+		return nil
+	}
+
+	for _, value := range []struct {
+		target *setting[string]
+		value  string
+	}{
+		{&s.listen, server.Listen},
+		{&s.uiSocket, server.Socket},
+		{&s.token, server.Token},
+		{&s.basicAuth, server.BasicAuth},
+		{&s.tlsCert, server.TLSCert},
+		{&s.tlsKey, server.TLSKey},
+	} {
+		if value.value != "" {
+			value.target.set(value.value, sourceConfig)
+		}
+	}
+
+	if server.TLSSelfSigned {
+		s.tlsSelfSigned.set(true, sourceConfig)
+	}
+
+	read, write, idle, err := server.Timeouts()
+	if err != nil {
+		return fmt.Errorf("failed to parse server timeouts: %w", err)
+	}
+
+	for _, value := range []struct {
+		target *setting[time.Duration]
+		value  time.Duration
+	}{
+		{&s.readTimeout, read},
+		{&s.writeTimeout, write},
+		{&s.idleTimeout, idle},
+	} {
+		if value.value != 0 {
+			value.target.set(value.value, sourceConfig)
+		}
+	}
+
+	return nil
+}
+
+// apply overrides the setting behind flag name with its TRICK_* variable, e.g. TRICK_TLS_CERT for -tls-cert, and then
+// with the flag, when either is set.
+func (s *settings) apply(name string, explicit map[string]string, getenv func(string) string) error {
+	env := envPrefix + strings.ToUpper(strings.ReplaceAll(name, "-", "_"))
+
+	if value := getenv(env); value != "" {
+		err := s.setValue(name, value, sourceEnv+" "+env)
+		if err != nil {
+			return fmt.Errorf("%w: %s: %w", ErrInvalidSetting, env, err)
+		}
+	}
+
+	if value, found := explicit[name]; found {
+		err := s.setValue(name, value, sourceFlag+" -"+name)
+		if err != nil {
+			return fmt.Errorf("%w: -%s: %w", ErrInvalidSetting, name, err)
+		}
+	}
+
+	return nil
+}
+
+//nolint:cyclop,funlen
+func (s *settings) setValue(name, value, source string) error {
+	switch name {
+	case "audit":
+		s.audit.set(value, source)
+	case "config":
+		s.config.set(value, source)
+	case "profile":
+		s.profile.set(value, source)
+	case "region":
+		s.region.set(value, source)
+	case "socket":
+		s.socket.set(value, source)
+	case "state":
+		s.state.set(value, source)
+	case "token":
+		s.token.set(value, source)
+	case "listen":
+		s.listen.set(value, source)
+	case "ui-socket":
+		s.uiSocket.set(value, source)
+	case "basic-auth":
+		if user, _, found := strings.Cut(value, ":"); !found || user == "" {
+			return parser.ErrInvalidBasicAuth
+		}
+
+		s.basicAuth.set(value, source)
+	case "tls-cert":
+		s.tlsCert.set(value, source)
+	case "tls-key":
+		s.tlsKey.set(value, source)
+	case "read-timeout", "write-timeout", "idle-timeout":
+		timeout, err := time.ParseDuration(value)
+		if err != nil || timeout <= 0 {
+			return fmt.Errorf("%w: %q", parser.ErrInvalidTimeout, value)
+		}
+
+		target := map[string]*setting[time.Duration]{
+			"read-timeout":  &s.readTimeout,
+			"write-timeout": &s.writeTimeout,
+			"idle-timeout":  &s.idleTimeout,
+		}[name]
+
+		target.set(timeout, source)
+	case "refresh":
+		refresh, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return fmt.Errorf("failed to parse integer: %w", err)
+		}
+
+		s.refresh.set(refresh, source)
+	case "ui", "verbose", "tls-self-signed":
+		enabled, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("failed to parse boolean: %w", err)
+		}
+
+		target := map[string]*setting[bool]{"ui": &s.ui, "verbose": &s.verbose, "tls-self-signed": &s.tlsSelfSigned}[name]
+
+		target.set(enabled, source)
+	case "role", "use":
+		values := splitList(value)
+
+		target := &s.roles
+		if name == "use" {
+			target = &s.useRoles
+		}
+
+		target.set(values, source)
+	}
+
+	return nil
+}
+
+// mask hides a secret setting, keeping only whether it is set.
+func mask(value string) string {
+	if value == "" {
+		return ""
+	}
+
+	return "********"
+}
+
+// splitList splits a comma-separated TRICK_ROLE or TRICK_USE value, ignoring blanks.
+func splitList(value string) []string {
+	values := make([]string, 0)
+
+	for item := range strings.SplitSeq(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			values = append(values, item)
+		}
+	}
+
+	return values
+}
+
+// write prints every setting with its effective value and source as a table.
+func (s *settings) write(output io.Writer) error {
+	table := tabwriter.NewWriter(output, 0, 0, 2, ' ', 0) //nolint:mnd
+
+	rows := [][3]string{
+		{"config", s.config.value, s.config.source},
+		{"profile", s.profile.value, s.profile.source},
+		{"region", s.region.value, s.region.source},
+		{"refresh", strconv.FormatInt(s.refresh.value, 10), s.refresh.source},
+		{"role", strings.Join(s.roles.value, ","), s.roles.source},
+		{"use", strings.Join(s.useRoles.value, ","), s.useRoles.source},
+		{"audit", s.audit.value, s.audit.source},
+		{"socket", s.socket.value, s.socket.source},
+		{"state", s.state.value, s.state.source},
+		{"token", mask(s.token.value), s.token.source},
+		{"ui", strconv.FormatBool(s.ui.value), s.ui.source},
+		{"listen", s.listen.value, s.listen.source},
+		{"ui-socket", s.uiSocket.value, s.uiSocket.source},
+		{"basic-auth", mask(s.basicAuth.value), s.basicAuth.source},
+		{"tls-cert", s.tlsCert.value, s.tlsCert.source},
+		{"tls-key", s.tlsKey.value, s.tlsKey.source},
+		{"tls-self-signed", strconv.FormatBool(s.tlsSelfSigned.value), s.tlsSelfSigned.source},
+		{"read-timeout", s.readTimeout.value.String(), s.readTimeout.source},
+		{"write-timeout", s.writeTimeout.value.String(), s.writeTimeout.source},
+		{"idle-timeout", s.idleTimeout.value.String(), s.idleTimeout.source},
+		{"verbose", strconv.FormatBool(s.verbose.value), s.verbose.source},
+	}
+
+	_, _ = fmt.Fprintln(table, "SETTING\tVALUE\tSOURCE")
+
+	for _, row := range rows {
+		value := row[1]
+		if value == "" {
+			value = "-"
+		}
+
+		_, _ = fmt.Fprintf(table, "%s\t%s\t%s\n", row[0], value, row[2])
+	}
+
+	err := table.Flush()
+	if err != nil {
+		return fmt.Errorf("failed to write settings: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,148 @@
+// Copyright 2025 variHQ OÜ
+// SPDX-License-Identifier: BSD-3-Clause
+
+package main
+
+import (
+	"errors"
+	"flag"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/wakeful/trick/internal/parser"
+)
+
+func TestResolveSettings(t *testing.T) {
+	t.Parallel()
+
+	config := writeConfig(t, ringConfig)
+	// regional sets a region but leaves ttl to its default.
+	regional := writeConfig(t, strings.NewReplacer(
+		"  chain {", "  region = \"us-west-2\"\n\n  chain {",
+		"    ttl = 12\n\n", "",
+	).Replace(ringConfig))
+
+	tests := []struct {
+		name        string
+		args        []string
+		env         map[string]string
+		wantRefresh setting[int64]
+		wantRegion  setting[string]
+		wantRoles   setting[[]string]
+		wantUse     setting[[]string]
+		wantErr     error
+	}{
+		{
+			name:        "defaults",
+			args:        nil,
+			env:         nil,
+			wantRefresh: setting[int64]{value: defaultRefreshTime, source: sourceDefault},
+			wantRegion:  setting[string]{value: "eu-west-1", source: sourceDefault},
+			wantRoles:   setting[[]string]{value: nil, source: sourceDefault},
+			wantUse:     setting[[]string]{value: nil, source: sourceDefault},
+		},
+		{
+			name:        "config file",
+			args:        []string{"-config", config},
+			wantRefresh: setting[int64]{value: 12, source: sourceConfig},
+			wantRegion:  setting[string]{value: "eu-west-1", source: sourceDefault},
+			wantRoles: setting[[]string]{value: []string{
+				"arn:aws:iam::123456789012:role/trick-role-a",
+				"arn:aws:iam::123456789012:role/trick-role-b",
+				"arn:aws:iam::123456789012:role/trick-role-c",
+			}, source: sourceConfig},
+			wantUse: setting[[]string]{
+				value:  []string{"arn:aws:iam::123456789012:role/trick-role-b"},
+				source: sourceConfig,
+			},
+		},
+		{
+			name:        "config file region without ttl",
+			args:        []string{"-config", regional},
+			wantRefresh: setting[int64]{value: defaultRefreshTime, source: sourceDefault},
+			wantRegion:  setting[string]{value: "us-west-2", source: sourceConfig},
+			wantRoles: setting[[]string]{value: []string{
+				"arn:aws:iam::123456789012:role/trick-role-a",
+				"arn:aws:iam::123456789012:role/trick-role-b",
+				"arn:aws:iam::123456789012:role/trick-role-c",
+			}, source: sourceConfig},
+			wantUse: setting[[]string]{
+				value:  []string{"arn:aws:iam::123456789012:role/trick-role-b"},
+				source: sourceConfig,
+			},
+		},
+		{
+			name: "env over config, flags over env",
+			args: []string{"-refresh", "5", "-use", "arn::42::role-a"},
+			env: map[string]string{
+				"TRICK_CONFIG":  config,
+				"TRICK_REFRESH": "7",
+				"TRICK_REGION":  "us-east-1",
+				"TRICK_ROLE":    "arn::42::role-a, arn::42::role-b",
+			},
+			wantRefresh: setting[int64]{value: 5, source: "flag -refresh"},
+			wantRegion:  setting[string]{value: "us-east-1", source: "env TRICK_REGION"},
+			wantRoles: setting[[]string]{
+				value:  []string{"arn::42::role-a", "arn::42::role-b"},
+				source: "env TRICK_ROLE",
+			},
+			wantUse: setting[[]string]{value: []string{"arn::42::role-a"}, source: "flag -use"},
+		},
+		{
+			name:    "profile override",
+			args:    []string{"-config", config, "-profile", "missing"},
+			wantErr: parser.ErrProfileNotFound,
+		},
+		{
+			name:    "invalid env",
+			env:     map[string]string{"TRICK_REFRESH": "soon"},
+			wantErr: ErrInvalidSetting,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			flags := flag.NewFlagSet("trick", flag.ContinueOnError)
+			flags.SetOutput(io.Discard)
+			declareRunFlags(flags)
+
+			err := flags.Parse(tt.args)
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+
+			got, err := resolveSettings(flags, func(key string) string { return tt.env[key] })
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf("resolveSettings() error = %v, want %v", err, tt.wantErr)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("resolveSettings() error = %v", err)
+			}
+
+			if got.refresh != tt.wantRefresh {
+				t.Errorf("refresh = %+v, want %+v", got.refresh, tt.wantRefresh)
+			}
+
+			if got.region != tt.wantRegion {
+				t.Errorf("region = %+v, want %+v", got.region, tt.wantRegion)
+			}
+
+			if !reflect.DeepEqual(got.roles, tt.wantRoles) {
+				t.Errorf("roles = %+v, want %+v", got.roles, tt.wantRoles)
+			}
+
+			if !reflect.DeepEqual(got.useRoles, tt.wantUse) {
+				t.Errorf("use = %+v, want %+v", got.useRoles, tt.wantUse)
+			}
+		})
+	}
+}
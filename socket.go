@@ -0,0 +1,140 @@
+// Copyright 2025 variHQ OÜ
+// SPDX-License-Identifier: BSD-3-Clause
+
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/wakeful/trick/internal/state"
+)
+
+var (
+	// ErrSocketInUse indicates that another trick already serves the socket.
+	ErrSocketInUse = errors.New("socket is in use")
+	// ErrNotSocket indicates a socket path that names something other than a socket, which trick never replaces.
+	ErrNotSocket = errors.New("refusing to replace a file that is not a socket")
+	// ErrUnsafeSocketDir indicates a socket directory that users other than the current one own or can enter.
+	ErrUnsafeSocketDir = errors.New("socket directory must be owned by the current user and closed to others")
+)
+
+const (
+	socketDirMode  = 0o700
+	socketFileMode = 0o600
+	// socketReadHeaderTimeout bounds how long a socket client may take to send its request headers.
+	socketReadHeaderTimeout = 5 * time.Second
+)
+
+// socketPath returns path, or the default control socket location when it is empty.
+func socketPath(path string) (string, error) {
+	if path != "" {
+		return path, nil
+	}
+
+	defaultPath, err := state.DefaultSocketPath()
+	if err != nil {
+		return "", fmt.Errorf("unable to locate control socket: %w", err)
+	}
+
+	return defaultPath, nil
+}
+
+// listenSocket creates a Unix socket at path inside a directory only the current user can enter, and
+// restricts the socket itself to the current user. An existing directory open to others, such as /tmp, is refused
+// rather than tightened. A stale socket left by a crashed run is replaced, one that another trick still answers on
+// is not.
+func listenSocket(ctx context.Context, path string) (net.Listener, error) {
+	err := os.MkdirAll(filepath.Dir(path), socketDirMode)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create socket directory: %w", err)
+	}
+
+	err = checkSocketDir(filepath.Dir(path))
+	if err != nil {
+		return nil, err
+	}
+
+	dialer := net.Dialer{Timeout: time.Second} //nolint:exhaustruct
+
+	conn, err := dialer.DialContext(ctx, "unix", path)
+	if err == nil {
+		_ = conn.Close()
+
+		return nil, fmt.Errorf("%w: %s", ErrSocketInUse, path)
+	}
+
+	info, err := os.Lstat(path)
+
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+	case err != nil:
+		return nil, fmt.Errorf("failed to inspect socket path: %w", err)
+	case info.Mode()&fs.ModeSocket == 0:
+		return nil, fmt.Errorf("%w: %s", ErrNotSocket, path)
+	default:
+		err = os.Remove(path)
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return nil, fmt.Errorf("failed to remove stale socket: %w", err)
+		}
+	}
+
+	var config net.ListenConfig
+
+	listener, err := config.Listen(ctx, "unix", path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen on socket: %w", err)
+	}
+
+	err = os.Chmod(path, socketFileMode)
+	if err != nil {
+		_ = listener.Close()
+
+		return nil, fmt.Errorf("failed to restrict socket: %w", err)
+	}
+
+	return listener, nil
+}
+
+// controlSocketHandler serves the status, control and credentials API to local clients such as trick ctl.
+// File permissions stand in for the bearer token the TCP server requires.
+func controlSocketHandler(app *App) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/chains", apiChains(app))
+	mux.HandleFunc("GET /api/diagram", apiDiagram(app))
+	mux.HandleFunc("GET /api/status", apiStatus(app))
+	mux.HandleFunc("GET /api/history", apiHistory(app.broadcaster))
+	mux.HandleFunc("GET /api/credentials", apiCredentials(app))
+	mux.HandleFunc("POST /api/control/{action}", apiControl(app))
+
+	return mux
+}
+
+// serveControlSocket answers requests on listener until ctx is done.
+func serveControlSocket(ctx context.Context, app *App, listener net.Listener) {
+	serv := &http.Server{ //nolint:exhaustruct
+		Handler:           controlSocketHandler(app),
+		ReadHeaderTimeout: socketReadHeaderTimeout,
+	}
+
+	go func() {
+		<-ctx.Done()
+
+		_ = serv.Close()
+	}()
+
+	slog.Info("serving control socket", slog.String("path", listener.Addr().String()))
+
+	err := serv.Serve(listener)
+	if err != nil && !errors.Is(err, http.ErrServerClosed) {
+		slog.Error("control socket error", slog.String("error", err.Error()))
+	}
+}
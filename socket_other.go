@@ -0,0 +1,11 @@
+// Copyright 2025 variHQ OÜ
+// SPDX-License-Identifier: BSD-3-Clause
+
+//go:build !unix
+
+package main
+
+// checkSocketDir accepts any directory where Unix file modes and owners do not apply.
+func checkSocketDir(string) error {
+	return nil
+}
@@ -0,0 +1,135 @@
+// Copyright 2025 variHQ OÜ
+// SPDX-License-Identifier: BSD-3-Clause
+
+package main
+
+import (
+	"errors"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// shortTempDir returns a temporary directory whose path stays within the length limit of Unix socket addresses.
+func shortTempDir(t *testing.T) string {
+	t.Helper()
+
+	dir, err := os.MkdirTemp("", "trick")
+	if err != nil {
+		t.Fatalf("MkdirTemp() error = %v", err)
+	}
+
+	t.Cleanup(func() { _ = os.RemoveAll(dir) })
+
+	return dir
+}
+
+func TestListenControlSocket(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		prepare func(t *testing.T, path string)
+		wantErr error
+	}{
+		{name: "fresh", prepare: func(*testing.T, string) {}},
+		{
+			name: "stale socket",
+			prepare: func(t *testing.T, path string) {
+				t.Helper()
+
+				var config net.ListenConfig
+
+				listener, err := config.Listen(t.Context(), "unix", path)
+				if err != nil {
+					t.Fatalf("Listen() error = %v", err)
+				}
+
+				unixListener, _ := listener.(*net.UnixListener)
+				unixListener.SetUnlinkOnClose(false)
+
+				_ = listener.Close()
+			},
+		},
+		{
+			name: "regular file",
+			prepare: func(t *testing.T, path string) {
+				t.Helper()
+
+				err := os.WriteFile(path, []byte("[default]\n"), 0o600)
+				if err != nil {
+					t.Fatalf("WriteFile() error = %v", err)
+				}
+			},
+			wantErr: ErrNotSocket,
+		},
+		{
+			name: "shared directory",
+			prepare: func(t *testing.T, path string) {
+				t.Helper()
+
+				err := os.Chmod(filepath.Dir(path), 0o755) //nolint:gosec
+				if err != nil {
+					t.Fatalf("Chmod() error = %v", err)
+				}
+			},
+			wantErr: ErrUnsafeSocketDir,
+		},
+		{
+			name: "in use",
+			prepare: func(t *testing.T, path string) {
+				t.Helper()
+
+				listener, err := listenSocket(t.Context(), path)
+				if err != nil {
+					t.Fatalf("listenSocket() error = %v", err)
+				}
+
+				t.Cleanup(func() { _ = listener.Close() })
+			},
+			wantErr: ErrSocketInUse,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			path := filepath.Join(shortTempDir(t), "run", "trick.sock")
+
+			err := os.MkdirAll(filepath.Dir(path), socketDirMode)
+			if err != nil {
+				t.Fatalf("MkdirAll() error = %v", err)
+			}
+
+			tt.prepare(t, path)
+
+			listener, err := listenSocket(t.Context(), path)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("listenSocket() error = %v, want %v", err, tt.wantErr)
+			}
+
+			if errors.Is(err, ErrNotSocket) {
+				if _, errStat := os.Stat(path); errStat != nil {
+					t.Errorf("the file in the way was removed: %v", errStat)
+				}
+			}
+
+			if err != nil {
+				return
+			}
+
+			defer func() { _ = listener.Close() }()
+
+			info, err := os.Stat(path)
+			if err != nil {
+				t.Fatalf("Stat() error = %v", err)
+			}
+
+			if info.Mode()&os.ModeSocket == 0 || info.Mode().Perm() != socketFileMode {
+				t.Errorf("socket mode = %v, want a socket with %v", info.Mode(), os.FileMode(socketFileMode))
+			}
+		})
+	}
+}
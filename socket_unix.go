@@ -0,0 +1,28 @@
+// Copyright 2025 variHQ OÜ
+// SPDX-License-Identifier: BSD-3-Clause
+
+//go:build unix
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"syscall"
+)
+
+// checkSocketDir refuses a socket directory that another user owns or can enter: the socket is created with the
+// process umask and only restricted afterwards, so the directory is what keeps other users out in between.
+func checkSocketDir(dir string) error {
+	info, err := os.Stat(dir)
+	if err != nil {
+		return fmt.Errorf("failed to inspect socket directory: %w", err)
+	}
+
+	stat, ok := info.Sys().(*syscall.Stat_t)
+	if !ok || int(stat.Uid) != os.Getuid() || info.Mode().Perm()&^socketDirMode != 0 {
+		return fmt.Errorf("%w: %s has mode %v", ErrUnsafeSocketDir, dir, info.Mode().Perm())
+	}
+
+	return nil
+}
@@ -8,20 +8,27 @@ import (
 	_ "embed"
 	"fmt"
 	"log/slog"
+	"net"
 	"net/http"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/wakeful/trick/internal/broadcast"
 	"github.com/wakeful/trick/internal/ui"
 )
 
-func startSSEServer(
-	ctx context.Context,
-	broadcast *broadcast.Broadcaster,
-	preRenderedHTML string,
-) {
+// uiHandler serves the diagram, its event stream and the JSON API, all behind the credentials of auth. The control
+// API is only served when auth requires credentials.
+func uiHandler(app *App, preRenderedHTML string, auth serverAuth) http.Handler {
 	mux := http.NewServeMux()
 	mux.HandleFunc("/", mainHandler(preRenderedHTML))
-	mux.HandleFunc("/events", events(broadcast))
+	mux.HandleFunc("/events", events(app.broadcaster, sseHeartbeat))
+	mux.HandleFunc("GET /api/chains", apiChains(app))
+	mux.HandleFunc("GET /api/diagram", apiDiagram(app))
+	mux.HandleFunc("GET /api/status", apiStatus(app))
+	mux.HandleFunc("GET /api/history", apiHistory(app.broadcaster))
+	mux.HandleFunc("POST /api/control/{action}", requireControl(auth, apiControl(app)))
 	mux.HandleFunc("/static/mermaid.js", func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Content-Type", "application/javascript; charset=utf-8")
 		w.Header().Set("Content-Encoding", "gzip")
@@ -29,19 +36,30 @@ func startSSEServer(
 		_, _ = w.Write(ui.MermaidScript)
 	})
 
-	const defaultAddr = "127.0.0.1:8742"
+	return requireAuth(auth, mux)
+}
 
-	serv := &http.Server{ //nolint:exhaustruct,gosec
-		Addr:    defaultAddr,
-		Handler: mux,
+func startSSEServer(
+	ctx context.Context,
+	app *App,
+	preRenderedHTML string,
+	config serverConfig,
+	listener net.Listener,
+) {
+	serv := &http.Server{ //nolint:exhaustruct
+		Handler:           uiHandler(app, preRenderedHTML, config.auth),
+		ReadTimeout:       config.readTimeout,
+		ReadHeaderTimeout: config.readTimeout,
+		WriteTimeout:      config.writeTimeout,
+		IdleTimeout:       config.idleTimeout,
 	}
 
 	serverErr := make(chan error, 1)
 
 	go func() {
-		slog.Info("starting SSE server", slog.String("addr", defaultAddr))
+		slog.Info("starting SSE server", slog.String("url", config.url()))
 
-		serverErr <- serv.ListenAndServe()
+		serverErr <- serv.Serve(listener)
 
 		slog.Info("SSE server stopped")
 	}()
@@ -63,43 +81,74 @@ func startSSEServer(
 	}
 }
 
+const (
+	// sseRetry is the reconnection delay suggested to browsers with the retry field.
+	sseRetry = 3 * time.Second
+	// sseHeartbeat is how often an idle stream gets a comment, so proxies do not close it.
+	sseHeartbeat = 15 * time.Second
+)
+
+// events streams published messages to an SSE client. A reconnecting client sends the ID of the last event it saw as
+// Last-Event-ID and first receives the events it missed.
+//
+//nolint:funlen
 func events(
 	broadcast *broadcast.Broadcaster,
+	heartbeat time.Duration,
 ) func(writer http.ResponseWriter, request *http.Request) {
 	return func(writer http.ResponseWriter, request *http.Request) {
 		writer.Header().Set("Content-Type", "text/event-stream")
 		writer.Header().Set("Cache-Control", "no-cache")
 		writer.Header().Set("Connection", "keep-alive")
 
-		if f, ok := writer.(http.Flusher); ok {
-			f.Flush()
+		// The stream outlives the write timeout of the server; heartbeats notice clients that went away.
+		_ = http.NewResponseController(writer).SetWriteDeadline(time.Time{})
+
+		flush := func() {
+			if f, ok := writer.(http.Flusher); ok {
+				f.Flush()
+			}
 		}
 
-		target, unsubscribe := broadcast.Subscribe()
-		defer unsubscribe()
+		_, _ = fmt.Fprintf(writer, "retry: %d\n\n", sseRetry.Milliseconds())
+		flush()
+
+		subscription := broadcast.SubscribeWith(subscribeOptions(request))
+		defer subscription.Close()
 
 		slog.Debug("SSE client connected", slog.String("client", request.RemoteAddr))
 
+		ticker := time.NewTicker(heartbeat)
+		defer ticker.Stop()
+
 		notify := request.Context().Done()
 
 		for {
 			select {
-			case message, ok := <-target:
+			case message, ok := <-subscription.C:
 				if !ok {
+					slog.Debug("SSE client dropped",
+						slog.String("client", request.RemoteAddr),
+						slog.Any("reason", subscription.Err()),
+					)
+
 					return
 				}
 
 				_, _ = fmt.Fprint(writer, message.String())
-				if f, ok := writer.(http.Flusher); ok {
-					f.Flush()
-				}
+				flush()
 
 				slog.Debug(
 					"SSE event delivered",
 					slog.String("client", request.RemoteAddr),
+					slog.Uint64("id", message.ID),
+					slog.String("event", message.Event),
 					slog.String("chain", message.Chain),
 					slog.String("role", message.Role),
 				)
+			case <-ticker.C:
+				_, _ = fmt.Fprint(writer, ": heartbeat\n\n")
+				flush()
 			case <-notify:
 				slog.Debug("SSE client disconnected", slog.String("client", request.RemoteAddr))
 
@@ -109,6 +158,42 @@ func events(
 	}
 }
 
+// subscribeOptions reads the subscription of an SSE client from its request. The chain and event query parameters
+// filter the stream and take comma-separated lists, buffer sets how many events the client may fall behind, and
+// Last-Event-ID replays the events it missed. A client that falls further behind is disconnected, so that it
+// reconnects and catches up from its last ID instead of silently missing events.
+func subscribeOptions(request *http.Request) broadcast.Options {
+	query := request.URL.Query()
+	opts := broadcast.Options{
+		Name:   request.RemoteAddr,
+		Chains: splitList(strings.Join(query["chain"], ",")),
+		Events: splitList(strings.Join(query["event"], ",")),
+		Buffer: broadcast.DefaultBuffer,
+		Policy: broadcast.PolicyDisconnect,
+		LastID: 0,
+	}
+
+	if value := query.Get("buffer"); value != "" {
+		buffer, err := strconv.Atoi(value)
+		if err != nil || buffer < 1 {
+			slog.Debug("ignoring invalid buffer", slog.String("value", value))
+		} else {
+			opts.Buffer = min(buffer, broadcast.HistorySize)
+		}
+	}
+
+	if value := request.Header.Get("Last-Event-ID"); value != "" {
+		lastID, err := strconv.ParseUint(value, 10, 64)
+		if err != nil {
+			slog.Debug("ignoring invalid Last-Event-ID", slog.String("value", value))
+		} else {
+			opts.LastID = lastID
+		}
+	}
+
+	return opts
+}
+
 func mainHandler(
 	preRenderedHTML string,
 ) func(writer http.ResponseWriter, request *http.Request) {
@@ -3,7 +3,16 @@
 
 package main
 
-import "testing"
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/wakeful/trick/internal/broadcast"
+)
 
 func TestMainHandler(t *testing.T) {
 	t.Parallel()
@@ -15,3 +24,86 @@ func TestMainHandler(t *testing.T) {
 		t.Fatal("diagramHandler returned nil")
 	}
 }
+
+func TestEvents(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		target      string
+		lastEventID string
+		want        []string
+		notWant     []string
+	}{
+		{
+			name:        "new client gets the current role",
+			target:      "/events",
+			lastEventID: "",
+			want:        []string{"retry: 3000\n\n", "id: 3\nevent: jump\n", ": heartbeat\n\n"},
+			notWant:     []string{"id: 1\n", "id: 2\n"},
+		},
+		{
+			name:        "reconnecting client gets the events it missed",
+			target:      "/events",
+			lastEventID: "1",
+			want:        []string{"id: 2\nevent: credentials-written\n", "id: 3\nevent: jump\n"},
+			notWant:     []string{"id: 1\n"},
+		},
+		{
+			name:        "invalid Last-Event-ID falls back to the current role",
+			target:      "/events",
+			lastEventID: "latest",
+			want:        []string{"id: 3\nevent: jump\n"},
+			notWant:     []string{"id: 2\n"},
+		},
+		{
+			name:        "event filter",
+			target:      "/events?event=credentials-written&chain=main&buffer=1",
+			lastEventID: "1",
+			want:        []string{"id: 2\nevent: credentials-written\n"},
+			notWant:     []string{"id: 1\n", "id: 3\n"},
+		},
+		{
+			name:        "chain filter",
+			target:      "/events?chain=other",
+			lastEventID: "",
+			want:        []string{"retry: 3000\n\n"},
+			notWant:     []string{"event: jump\n"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			b := broadcast.NewBroadcaster()
+			b.Publish(broadcast.Message{Event: broadcast.EventJump, Chain: "main", Role: "a"})               //nolint:exhaustruct
+			b.Publish(broadcast.Message{Event: broadcast.EventCredentialsWritten, Chain: "main", Role: "a"}) //nolint:exhaustruct
+			b.Publish(broadcast.Message{Event: broadcast.EventJump, Chain: "main", Role: "b"})               //nolint:exhaustruct
+
+			ctx, cancel := context.WithTimeout(t.Context(), 50*time.Millisecond)
+			defer cancel()
+
+			request := httptest.NewRequestWithContext(ctx, http.MethodGet, tt.target, nil)
+			if tt.lastEventID != "" {
+				request.Header.Set("Last-Event-ID", tt.lastEventID)
+			}
+
+			recorder := httptest.NewRecorder()
+			events(b, 10*time.Millisecond)(recorder, request)
+
+			body := recorder.Body.String()
+			for _, want := range tt.want {
+				if !strings.Contains(body, want) {
+					t.Errorf("stream %q does not contain %q", body, want)
+				}
+			}
+
+			for _, notWant := range tt.notWant {
+				if strings.Contains(body, notWant) {
+					t.Errorf("stream %q contains %q", body, notWant)
+				}
+			}
+		})
+	}
+}
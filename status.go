@@ -0,0 +1,150 @@
+// Copyright 2025 variHQ OÜ
+// SPDX-License-Identifier: BSD-3-Clause
+
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/wakeful/trick/internal/audit"
+)
+
+// runStatus is the state of the latest run recorded in an audit log.
+type runStatus struct {
+	Started    time.Time
+	Running    bool
+	Role       string
+	Since      time.Time
+	Expiration *time.Time
+	NextHop    time.Time
+	Hops       int
+	Failures   int
+	LastEvent  audit.Entry
+}
+
+// latestRun summarises the entries from the last start event onwards.
+func latestRun(entries []audit.Entry) runStatus {
+	first := 0
+
+	for idx, entry := range entries {
+		if entry.Event == audit.EventStart {
+			first = idx
+		}
+	}
+
+	status := runStatus{ //nolint:exhaustruct
+		Started:   entries[first].Time,
+		Running:   true,
+		LastEvent: entries[len(entries)-1],
+	}
+
+	var refresh time.Duration
+
+	for _, entry := range entries[first:] {
+		switch entry.Event {
+		case audit.EventStart:
+			refresh = time.Duration(entry.Refresh) * time.Minute
+		case audit.EventAssume:
+			status.Hops++
+		case audit.EventAssumeFailed, audit.EventWriteFailed:
+			status.Failures++
+		case audit.EventCredentialsWritten:
+			status.Role = entry.Role
+			status.Since = entry.Time
+			status.Expiration = entry.Expiration
+			status.NextHop = entry.Time.Add(refresh)
+		case audit.EventStop, audit.EventGuardrailTripped:
+			status.Running = false
+		}
+	}
+
+	return status
+}
+
+// statusCommand reports the role a run currently holds and when it hops next, read from the run's audit log.
+func statusCommand(_ context.Context, args []string, stdout io.Writer) error {
+	flags := flag.NewFlagSet("status", flag.ContinueOnError)
+	auditPath := flags.String("audit", os.Getenv(envPrefix+"AUDIT"), "audit log written by trick run -audit")
+	flags.Usage = func() {
+		_, _ = fmt.Fprintln(flags.Output(), "Usage: trick status -audit engagement.jsonl")
+		_, _ = fmt.Fprintln(flags.Output(), "\nShow the current role and next hop of the latest run in the audit log.\n\nFlags:")
+		flags.PrintDefaults()
+	}
+
+	ok, err := parseFlags(flags, args)
+	if !ok {
+		return err
+	}
+
+	if *auditPath == "" {
+		return fmt.Errorf("%w: %w", ErrUsage, ErrMissingAuditLog)
+	}
+
+	file, err := os.Open(*auditPath) //nolint:gosec
+	if err != nil {
+		return fmt.Errorf("failed to open audit log: %w", err)
+	}
+
+	defer func() { _ = file.Close() }()
+
+	entries, err := audit.Read(file)
+	if err != nil {
+		return fmt.Errorf("failed to read audit log: %w", err)
+	}
+
+	return writeStatus(stdout, latestRun(entries))
+}
+
+func writeStatus(output io.Writer, status runStatus) error {
+	table := tabwriter.NewWriter(output, 0, 0, 2, ' ', 0) //nolint:mnd
+
+	state := "stopped"
+	if status.Running {
+		state = "running"
+	}
+
+	formatTime := func(value time.Time) string {
+		if value.IsZero() {
+			return "-"
+		}
+
+		return value.UTC().Format(time.RFC3339)
+	}
+
+	role := status.Role
+	if role == "" {
+		role = "-"
+	}
+
+	expiration := "-"
+	if status.Expiration != nil {
+		expiration = formatTime(*status.Expiration)
+	}
+
+	nextHop := "-"
+	if status.Running {
+		nextHop = formatTime(status.NextHop)
+	}
+
+	_, _ = fmt.Fprintf(table, "state\t%s\n", state)
+	_, _ = fmt.Fprintf(table, "started\t%s\n", formatTime(status.Started))
+	_, _ = fmt.Fprintf(table, "role\t%s\n", role)
+	_, _ = fmt.Fprintf(table, "since\t%s\n", formatTime(status.Since))
+	_, _ = fmt.Fprintf(table, "expires\t%s\n", expiration)
+	_, _ = fmt.Fprintf(table, "next hop\t%s\n", nextHop)
+	_, _ = fmt.Fprintf(table, "hops\t%d (%d failed)\n", status.Hops, status.Failures)
+	_, _ = fmt.Fprintf(table, "last event\t%s at %s\n", status.LastEvent.Event, formatTime(status.LastEvent.Time))
+
+	err := table.Flush()
+	if err != nil {
+		return fmt.Errorf("failed to write status: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,86 @@
+// Copyright 2025 variHQ OÜ
+// SPDX-License-Identifier: BSD-3-Clause
+
+package main
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestStatusCommand(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		log  string
+		want []string
+	}{
+		{
+			name: "running",
+			log: `{"time":"2025-01-01T09:00:00Z","event":"start","chain":"main","refresh":12}
+{"time":"2025-01-01T09:00:01Z","event":"assume","chain":"main","role":"arn::42::role-a"}
+{"time":"2025-01-01T09:00:02Z","event":"credentials-written","chain":"main","role":"arn::42::role-a","expiration":"2025-01-01T09:15:01Z"}
+{"time":"2025-01-01T09:12:01Z","event":"assume-failed","chain":"main","role":"arn::42::role-b","error":"denied"}
+`,
+			want: []string{
+				"state       running\n",
+				"role        arn::42::role-a\n",
+				"expires     2025-01-01T09:15:01Z\n",
+				"next hop    2025-01-01T09:12:02Z\n",
+				"hops        1 (1 failed)\n",
+				"last event  assume-failed at 2025-01-01T09:12:01Z\n",
+			},
+		},
+		{
+			name: "stopped after a restart",
+			log: `{"time":"2025-01-01T09:00:00Z","event":"start","chain":"main","refresh":12}
+{"time":"2025-01-01T09:00:01Z","event":"assume","chain":"main","role":"arn::42::role-a"}
+{"time":"2025-01-02T09:00:00Z","event":"start","chain":"main","refresh":5}
+{"time":"2025-01-02T09:00:01Z","event":"stop","chain":"main"}
+`,
+			want: []string{
+				"state       stopped\n",
+				"started     2025-01-02T09:00:00Z\n",
+				"role        -\n",
+				"next hop    -\n",
+				"hops        0 (0 failed)\n",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			path := filepath.Join(t.TempDir(), "audit.jsonl")
+
+			err := os.WriteFile(path, []byte(tt.log), 0o600)
+			if err != nil {
+				t.Fatalf("failed to write audit log: %v", err)
+			}
+
+			var out bytes.Buffer
+
+			err = statusCommand(t.Context(), []string{"-audit", path}, &out)
+			if err != nil {
+				t.Fatalf("status error = %v", err)
+			}
+
+			for _, want := range tt.want {
+				if !strings.Contains(out.String(), want) {
+					t.Errorf("status output missing %q:\n%s", want, out.String())
+				}
+			}
+		})
+	}
+
+	err := statusCommand(t.Context(), []string{"-audit", ""}, &bytes.Buffer{})
+	if !errors.Is(err, ErrMissingAuditLog) {
+		t.Errorf("status error = %v, want %v", err, ErrMissingAuditLog)
+	}
+}
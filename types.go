@@ -12,11 +12,14 @@ import (
 	"log/slog"
 	"os/exec"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/aws/aws-sdk-go-v2/config"
 	"github.com/aws/aws-sdk-go-v2/credentials/stscreds"
 	"github.com/aws/aws-sdk-go-v2/service/sts"
+	"github.com/aws/aws-sdk-go-v2/service/sts/types"
+	"github.com/wakeful/trick/internal/audit"
 	"github.com/wakeful/trick/internal/broadcast"
 )
 
@@ -48,33 +51,69 @@ type App struct {
 	profileWriter *ProfileWriter
 	// region is the AWS region used for IAM operations
 	region string
+	// members lists the roles of the ring in order; unlike roles it never moves
+	members []string
+	// entries are the roles the chain can be entered from; empty means the first member
+	entries []string
 	// roles is a ring buffer containing all roles that can be assumed
 	roles *ring.Ring
+	// position is the index in the ring of the role nextRole returns next
+	position int
+	// current is the last role assumed successfully
+	current string
 	// usableRoles is a set of roles with meaningful permissions
 	usableRoles map[string]struct{}
 	// sessionDuration is the duration for which assumed role credentials are valid
 	sessionDuration time.Duration
+	// refresh is the interval between ticks; zero disables the expiry warning after a write
+	refresh time.Duration
+	// expiration is when the credentials last written to the output profile expire
+	expiration *time.Time
 	// broadcaster is used to publish messages about role changes
 	broadcaster *broadcast.Broadcaster
+	// audit records hops, writes and failures for the engagement report; nil disables it
+	audit *audit.Logger
+	// guardrails enforces the rules-of-engagement limits; nil disables them
+	guardrails *Guardrails
+	// control carries requests from the control API to the run goroutine, which alone touches roles and client
+	control chan controlRequest
+	// paused stops ticks from hopping until resumed
+	paused bool
+	// pinned is the only role the chain stops at while set
+	pinned string
+	// skipped maps disabled roles to the time they are enabled again
+	skipped map[string]time.Time
+	// loadSettings re-reads the run settings for reload; nil disables reload
+	loadSettings func() (*settings, error)
+	// started is set once the first tick ran
+	started bool
+	// brokenAt is when the chain broke, or the zero time while it is healthy
+	brokenAt time.Time
+	// statusMu guards status and credentials, which the run goroutine updates and the API reads, and the ring
+	// description that reload replaces: members, entries, usableRoles and refresh
+	statusMu    sync.RWMutex
+	status      liveStatus
+	credentials *types.Credentials
 }
 
 // NewApp initializes a new App instance for managing AWS role assumptions and profile updates.
-// It requires a context, AWS region, list of roles, and a subset of usable roles.
+// It requires a context, AWS region, list of roles, a subset of usable roles and optional guardrails.
 // Returns the configured App instance or an error if initialization fails.
 func NewApp(
 	ctx context.Context,
 	region string,
 	roles []string,
 	usableRoles []string,
+	guardrails *Guardrails,
 ) (*App, error) {
 	cfg, err := config.LoadDefaultConfig(ctx,
 		config.WithRegion(region),
 	)
 	if err != nil {
-		return nil, fmt.Errorf("unable to load SDK config, %w", err)
+		return nil, withClass(ClassBootstrap, fmt.Errorf("unable to load SDK config, %w", err))
 	}
 
-	rolesPool, err := setRolePool(roles)
+	rolesPool, err := setRolePool(roles, guardrails.accounts()...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to set role pool: %w", err)
 	}
@@ -103,10 +142,14 @@ func NewApp(
 		client:          sts.NewFromConfig(cfg),
 		profileWriter:   NewProfileWriter(nil),
 		region:          region,
+		members:         roles,
 		roles:           rolesPool,
 		usableRoles:     hMap,
 		sessionDuration: maxSessionDuration * time.Minute,
 		broadcaster:     broadcast.NewBroadcaster(),
+		guardrails:      guardrails,
+		control:         make(chan controlRequest),
+		status:          liveStatus{Chain: "main", State: stateStarting}, //nolint:exhaustruct
 	}, nil
 }
 
@@ -102,7 +102,13 @@ func TestNewApp(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			t.Parallel()
 
-			got, err := NewApp(t.Context(), tt.args.region, tt.args.roles, tt.args.usableRoles)
+			got, err := NewApp(
+				t.Context(),
+				tt.args.region,
+				tt.args.roles,
+				tt.args.usableRoles,
+				nil,
+			)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("NewApp() error = %v, wantErr %v", err, tt.wantErr)
 
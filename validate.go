@@ -0,0 +1,117 @@
+// Copyright 2025 variHQ OÜ
+// SPDX-License-Identifier: BSD-3-Clause
+
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+
+	"github.com/wakeful/trick/internal/parser"
+)
+
+var (
+	// ErrInvalidConfig indicates that trick validate found at least one config file that cannot be run.
+	ErrInvalidConfig = errors.New("invalid config")
+	// ErrMissingConfigFiles indicates that trick validate was called without a config file.
+	ErrMissingConfigFiles = errors.New("at least one config file is required")
+)
+
+// validateCommand checks that config files parse and that every profile, or the one given with -profile, is a ring
+// trick can run.
+func validateCommand(_ context.Context, args []string, stdout io.Writer) error {
+	flags := flag.NewFlagSet("validate", flag.ContinueOnError)
+	profileName := flags.String("profile", "", "only check this profile")
+	flags.Usage = func() {
+		_, _ = fmt.Fprintln(flags.Output(), "Usage: trick validate [flags] config.hcl [config.json ...]")
+		_, _ = fmt.Fprintln(flags.Output(), "\nCheck that each profile has a chain of at least two roles and that its")
+		_, _ = fmt.Fprintln(flags.Output(), "guardrails are valid and allow every role.\n\nFlags:")
+		flags.PrintDefaults()
+	}
+
+	ok, err := parseFlags(flags, args)
+	if !ok {
+		return err
+	}
+
+	if flags.NArg() == 0 {
+		return fmt.Errorf("%w: %w", ErrUsage, ErrMissingConfigFiles)
+	}
+
+	invalid := 0
+
+	for _, path := range flags.Args() {
+		err = validateFile(path, *profileName)
+		if err != nil {
+			invalid++
+
+			_, _ = fmt.Fprintf(stdout, "%s: %v\n", path, err)
+
+			continue
+		}
+
+		_, _ = fmt.Fprintf(stdout, "%s: ok\n", path)
+	}
+
+	if invalid > 0 {
+		return fmt.Errorf("%w: %d of %d file(s)", ErrInvalidConfig, invalid, flags.NArg())
+	}
+
+	return nil
+}
+
+func validateFile(path, profileName string) error {
+	config, err := parser.ParseFile(path)
+	if err != nil {
+		return err //nolint:wrapcheck
+	}
+
+	if profileName != "" {
+		config.SelectProfile = profileName
+	}
+
+	_, err = config.Selected()
+	if err != nil {
+		return fmt.Errorf("failed to select profile: %w", err)
+	}
+
+	for _, profile := range config.Profiles {
+		if profileName != "" && profile.Name != profileName {
+			continue
+		}
+
+		err = validateProfile(profile)
+		if err != nil {
+			return fmt.Errorf("profile %q: %w", profile.Name, err)
+		}
+	}
+
+	return nil
+}
+
+// validateProfile runs the start-up checks of NewApp that need no AWS access.
+func validateProfile(profile *parser.Profile) error {
+	if profile.Chain == nil {
+		return ErrMissingChain
+	}
+
+	guardrails, err := NewGuardrails(profile.Guardrails)
+	if err != nil {
+		return err
+	}
+
+	roles := make([]string, 0, len(profile.Chain.UseRoles))
+	for _, role := range profile.Chain.UseRoles {
+		roles = append(roles, role.ARN)
+	}
+
+	_, err = setRolePool(roles, guardrails.accounts()...)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
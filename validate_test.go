@@ -0,0 +1,69 @@
+// Copyright 2025 variHQ OÜ
+// SPDX-License-Identifier: BSD-3-Clause
+
+package main
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestValidateCommand(t *testing.T) {
+	t.Parallel()
+
+	valid := writeConfig(t, ringConfig)
+	short := writeConfig(t, `select_profile = profile.short
+
+profile "short" {
+  chain {
+    use {
+      arn = "arn:aws:iam::123456789012:role/trick-role-a"
+    }
+  }
+}
+`)
+	foreign := writeConfig(t, strings.Replace(ringConfig, "  chain {", `  guardrails {
+    allowed_accounts = ["210987654321"]
+  }
+
+  chain {`, 1))
+
+	var out bytes.Buffer
+
+	err := validateCommand(t.Context(), []string{valid}, &out)
+	if err != nil {
+		t.Fatalf("validate error = %v", err)
+	}
+
+	if out.String() != valid+": ok\n" {
+		t.Errorf("validate output = %q", out.String())
+	}
+
+	out.Reset()
+
+	err = validateCommand(t.Context(), []string{valid, short, foreign}, &out)
+	if !errors.Is(err, ErrInvalidConfig) {
+		t.Fatalf("validate error = %v, want %v", err, ErrInvalidConfig)
+	}
+
+	for _, want := range []string{
+		short + `: profile "short": ` + ErrMinRoles.Error(),
+		foreign + `: profile "ring": ` + ErrAccountNotAllowed.Error(),
+	} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("validate output missing %q:\n%s", want, out.String())
+		}
+	}
+
+	err = validateCommand(t.Context(), []string{"-profile", "missing", valid}, &out)
+	if !errors.Is(err, ErrInvalidConfig) {
+		t.Errorf("validate -profile error = %v, want %v", err, ErrInvalidConfig)
+	}
+
+	err = validateCommand(t.Context(), nil, &out)
+	if !errors.Is(err, ErrUsage) {
+		t.Errorf("validate error = %v, want %v", err, ErrUsage)
+	}
+}
@@ -0,0 +1,31 @@
+// Copyright 2025 variHQ OÜ
+// SPDX-License-Identifier: BSD-3-Clause
+
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+)
+
+// versionCommand prints the version of the binary.
+func versionCommand(_ context.Context, args []string, stdout io.Writer) error {
+	flags := flag.NewFlagSet("version", flag.ContinueOnError)
+	flags.Usage = func() {
+		_, _ = fmt.Fprintln(flags.Output(), "Usage: trick version")
+	}
+
+	ok, err := parseFlags(flags, args)
+	if !ok {
+		return err
+	}
+
+	_, err = fmt.Fprintf(stdout, "trick %s https://github.com/wakeful/trick\n", version)
+	if err != nil {
+		return fmt.Errorf("failed to write version: %w", err)
+	}
+
+	return nil
+}